@@ -1,16 +1,20 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/config"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository/postgres"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/server"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/auth"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/cloudinary"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/database"
 	email "github.com/mohamedfawas/rmshop-clean-architecture/pkg/emailVerify"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/storage"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/tasks"
 )
 
@@ -61,10 +65,10 @@ func main() {
 	// 	cfg.Cloudinary.APIKey,
 	// 	cfg.Cloudinary.APISecret)
 
-	// // Initialize Cloudinary service
-	cloudinaryService, err := cloudinary.NewCloudinaryService(cfg.Cloudinary.CloudName, cfg.Cloudinary.APIKey, cfg.Cloudinary.APISecret)
+	// Initialize the image storage, cloudinary or the local disk
+	imageStorage, err := newImageStorage(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize Cloudinary instance: %v", err)
+		log.Fatalf("Failed to initialize image storage: %v", err)
 	}
 
 	// Create TokenBlacklist service
@@ -73,8 +77,21 @@ func main() {
 	// Start token cleanup task
 	tasks.StartTokenCleanupTask(tokenBlacklist)
 
+	// Start the task which makes scheduled products live at their publish time
+	tasks.StartScheduledProductPublishTask(postgres.NewProductRepository(db))
+
+	// Start the task which rebuilds the co-purchase affinity of the products
+	tasks.StartProductAffinityRefreshTask(postgres.NewRecommendationRepository(db))
+
+	// Start the task which releases the expired stock reservations
+	tasks.StartStockReservationExpiryTask(postgres.NewStockReservationRepository(db))
+
+	// Start the task which raises the low stock alerts and emails them
+	tasks.StartStockAlertTask(usecase.NewInventoryUseCase(
+		postgres.NewInventoryRepository(db), postgres.NewProductRepository(db), emailSender, cfg.Alerts.Email))
+
 	// Create a new server instance with the database connection and email sender
-	srv := server.NewServer(db, emailSender, cloudinaryService, tokenBlacklist, cfg)
+	srv := server.NewServer(db, emailSender, imageStorage, tokenBlacklist, cfg)
 
 	// Start the HTTP server
 	log.Printf("Starting server on : %s", cfg.Server.Port)
@@ -82,3 +99,26 @@ func main() {
 		log.Fatalf("Failed to start the server : %v", err)
 	}
 }
+
+// newImageStorage creates the image storage selected in the config.
+// Without a driver, cloudinary is used when its credentials are set, otherwise the images are stored on the local disk
+func newImageStorage(cfg *config.Config) (storage.ImageStorage, error) {
+	driver := cfg.Storage.Driver
+	if driver == "" {
+		driver = "local"
+		if cfg.Cloudinary.CloudName != "" && cfg.Cloudinary.APIKey != "" && cfg.Cloudinary.APISecret != "" {
+			driver = "cloudinary"
+		}
+	}
+
+	switch driver {
+	case "cloudinary":
+		log.Println("Using cloudinary image storage")
+		return cloudinary.NewCloudinaryService(cfg.Cloudinary.CloudName, cfg.Cloudinary.APIKey, cfg.Cloudinary.APISecret)
+	case "local":
+		log.Printf("Using local image storage at %s", cfg.Storage.LocalDir)
+		return storage.NewLocalStorage(cfg.Storage.LocalDir, cfg.Storage.URLPrefix)
+	default:
+		return nil, fmt.Errorf("unknown image storage driver %q", driver)
+	}
+}
@@ -14,8 +14,11 @@ type Config struct {
 	Admin      AdminConfig      `mapstructure:"admin"`
 	SMTP       SMTPConfig       `mapstructure:"smtp"`
 	Cloudinary CloudinaryConfig `mapstructure:"cloudinary"`
+	Storage    StorageConfig    `mapstructure:"storage"`
 	JWT        JWTConfig        `mapstructure:"jwt"`
 	Razorpay   RazorpayConfig   `mapstructure:"razorpay"`
+	Alerts     AlertsConfig     `mapstructure:"alerts"`
+	Inventory  InventoryConfig  `mapstructure:"inventory"`
 }
 
 type ServerConfig struct {
@@ -50,6 +53,14 @@ type CloudinaryConfig struct {
 	APISecret string `mapstructure:"api_secret"`
 }
 
+// StorageConfig selects where the product images are stored.
+// Driver is "local" or "cloudinary", when empty cloudinary is used if its credentials are set
+type StorageConfig struct {
+	Driver    string `mapstructure:"driver"`
+	LocalDir  string `mapstructure:"local_dir"`
+	URLPrefix string `mapstructure:"url_prefix"`
+}
+
 type JWTConfig struct {
 	Secret string `mapstructure:"secret"`
 }
@@ -59,6 +70,17 @@ type RazorpayConfig struct {
 	KeySecret string `mapstructure:"key_secret"`
 }
 
+// AlertsConfig is where the low stock alerts are emailed, alerts are only listed for the admin when Email is empty
+type AlertsConfig struct {
+	Email string `mapstructure:"email"`
+}
+
+// InventoryConfig selects how the stock of an order is allocated from the locations.
+// AllocationRule is "priority" or "nearest" (nearest to the shipping pincode), any other value is treated as priority
+type InventoryConfig struct {
+	AllocationRule string `mapstructure:"allocation_rule"`
+}
+
 func Load() (*Config, error) {
 	// Load .env into process env (non-fatal if file is missing)
 	_ = gotenv.Load()
@@ -105,10 +127,18 @@ func bindEnvVars(v *viper.Viper) {
 		"cloudinary.api_key",
 		"cloudinary.api_secret",
 
+		"storage.driver",
+		"storage.local_dir",
+		"storage.url_prefix",
+
 		"jwt.secret",
 
 		"razorpay.key_id",
 		"razorpay.key_secret",
+
+		"alerts.email",
+
+		"inventory.allocation_rule",
 	}
 
 	for _, key := range keys {
@@ -144,10 +174,21 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("cloudinary.api_key", "")
 	v.SetDefault("cloudinary.api_secret", "")
 
+	// Image storage
+	v.SetDefault("storage.driver", "")
+	v.SetDefault("storage.local_dir", "./uploads/product_images")
+	v.SetDefault("storage.url_prefix", "/uploads/product_images")
+
 	// JWT
 	v.SetDefault("jwt.secret", "change-me-in-production")
 
 	// Razorpay
 	v.SetDefault("razorpay.key_id", "")
 	v.SetDefault("razorpay.key_secret", "")
+
+	// Alerts
+	v.SetDefault("alerts.email", "")
+
+	// Inventory
+	v.SetDefault("inventory.allocation_rule", "priority")
 }
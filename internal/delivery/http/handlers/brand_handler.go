@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type BrandHandler struct {
+	brandUseCase usecase.BrandUseCase
+}
+
+func NewBrandHandler(brandUseCase usecase.BrandUseCase) *BrandHandler {
+	return &BrandHandler{brandUseCase: brandUseCase}
+}
+
+func (h *BrandHandler) CreateBrand(w http.ResponseWriter, r *http.Request) {
+	var brand domain.Brand
+	err := json.NewDecoder(r.Body).Decode(&brand)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to create brand", nil, "Invalid request body")
+		return
+	}
+
+	err = h.brandUseCase.CreateBrand(r.Context(), &brand)
+	if err != nil {
+		handleBrandError(w, "Failed to create brand", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusCreated, "Brand created successfully", brand, "")
+}
+
+func (h *BrandHandler) GetAllBrands(w http.ResponseWriter, r *http.Request) {
+	brands, err := h.brandUseCase.GetAllBrands(r.Context())
+	if err != nil {
+		api.SendResponse(w, http.StatusInternalServerError, "Failed to get brands", nil, "An unexpected error occurred")
+		return
+	}
+
+	if len(brands) == 0 {
+		api.SendResponse(w, http.StatusOK, "No brands found", []struct{}{}, "")
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Brands retrieved successfully", brands, "")
+}
+
+func (h *BrandHandler) GetBrandByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	brandID, err := strconv.Atoi(vars["brandId"])
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to get brand details", nil, "Please provide a valid brand id")
+		return
+	}
+
+	brand, err := h.brandUseCase.GetBrandByID(r.Context(), brandID)
+	if err != nil {
+		handleBrandError(w, "Failed to get brand details", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Brand retrieved successfully", brand, "")
+}
+
+func (h *BrandHandler) UpdateBrand(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	brandID, err := strconv.Atoi(vars["brandId"])
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update brand", nil, "Invalid brand ID")
+		return
+	}
+
+	var brand domain.Brand
+	err = json.NewDecoder(r.Body).Decode(&brand)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update brand", nil, "Invalid request body")
+		return
+	}
+	brand.ID = brandID
+
+	err = h.brandUseCase.UpdateBrand(r.Context(), &brand)
+	if err != nil {
+		handleBrandError(w, "Failed to update brand", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Brand updated successfully", brand, "")
+}
+
+func (h *BrandHandler) SoftDeleteBrand(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	brandID, err := strconv.Atoi(vars["brandId"])
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to delete brand", nil, "Invalid brand ID")
+		return
+	}
+
+	err = h.brandUseCase.SoftDeleteBrand(r.Context(), brandID)
+	if err != nil {
+		handleBrandError(w, "Failed to delete brand", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Brand deleted successfully", nil, "")
+}
+
+func handleBrandError(w http.ResponseWriter, message string, err error) {
+	switch err {
+	case utils.ErrInvalidBrandName:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Please provide a valid brand name")
+	case utils.ErrBrandNameTooShort:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Brand name should have at least 2 characters")
+	case utils.ErrBrandNameTooLong:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Brand name length should not be greater than 50 characters")
+	case utils.ErrDuplicateBrand:
+		api.SendResponse(w, http.StatusConflict, message, nil, "Brand already exists")
+	case utils.ErrBrandNotFound:
+		api.SendResponse(w, http.StatusNotFound, message, nil, "Brand not found")
+	default:
+		log.Printf("error while handling brand request : %v", err)
+		api.SendResponse(w, http.StatusInternalServerError, message, nil, "An unexpected error occurred")
+	}
+}
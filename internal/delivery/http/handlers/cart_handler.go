@@ -38,11 +38,15 @@ func (h *CartHandler) AddToCart(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call the AddToCart Method from usecase layer
-	cartItem, err := h.cartUseCase.AddToCart(r.Context(), userID, input.ProductID, input.Quantity)
+	cartItem, err := h.cartUseCase.AddToCart(r.Context(), userID, input.ProductID, input.VariantID, input.Quantity)
 	if err != nil {
 		switch err {
 		case utils.ErrProductNotFound:
 			api.SendResponse(w, http.StatusNotFound, "Failed to add item to cart", nil, "Product not found")
+		case utils.ErrVariantNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to add item to cart", nil, "Product variant not found")
+		case utils.ErrVariantRequired:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to add item to cart", nil, "Product has variants, variant_id is required")
 		case utils.ErrInvalidQuantity:
 			api.SendResponse(w, http.StatusBadRequest, "Failed to add item to cart", nil, "Invalid quantity")
 		case utils.ErrExceedsMaxQuantity:
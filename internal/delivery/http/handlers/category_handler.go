@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
@@ -53,6 +54,8 @@ func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 		switch err {
 		case utils.ErrDuplicateCategory:
 			api.SendResponse(w, http.StatusConflict, "Failed to create category", nil, "Category already exists")
+		case utils.ErrInvalidParentCategory:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to create category", nil, "The specified parent category is invalid or deleted")
 		default:
 			api.SendResponse(w, http.StatusInternalServerError, "Failed to create category", nil, "An unexpected error occurred")
 		}
@@ -82,6 +85,52 @@ func (h *CategoryHandler) GetAllCategories(w http.ResponseWriter, r *http.Reques
 	api.SendResponse(w, http.StatusOK, "Categories retrieved successfully", categories, "")
 }
 
+func (h *CategoryHandler) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.categoryUseCase.GetCategoryTree(r.Context())
+	if err != nil {
+		log.Printf("error while retrieving category tree : %v", err)
+		api.SendResponse(w, http.StatusInternalServerError, "Failed to get category tree", nil, "An unexpected error occured")
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Category tree retrieved successfully", tree, "")
+}
+
+// publicCategoryMaxAge is how long the clients and the proxies may cache the public category responses
+const publicCategoryMaxAge = 5 * time.Minute
+
+func (h *CategoryHandler) GetPublicCategoryTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.categoryUseCase.GetPublicCategoryTree(r.Context())
+	if err != nil {
+		log.Printf("error while retrieving public category tree : %v", err)
+		api.SendResponse(w, http.StatusInternalServerError, "Failed to get categories", nil, "An unexpected error occured")
+		return
+	}
+
+	api.SendCacheableResponse(w, r, publicCategoryMaxAge, "Categories retrieved successfully", tree)
+}
+
+func (h *CategoryHandler) GetPublicCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := strconv.Atoi(mux.Vars(r)["categoryId"])
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to get category details", nil, "Please provide a valid category id")
+		return
+	}
+
+	category, err := h.categoryUseCase.GetPublicCategory(r.Context(), categoryID)
+	if err != nil {
+		if err == utils.ErrCategoryNotFound {
+			api.SendResponse(w, http.StatusNotFound, "Failed to get category details", nil, "Category not found")
+		} else {
+			log.Printf("error while retrieving public category : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to get category details", nil, "An unexpected error occured")
+		}
+		return
+	}
+
+	api.SendCacheableResponse(w, r, publicCategoryMaxAge, "Category retrieved successfully", category)
+}
+
 func (h *CategoryHandler) GetActiveCategoryByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	categoryID, err := strconv.Atoi(vars["categoryId"])
@@ -164,7 +213,7 @@ func (h *CategoryHandler) SoftDeleteCategory(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err = h.categoryUseCase.SoftDeleteCategory(r.Context(), categoryID)
+	result, err := h.categoryUseCase.SoftDeleteCategory(r.Context(), categoryID)
 	if err != nil {
 		log.Printf("Error soft deleting category: %v", err)
 		if err == utils.ErrCategoryNotFound {
@@ -175,5 +224,5 @@ func (h *CategoryHandler) SoftDeleteCategory(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	api.SendResponse(w, http.StatusOK, "Category deleted successfully", nil, "")
+	api.SendResponse(w, http.StatusOK, "Category deleted successfully", result, "")
 }
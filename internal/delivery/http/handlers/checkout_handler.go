@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/delivery/http/middleware"
@@ -30,12 +31,13 @@ func (h *CheckoutHandler) CreateCheckout(w http.ResponseWriter, r *http.Request)
 
 	session, err := h.checkoutUseCase.CreateOrUpdateCheckout(r.Context(), userID)
 	if err != nil {
+		if errors.Is(err, utils.ErrInsufficientStock) {
+			sendInsufficientStock(w, http.StatusBadRequest, "Failed to create checkout", err)
+			return
+		}
 		switch err {
 		case utils.ErrEmptyCart:
 			api.SendResponse(w, http.StatusBadRequest, "Failed to create checkout", nil, "Cart is empty")
-		case utils.ErrInsufficientStock:
-			api.SendResponse(w, http.StatusBadRequest, "Failed to create checkout", nil, "Insufficient stock for one or more items")
-
 		default:
 			api.SendResponse(w, http.StatusInternalServerError, "Failed to create checkout", nil, "An unexpected error occurred")
 		}
@@ -118,6 +120,10 @@ func (h *CheckoutHandler) UpdateCheckoutAddress(w http.ResponseWriter, r *http.R
 
 	updatedCheckout, err := h.checkoutUseCase.UpdateCheckoutAddress(r.Context(), userID, input.AddressID)
 	if err != nil {
+		if errors.Is(err, utils.ErrInsufficientStock) {
+			sendInsufficientStock(w, http.StatusConflict, "Failed to update address", err)
+			return
+		}
 		switch err {
 		case utils.ErrCheckoutNotFound:
 			api.SendResponse(w, http.StatusNotFound, "Failed to update address", nil, "Checkout not found")
@@ -129,6 +135,8 @@ func (h *CheckoutHandler) UpdateCheckoutAddress(w http.ResponseWriter, r *http.R
 			api.SendResponse(w, http.StatusNotFound, "Failed to update address", nil, "Address not found")
 		case utils.ErrAddressNotBelongToUser:
 			api.SendResponse(w, http.StatusForbidden, "Failed to update address", nil, "Address does not belong to the user")
+		case utils.ErrEmptyCart:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to update address", nil, "Cart is empty")
 		default:
 			api.SendResponse(w, http.StatusInternalServerError, "Failed to update address", nil, "An unexpected error occurred")
 		}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/delivery/http/middleware"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
@@ -38,6 +39,9 @@ func (h *InventoryHandler) GetInventory(w http.ResponseWriter, r *http.Request)
 	}
 
 	params.CategoryName = r.URL.Query().Get("category_name")
+	params.SKU = r.URL.Query().Get("sku")
+	params.Size = r.URL.Query().Get("size")
+	params.Color = r.URL.Query().Get("color")
 
 	if stockLessThan, err := strconv.Atoi(r.URL.Query().Get("stock_less_than")); err == nil {
 		params.StockLessThan = &stockLessThan
@@ -47,6 +51,12 @@ func (h *InventoryHandler) GetInventory(w http.ResponseWriter, r *http.Request)
 		params.StockMoreThan = &stockMoreThan
 	}
 
+	if locationID, err := strconv.ParseInt(r.URL.Query().Get("location_id"), 10, 64); err == nil {
+		params.LocationID = locationID
+	}
+
+	params.Breakdown = r.URL.Query().Get("breakdown")
+
 	if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page > 0 {
 		params.Page = page
 	}
@@ -77,6 +87,12 @@ func (h *InventoryHandler) GetInventory(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *InventoryHandler) UpdateProductStock(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to update stock", nil, "Admin not authenticated")
+		return
+	}
+
 	// Extract product ID from URL
 	vars := mux.Vars(r)
 	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
@@ -95,7 +111,7 @@ func (h *InventoryHandler) UpdateProductStock(w http.ResponseWriter, r *http.Req
 	}
 
 	// Call use case method
-	err = h.inventoryUseCase.UpdateProductStock(r.Context(), productID, input.StockQuantity)
+	err = h.inventoryUseCase.UpdateProductStock(r.Context(), adminID, productID, input.StockQuantity)
 	if err != nil {
 		switch err {
 		case utils.ErrProductNotFound:
@@ -104,6 +120,10 @@ func (h *InventoryHandler) UpdateProductStock(w http.ResponseWriter, r *http.Req
 			api.SendResponse(w, http.StatusBadRequest, "Failed to update stock", nil, "Invalid stock quantity")
 		case utils.ErrStockQuantityTooLarge:
 			api.SendResponse(w, http.StatusBadRequest, "Failed to update stock", nil, "Stock quantity exceeds maximum allowed value")
+		case utils.ErrInsufficientLocationStock:
+			api.SendResponse(w, http.StatusConflict, "Failed to update stock", nil, "The primary location doesn't hold enough stock for the reduction, update the stock at each location instead")
+		case utils.ErrProductHasVariants:
+			api.SendResponse(w, http.StatusConflict, "Failed to update stock", nil, "Product has variants, update the stock of each variant instead")
 		default:
 			api.SendResponse(w, http.StatusInternalServerError, "Failed to update stock", nil, "An unexpected error occurred")
 		}
@@ -112,3 +132,208 @@ func (h *InventoryHandler) UpdateProductStock(w http.ResponseWriter, r *http.Req
 
 	api.SendResponse(w, http.StatusOK, "Stock updated successfully", nil, "")
 }
+
+func (h *InventoryHandler) UpdateVariantStock(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to update stock", nil, "Admin not authenticated")
+		return
+	}
+
+	// Extract product ID and variant ID from URL
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update stock", nil, "Invalid product ID")
+		return
+	}
+	variantID, err := strconv.ParseInt(vars["variantId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update stock", nil, "Invalid variant ID")
+		return
+	}
+
+	// Parse request body
+	var input struct {
+		StockQuantity int `json:"stock_quantity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update stock", nil, "Invalid request body")
+		return
+	}
+
+	err = h.inventoryUseCase.UpdateVariantStock(r.Context(), adminID, productID, variantID, input.StockQuantity)
+	if err != nil {
+		switch err {
+		case utils.ErrVariantNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to update stock", nil, "Variant not found")
+		case utils.ErrInvalidStockQuantity:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to update stock", nil, "Invalid stock quantity")
+		case utils.ErrStockQuantityTooLarge:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to update stock", nil, "Stock quantity exceeds maximum allowed value")
+		case utils.ErrInsufficientLocationStock:
+			api.SendResponse(w, http.StatusConflict, "Failed to update stock", nil, "The primary location doesn't hold enough stock for the reduction, update the stock at each location instead")
+		default:
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to update stock", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Stock updated successfully", nil, "")
+}
+
+func (h *InventoryHandler) GetStockMovements(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve stock movements", nil, "Invalid product ID")
+		return
+	}
+
+	params := domain.StockMovementQueryParams{
+		ProductID: productID,
+		Page:      1,
+		Limit:     20,
+	}
+
+	if variantIDStr := r.URL.Query().Get("variant_id"); variantIDStr != "" {
+		variantID, err := strconv.ParseInt(variantIDStr, 10, 64)
+		if err != nil {
+			api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve stock movements", nil, "Invalid variant ID")
+			return
+		}
+		params.VariantID = &variantID
+	}
+
+	if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page > 0 {
+		params.Page = page
+	}
+
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 && limit <= 100 {
+		params.Limit = limit
+	}
+
+	movements, total, err := h.inventoryUseCase.GetStockMovements(r.Context(), params)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to retrieve stock movements", nil, "Product not found")
+		default:
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve stock movements", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"movements":   movements,
+		"total_count": total,
+		"page":        params.Page,
+		"limit":       params.Limit,
+		"total_pages": (total + int64(params.Limit) - 1) / int64(params.Limit),
+	}
+
+	api.SendResponse(w, http.StatusOK, "Stock movements retrieved successfully", response, "")
+}
+
+func (h *InventoryHandler) CheckStockConsistency(w http.ResponseWriter, r *http.Request) {
+	var productID int64
+	if productIDStr := r.URL.Query().Get("product_id"); productIDStr != "" {
+		id, err := strconv.ParseInt(productIDStr, 10, 64)
+		if err != nil {
+			api.SendResponse(w, http.StatusBadRequest, "Failed to check stock consistency", nil, "Invalid product ID")
+			return
+		}
+		productID = id
+	}
+
+	discrepancies, err := h.inventoryUseCase.CheckStockConsistency(r.Context(), productID)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to check stock consistency", nil, "Product not found")
+		default:
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to check stock consistency", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"consistent":    len(discrepancies) == 0,
+		"discrepancies": discrepancies,
+	}
+
+	api.SendResponse(w, http.StatusOK, "Stock consistency checked successfully", response, "")
+}
+
+func (h *InventoryHandler) SetReorderThreshold(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update reorder threshold", nil, "Invalid product ID")
+		return
+	}
+
+	// null threshold stops the alerts of the product
+	var input struct {
+		ReorderThreshold *int `json:"reorder_threshold"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update reorder threshold", nil, "Invalid request body")
+		return
+	}
+
+	err = h.inventoryUseCase.SetReorderThreshold(r.Context(), productID, input.ReorderThreshold)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to update reorder threshold", nil, "Product not found")
+		case utils.ErrInvalidReorderThreshold:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to update reorder threshold", nil, "Reorder threshold must be between 0 and 1000000")
+		default:
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to update reorder threshold", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Reorder threshold updated successfully", map[string]interface{}{
+		"product_id":        productID,
+		"reorder_threshold": input.ReorderThreshold,
+	}, "")
+}
+
+func (h *InventoryHandler) GetStockAlerts(w http.ResponseWriter, r *http.Request) {
+	params := domain.StockAlertQueryParams{
+		Status: r.URL.Query().Get("status"),
+		Page:   1,
+		Limit:  20,
+	}
+
+	if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page > 0 {
+		params.Page = page
+	}
+
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 && limit <= 100 {
+		params.Limit = limit
+	}
+
+	alerts, total, err := h.inventoryUseCase.GetStockAlerts(r.Context(), params)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidStockAlertStatus:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve stock alerts", nil, "Status must be open or resolved")
+		default:
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve stock alerts", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"alerts":      alerts,
+		"total_count": total,
+		"page":        params.Page,
+		"limit":       params.Limit,
+		"total_pages": (total + int64(params.Limit) - 1) / int64(params.Limit),
+	}
+
+	api.SendResponse(w, http.StatusOK, "Stock alerts retrieved successfully", response, "")
+}
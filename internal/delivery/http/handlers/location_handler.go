@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/delivery/http/middleware"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type LocationHandler struct {
+	locationUseCase usecase.LocationUseCase
+}
+
+func NewLocationHandler(locationUseCase usecase.LocationUseCase) *LocationHandler {
+	return &LocationHandler{locationUseCase: locationUseCase}
+}
+
+func (h *LocationHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name     string `json:"name"`
+		Code     string `json:"code"`
+		Pincode  string `json:"pincode"`
+		Priority int    `json:"priority"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to create location", nil, "Invalid request body")
+		return
+	}
+
+	location := &domain.InventoryLocation{
+		Name:     input.Name,
+		Code:     input.Code,
+		Pincode:  input.Pincode,
+		Priority: input.Priority,
+	}
+	err := h.locationUseCase.CreateLocation(r.Context(), location)
+	if err != nil {
+		handleLocationError(w, "Failed to create location", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusCreated, "Location created successfully", location, "")
+}
+
+func (h *LocationHandler) GetLocations(w http.ResponseWriter, r *http.Request) {
+	locations, err := h.locationUseCase.GetLocations(r.Context())
+	if err != nil {
+		handleLocationError(w, "Failed to retrieve locations", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Locations retrieved successfully", locations, "")
+}
+
+func (h *LocationHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	locationID, err := strconv.ParseInt(vars["locationId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update location", nil, "Invalid location ID")
+		return
+	}
+
+	var update domain.InventoryLocationUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update location", nil, "Invalid request body")
+		return
+	}
+
+	location, err := h.locationUseCase.UpdateLocation(r.Context(), locationID, update)
+	if err != nil {
+		handleLocationError(w, "Failed to update location", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Location updated successfully", location, "")
+}
+
+func (h *LocationHandler) GetLocationStock(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	locationID, err := strconv.ParseInt(vars["locationId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve location stock", nil, "Invalid location ID")
+		return
+	}
+
+	params := domain.LocationStockQueryParams{
+		LocationID: locationID,
+		Page:       1,
+		Limit:      20,
+	}
+
+	if productID, err := strconv.ParseInt(r.URL.Query().Get("product_id"), 10, 64); err == nil {
+		params.ProductID = productID
+	}
+
+	if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page > 0 {
+		params.Page = page
+	}
+
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 && limit <= 100 {
+		params.Limit = limit
+	}
+
+	stocks, total, err := h.locationUseCase.GetLocationStock(r.Context(), params)
+	if err != nil {
+		handleLocationError(w, "Failed to retrieve location stock", err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"stock":       stocks,
+		"total_count": total,
+		"page":        params.Page,
+		"limit":       params.Limit,
+		"total_pages": (total + int64(params.Limit) - 1) / int64(params.Limit),
+	}
+
+	api.SendResponse(w, http.StatusOK, "Location stock retrieved successfully", response, "")
+}
+
+func (h *LocationHandler) SetLocationStock(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to update location stock", nil, "Admin not authenticated")
+		return
+	}
+
+	vars := mux.Vars(r)
+	locationID, err := strconv.ParseInt(vars["locationId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update location stock", nil, "Invalid location ID")
+		return
+	}
+
+	var input struct {
+		ProductID     int64  `json:"product_id"`
+		VariantID     *int64 `json:"variant_id"`
+		StockQuantity int    `json:"stock_quantity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update location stock", nil, "Invalid request body")
+		return
+	}
+
+	err = h.locationUseCase.SetLocationStock(r.Context(), adminID, locationID, input.ProductID, input.VariantID, input.StockQuantity)
+	if err != nil {
+		handleLocationError(w, "Failed to update location stock", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Location stock updated successfully", nil, "")
+}
+
+func (h *LocationHandler) TransferStock(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to transfer stock", nil, "Admin not authenticated")
+		return
+	}
+
+	var input struct {
+		FromLocationID int64  `json:"from_location_id"`
+		ToLocationID   int64  `json:"to_location_id"`
+		ProductID      int64  `json:"product_id"`
+		VariantID      *int64 `json:"variant_id"`
+		Quantity       int    `json:"quantity"`
+		Note           string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to transfer stock", nil, "Invalid request body")
+		return
+	}
+
+	transfer := &domain.StockTransfer{
+		FromLocationID: input.FromLocationID,
+		ToLocationID:   input.ToLocationID,
+		ProductID:      input.ProductID,
+		VariantID:      input.VariantID,
+		Quantity:       input.Quantity,
+		Note:           input.Note,
+	}
+	err := h.locationUseCase.TransferStock(r.Context(), adminID, transfer)
+	if err != nil {
+		handleLocationError(w, "Failed to transfer stock", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusCreated, "Stock transferred successfully", transfer, "")
+}
+
+func (h *LocationHandler) GetTransfers(w http.ResponseWriter, r *http.Request) {
+	params := domain.StockTransferQueryParams{
+		Page:  1,
+		Limit: 20,
+	}
+
+	if locationID, err := strconv.ParseInt(r.URL.Query().Get("location_id"), 10, 64); err == nil {
+		params.LocationID = locationID
+	}
+
+	if productID, err := strconv.ParseInt(r.URL.Query().Get("product_id"), 10, 64); err == nil {
+		params.ProductID = productID
+	}
+
+	if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page > 0 {
+		params.Page = page
+	}
+
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 && limit <= 100 {
+		params.Limit = limit
+	}
+
+	transfers, total, err := h.locationUseCase.GetTransfers(r.Context(), params)
+	if err != nil {
+		handleLocationError(w, "Failed to retrieve stock transfers", err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"transfers":   transfers,
+		"total_count": total,
+		"page":        params.Page,
+		"limit":       params.Limit,
+		"total_pages": (total + int64(params.Limit) - 1) / int64(params.Limit),
+	}
+
+	api.SendResponse(w, http.StatusOK, "Stock transfers retrieved successfully", response, "")
+}
+
+func handleLocationError(w http.ResponseWriter, message string, err error) {
+	switch err {
+	case utils.ErrLocationNotFound:
+		api.SendResponse(w, http.StatusNotFound, message, nil, "Location not found")
+	case utils.ErrProductNotFound:
+		api.SendResponse(w, http.StatusNotFound, message, nil, "Product not found")
+	case utils.ErrVariantNotFound:
+		api.SendResponse(w, http.StatusNotFound, message, nil, "Variant not found")
+	case utils.ErrInvalidLocationName:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Location name should have 2 to 100 characters")
+	case utils.ErrInvalidLocationCode:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Location code should have 2 to 20 letters, digits, - or _")
+	case utils.ErrInvalidLocationPincode:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Location pincode should have 6 digits")
+	case utils.ErrInvalidLocationPriority:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Location priority should be between 0 and 1000")
+	case utils.ErrDuplicateLocationName:
+		api.SendResponse(w, http.StatusConflict, message, nil, "Location name already exists")
+	case utils.ErrDuplicateLocationCode:
+		api.SendResponse(w, http.StatusConflict, message, nil, "Location code already exists")
+	case utils.ErrLocationInactive:
+		api.SendResponse(w, http.StatusConflict, message, nil, "Location is inactive")
+	case utils.ErrLocationHasStock:
+		api.SendResponse(w, http.StatusConflict, message, nil, "Location still holds stock, transfer the stock to another location first")
+	case utils.ErrNoActiveLocation:
+		api.SendResponse(w, http.StatusConflict, message, nil, "At least one location should stay active")
+	case utils.ErrInsufficientLocationStock:
+		api.SendResponse(w, http.StatusConflict, message, nil, "Not enough stock at the location")
+	case utils.ErrProductHasVariants:
+		api.SendResponse(w, http.StatusConflict, message, nil, "Product has variants, give the variant instead")
+	case utils.ErrInvalidStockQuantity:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Invalid stock quantity")
+	case utils.ErrStockQuantityTooLarge:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Stock quantity exceeds maximum allowed value")
+	case utils.ErrInvalidTransferQuantity:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Transfer quantity should be between 1 and 1000000")
+	case utils.ErrSameTransferLocation:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Source and destination locations should be different")
+	default:
+		log.Printf("error while handling location request : %v", err)
+		api.SendResponse(w, http.StatusInternalServerError, message, nil, "An unexpected error occurred")
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type OfferHandler struct {
+	offerUseCase usecase.OfferUseCase
+}
+
+func NewOfferHandler(offerUseCase usecase.OfferUseCase) *OfferHandler {
+	return &OfferHandler{offerUseCase: offerUseCase}
+}
+
+func (h *OfferHandler) CreateOffer(w http.ResponseWriter, r *http.Request) {
+	var input domain.CreateOfferInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to create offer", nil, "Invalid request body")
+		return
+	}
+
+	offer, err := h.offerUseCase.CreateOffer(r.Context(), input)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidOfferName:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to create offer", nil, "Please provide a valid offer name")
+		case utils.ErrInvalidOfferType:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to create offer", nil, "Discount type should be percentage or flat")
+		case utils.ErrInvalidOfferValue:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to create offer", nil, "Invalid discount value")
+		case utils.ErrInvalidOfferTarget:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to create offer", nil, "Offer should be given on exactly one of product, sub category or category")
+		case utils.ErrInvalidOfferPeriod:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to create offer", nil, "Invalid offer period, ends_at should be after starts_at and in the future")
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to create offer", nil, "Product not found")
+		case utils.ErrSubCategoryNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to create offer", nil, "Sub category not found")
+		case utils.ErrCategoryNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to create offer", nil, "Category not found")
+		default:
+			log.Printf("error while creating offer : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to create offer", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusCreated, "Offer created successfully", offer, "")
+}
+
+func (h *OfferHandler) GetOffers(w http.ResponseWriter, r *http.Request) {
+	params := domain.OfferQueryParams{Page: 1, Limit: 10}
+	if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page > 0 {
+		params.Page = page
+	}
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 {
+		params.Limit = limit
+	}
+	params.ActiveOnly, _ = strconv.ParseBool(r.URL.Query().Get("active"))
+
+	offers, totalCount, err := h.offerUseCase.GetOffers(r.Context(), params)
+	if err != nil {
+		log.Printf("error while retrieving offers : %v", err)
+		api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve offers", nil, "An unexpected error occurred")
+		return
+	}
+
+	response := map[string]interface{}{
+		"offers":      offers,
+		"total_count": totalCount,
+		"page":        params.Page,
+		"limit":       params.Limit,
+		"total_pages": (totalCount + int64(params.Limit) - 1) / int64(params.Limit),
+	}
+
+	api.SendResponse(w, http.StatusOK, "Offers retrieved successfully", response, "")
+}
+
+func (h *OfferHandler) DeactivateOffer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	offerID, err := strconv.ParseInt(vars["offerId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to deactivate offer", nil, "Invalid offer ID")
+		return
+	}
+
+	err = h.offerUseCase.DeactivateOffer(r.Context(), offerID)
+	if err != nil {
+		switch err {
+		case utils.ErrOfferNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to deactivate offer", nil, "Offer not found")
+		case utils.ErrOfferAlreadyInactive:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to deactivate offer", nil, "Offer is already inactive")
+		default:
+			log.Printf("error while deactivating offer : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to deactivate offer", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Offer deactivated successfully", nil, "")
+}
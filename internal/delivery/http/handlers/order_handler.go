@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/pagination"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 )
 
@@ -79,6 +81,27 @@ func (h *OrderHandler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// cursor pagination skips the count query and keeps pages stable while orders are placed
+	if cursorParams, ok, err := pagination.FromRequest(r); ok {
+		if err != nil {
+			api.SendResponse(w, http.StatusBadRequest, "Failed to get orders", nil, "Invalid cursor")
+			return
+		}
+		orders, page, err := h.orderUseCase.GetUserOrdersByCursor(r.Context(), userID, cursorParams)
+		if err != nil {
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to get orders", nil, "An unexpected error occurred")
+			return
+		}
+		response := map[string]interface{}{
+			"orders":      orders,
+			"next_cursor": page.NextCursor,
+			"prev_cursor": page.PrevCursor,
+			"limit":       page.Limit,
+		}
+		api.SendResponse(w, http.StatusOK, "Orders retrieved successfully", response, "")
+		return
+	}
+
 	// Get the page number given in the query
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	// If page number is not given in the query or page number given is less than 1, then set the default value
@@ -105,6 +128,26 @@ func (h *OrderHandler) GetUserOrders(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *OrderHandler) GetOrders(w http.ResponseWriter, r *http.Request) {
+	if cursorParams, ok, err := pagination.FromRequest(r); ok {
+		if err != nil {
+			api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve orders", nil, "Invalid cursor")
+			return
+		}
+		orders, page, err := h.orderUseCase.GetOrdersByCursor(r.Context(), cursorParams)
+		if err != nil {
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve orders", nil, "An unexpected error occurred")
+			return
+		}
+		response := map[string]interface{}{
+			"orders":      orders,
+			"next_cursor": page.NextCursor,
+			"prev_cursor": page.PrevCursor,
+			"limit":       page.Limit,
+		}
+		api.SendResponse(w, http.StatusOK, "Orders retrieved successfully", response, "")
+		return
+	}
+
 	// Get the page number from the query parameters
 	pageStr := r.URL.Query().Get("page")
 	page, err := strconv.Atoi(pageStr)
@@ -194,13 +237,17 @@ func (h *OrderHandler) PlaceOrderCOD(w http.ResponseWriter, r *http.Request) {
 	// Call use case method to place the order
 	order, err := h.orderUseCase.PlaceOrderCOD(r.Context(), userID)
 	if err != nil {
+		if errors.Is(err, utils.ErrInsufficientStock) {
+			sendInsufficientStock(w, http.StatusBadRequest, "Failed to place order", err)
+			return
+		}
 		switch err {
 		case utils.ErrCheckoutNotFound:
 			api.SendResponse(w, http.StatusNotFound, "Failed to place order", nil, "Checkout not found")
 		case utils.ErrEmptyCart:
 			api.SendResponse(w, http.StatusBadRequest, "Failed to place order", nil, "Cannot place order with empty cart")
-		case utils.ErrInsufficientStock:
-			api.SendResponse(w, http.StatusBadRequest, "Failed to place order", nil, "Insufficient stock for one or more items")
+		case utils.ErrPriceChanged:
+			api.SendResponse(w, http.StatusConflict, "Failed to place order", nil, "Prices have changed, please checkout again")
 		case utils.ErrInvalidAddress:
 			api.SendResponse(w, http.StatusBadRequest, "Failed to place order", nil, "Invalid or missing delivery address")
 		case utils.ErrCODLimitExceeded:
@@ -318,13 +365,17 @@ func (h *OrderHandler) PlaceOrderRazorpay(w http.ResponseWriter, r *http.Request
 	// Call the method in the usecase layer
 	order, err := h.orderUseCase.PlaceOrderRazorpay(r.Context(), userID)
 	if err != nil {
+		if errors.Is(err, utils.ErrInsufficientStock) {
+			sendInsufficientStock(w, http.StatusBadRequest, "Failed to place order", err)
+			return
+		}
 		switch err {
 		case utils.ErrCheckoutNotFound:
 			api.SendResponse(w, http.StatusNotFound, "Failed to place order", nil, "Checkout not found")
 		case utils.ErrEmptyCart:
 			api.SendResponse(w, http.StatusBadRequest, "Failed to place order", nil, "Cannot place order with empty cart")
-		case utils.ErrInsufficientStock:
-			api.SendResponse(w, http.StatusBadRequest, "Failed to place order", nil, "Insufficient stock for one or more items")
+		case utils.ErrPriceChanged:
+			api.SendResponse(w, http.StatusConflict, "Failed to place order", nil, "Prices have changed, please checkout again")
 		case utils.ErrInvalidAddress:
 			api.SendResponse(w, http.StatusBadRequest, "Failed to place order", nil, "Invalid or missing delivery address")
 		default:
@@ -378,6 +429,12 @@ func (h *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *OrderHandler) AdminApproveCancellation(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to approve cancellation", nil, "Admin not authenticated")
+		return
+	}
+
 	// Extract order ID from URL
 	vars := mux.Vars(r)
 	orderID, err := strconv.ParseInt(vars["orderId"], 10, 64)
@@ -387,7 +444,7 @@ func (h *OrderHandler) AdminApproveCancellation(w http.ResponseWriter, r *http.R
 	}
 
 	// Call use case method to approve cancellation
-	result, err := h.orderUseCase.ApproveCancellation(r.Context(), orderID)
+	result, err := h.orderUseCase.ApproveCancellation(r.Context(), adminID, orderID)
 	if err != nil {
 		log.Printf("Error approving cancellation: %v", err)
 		switch err {
@@ -419,6 +476,12 @@ func (h *OrderHandler) AdminApproveCancellation(w http.ResponseWriter, r *http.R
 }
 
 func (h *OrderHandler) AdminCancelOrder(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to cancel order", nil, "Admin not authenticated")
+		return
+	}
+
 	vars := mux.Vars(r)
 	orderID, err := strconv.ParseInt(vars["orderId"], 10, 64)
 	if err != nil {
@@ -426,7 +489,7 @@ func (h *OrderHandler) AdminCancelOrder(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	result, err := h.orderUseCase.AdminCancelOrder(r.Context(), orderID)
+	result, err := h.orderUseCase.AdminCancelOrder(r.Context(), adminID, orderID)
 	if err != nil {
 		switch err {
 		case utils.ErrOrderNotFound:
@@ -492,3 +555,13 @@ func (h *OrderHandler) GetCancellationRequests(w http.ResponseWriter, r *http.Re
 
 	api.SendResponse(w, http.StatusOK, "Cancellation requests retrieved successfully", response, "")
 }
+
+// sendInsufficientStock sends the items which are short of stock along with the insufficient stock error
+func sendInsufficientStock(w http.ResponseWriter, statusCode int, message string, err error) {
+	var data interface{}
+	var stockErr *utils.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		data = map[string]interface{}{"items": stockErr.Items}
+	}
+	api.SendResponse(w, statusCode, message, data, "Insufficient stock for one or more items")
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -69,6 +70,22 @@ func (h *PaymentHandler) RenderPaymentPage(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Hold the stock of the order again, the reservation is released when an earlier payment failed
+	err = h.orderUseCase.HoldStockForPayment(r.Context(), orderID)
+	if err != nil {
+		log.Printf("Error holding stock for the payment: %v", err)
+		if errors.Is(err, utils.ErrInsufficientStock) {
+			http.Error(w, "Some items of the order are out of stock", http.StatusConflict)
+			return
+		}
+		if err == utils.ErrCancelledOrder {
+			http.Error(w, "Order is cancelled", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to hold the stock of the order", http.StatusInternalServerError)
+		return
+	}
+
 	// Create Razorpay order
 	razorpayOrder, err := h.razorpayService.CreateOrder(int64(order.FinalAmount*100), "INR")
 	if err != nil {
@@ -110,18 +127,63 @@ func (h *PaymentHandler) ProcessRazorpayPayment(w http.ResponseWriter, r *http.R
 
 	log.Printf("Received Razorpay payment input: %+v", input)
 
-	err := h.orderUseCase.VerifyAndUpdateRazorpayPayment(r.Context(), input)
+	result, err := h.orderUseCase.VerifyAndUpdateRazorpayPayment(r.Context(), input)
 	if err != nil {
 		log.Printf("Error verifying and updating Razorpay payment: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	// The order couldn't be fulfilled after the payment, the amount is refunded to the wallet
+	if result.RefundStatus == utils.RefundStatusInitiated {
+		log.Printf("Payment refunded to the wallet for order ID: %s", input.OrderID)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":        "refunded",
+			"message":       "Some items of the order went out of stock, the amount is refunded to your wallet",
+			"order_id":      result.OrderID,
+			"order_status":  result.UpdatedOrderStatus,
+			"refund_status": result.RefundStatus,
+		})
+		return
+	}
+
 	log.Printf("Payment processed successfully for order ID: %s", input.OrderID)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
 }
 
+// ProcessRazorpayPaymentFailure records the failed payment once Razorpay confirms it, so that the stock held for the order is released
+func (h *PaymentHandler) ProcessRazorpayPaymentFailure(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		OrderID   string `json:"razorpay_order_id"`
+		PaymentID string `json:"razorpay_payment_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.OrderID == "" || input.PaymentID == "" {
+		http.Error(w, "Invalid input", http.StatusBadRequest)
+		return
+	}
+
+	err := h.orderUseCase.HandleRazorpayPaymentFailure(r.Context(), input.OrderID, input.PaymentID)
+	if err != nil {
+		log.Printf("Error handling Razorpay payment failure: %v", err)
+		switch err {
+		case utils.ErrPaymentNotFound:
+			http.Error(w, "Payment not found", http.StatusNotFound)
+		case utils.ErrPaymentNotFailed:
+			http.Error(w, "Payment has not failed", http.StatusConflict)
+		case utils.ErrRazorpayServiceUnavailable:
+			http.Error(w, "Failed to verify the payment with Razorpay", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "Failed to update payment", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "failed"})
+}
+
 func (h *PaymentHandler) RenderPaymentFailurePage(w http.ResponseWriter, r *http.Request) {
 	orderID := r.URL.Query().Get("order_id")
 	errorMsg := r.URL.Query().Get("error")
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type ProductAttributeHandler struct {
+	attributeUseCase usecase.ProductAttributeUseCase
+}
+
+func NewProductAttributeHandler(attributeUseCase usecase.ProductAttributeUseCase) *ProductAttributeHandler {
+	return &ProductAttributeHandler{attributeUseCase: attributeUseCase}
+}
+
+func (h *ProductAttributeHandler) CreateAttribute(w http.ResponseWriter, r *http.Request) {
+	subCategoryID, err := strconv.Atoi(mux.Vars(r)["subcategoryId"])
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to create attribute", nil, "Invalid sub category ID")
+		return
+	}
+
+	var attribute domain.ProductAttribute
+	err = json.NewDecoder(r.Body).Decode(&attribute)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to create attribute", nil, "Invalid request body")
+		return
+	}
+
+	err = h.attributeUseCase.CreateAttribute(r.Context(), subCategoryID, &attribute)
+	if err != nil {
+		h.sendAttributeError(w, "Failed to create attribute", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusCreated, "Attribute created successfully", attribute, "")
+}
+
+func (h *ProductAttributeHandler) GetAttributes(w http.ResponseWriter, r *http.Request) {
+	subCategoryID, err := strconv.Atoi(mux.Vars(r)["subcategoryId"])
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve attributes", nil, "Invalid sub category ID")
+		return
+	}
+
+	attributes, err := h.attributeUseCase.GetAttributes(r.Context(), subCategoryID)
+	if err != nil {
+		h.sendAttributeError(w, "Failed to retrieve attributes", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Attributes retrieved successfully", attributes, "")
+}
+
+func (h *ProductAttributeHandler) UpdateAttribute(w http.ResponseWriter, r *http.Request) {
+	attributeID, err := strconv.Atoi(mux.Vars(r)["attributeId"])
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update attribute", nil, "Invalid attribute ID")
+		return
+	}
+
+	var attribute domain.ProductAttribute
+	err = json.NewDecoder(r.Body).Decode(&attribute)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update attribute", nil, "Invalid request body")
+		return
+	}
+	attribute.ID = attributeID
+
+	updatedAttribute, err := h.attributeUseCase.UpdateAttribute(r.Context(), &attribute)
+	if err != nil {
+		h.sendAttributeError(w, "Failed to update attribute", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Attribute updated successfully", updatedAttribute, "")
+}
+
+func (h *ProductAttributeHandler) DeleteAttribute(w http.ResponseWriter, r *http.Request) {
+	attributeID, err := strconv.Atoi(mux.Vars(r)["attributeId"])
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to delete attribute", nil, "Invalid attribute ID")
+		return
+	}
+
+	err = h.attributeUseCase.DeleteAttribute(r.Context(), attributeID)
+	if err != nil {
+		h.sendAttributeError(w, "Failed to delete attribute", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Attribute deleted successfully", nil, "")
+}
+
+func (h *ProductAttributeHandler) SetProductAttributes(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(mux.Vars(r)["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid product ID", nil, "Product ID must be a number")
+		return
+	}
+
+	// attribute slug to value, like {"fabric": "polyester", "season": "2024/25"}
+	var values map[string]interface{}
+	err = json.NewDecoder(r.Body).Decode(&values)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to set product attributes", nil, "Invalid request body")
+		return
+	}
+
+	productValues, err := h.attributeUseCase.SetProductAttributes(r.Context(), productID, values)
+	if err != nil {
+		h.sendAttributeError(w, "Failed to set product attributes", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Product attributes updated successfully", productValues, "")
+}
+
+func (h *ProductAttributeHandler) GetProductAttributes(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(mux.Vars(r)["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid product ID", nil, "Product ID must be a number")
+		return
+	}
+
+	values, err := h.attributeUseCase.GetProductAttributes(r.Context(), productID)
+	if err != nil {
+		h.sendAttributeError(w, "Failed to retrieve product attributes", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Product attributes retrieved successfully", values, "")
+}
+
+func (h *ProductAttributeHandler) sendAttributeError(w http.ResponseWriter, message string, err error) {
+	switch err {
+	case utils.ErrSubCategoryNotFound:
+		api.SendResponse(w, http.StatusNotFound, message, nil, "Sub category not found")
+	case utils.ErrProductNotFound:
+		api.SendResponse(w, http.StatusNotFound, message, nil, "Product not found")
+	case utils.ErrAttributeNotFound:
+		api.SendResponse(w, http.StatusNotFound, message, nil, "Attribute not found")
+	case utils.ErrInvalidAttributeName:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Attribute name should have 2 to 100 characters")
+	case utils.ErrInvalidAttributeType:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Attribute type should be one of text, number, boolean or select")
+	case utils.ErrInvalidAllowedValues:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Select attributes need a list of unique allowed values, other types can't have allowed values")
+	case utils.ErrDuplicateAttribute:
+		api.SendResponse(w, http.StatusConflict, message, nil, "Attribute with this name already exists for the sub category")
+	case utils.ErrAttributeSlugInTree:
+		api.SendResponse(w, http.StatusConflict, message, nil, "Attribute with this name already exists for a sub category above or below it")
+	case utils.ErrAllowedValueInUse:
+		api.SendResponse(w, http.StatusConflict, message, nil, "Allowed values being removed are set on products, change the products first")
+	case utils.ErrInvalidAttributeValue:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Attribute value doesn't match the type or the allowed values of the attribute")
+	case utils.ErrAttributeNotApplicable:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Attribute is not defined for the sub category of the product")
+	default:
+		log.Printf("error while handling product attributes : %v", err)
+		api.SendResponse(w, http.StatusInternalServerError, message, nil, "An unexpected error occurred")
+	}
+}
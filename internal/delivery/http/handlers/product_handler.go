@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"mime/multipart"
 	"net/http"
@@ -9,9 +10,13 @@ import (
 	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/delivery/http/middleware"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/pagination"
+	productcatalog "github.com/mohamedfawas/rmshop-clean-architecture/pkg/product_catalog"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/storage"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/validator"
 )
@@ -25,6 +30,12 @@ func NewProductHandler(productUseCase usecase.ProductUseCase) *ProductHandler {
 }
 
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to create product", nil, "Admin not authenticated")
+		return
+	}
+
 	// Parse the request body
 	var product domain.Product
 	err := json.NewDecoder(r.Body).Decode(&product)
@@ -57,11 +68,17 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create the product
-	err = h.productUseCase.CreateProduct(r.Context(), &product)
+	err = h.productUseCase.CreateProduct(r.Context(), adminID, &product)
 	if err != nil {
 		switch err {
 		case utils.ErrInvalidSubCategory:
 			api.SendResponse(w, http.StatusBadRequest, "Failed to create product", nil, "The specified sub-category is invalid or deleted")
+		case utils.ErrInvalidBrand:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to create product", nil, "The specified brand is invalid or deleted")
+		case utils.ErrInvalidProductStatus:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to create product", nil, "Status should be one of draft, scheduled, active or archived")
+		case utils.ErrInvalidPublishAt:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to create product", nil, "Scheduled product needs a future publish_at time")
 		case utils.ErrDuplicateProductName:
 			api.SendResponse(w, http.StatusConflict, "Failed to create product", nil, "A product with this name already exists")
 		default:
@@ -76,6 +93,12 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to update product", nil, "Admin not authenticated")
+		return
+	}
+
 	// Extract the product id from the URL
 	vars := mux.Vars(r)
 	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
@@ -93,7 +116,7 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call the usecase method
-	updatedProduct, err := h.productUseCase.UpdateProduct(r.Context(), productID, updateFields)
+	updatedProduct, err := h.productUseCase.UpdateProduct(r.Context(), adminID, productID, updateFields)
 	if err != nil {
 		switch err {
 		case utils.ErrProductNameTooShort:
@@ -112,8 +135,18 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 			api.SendResponse(w, http.StatusNotFound, "Failed to update product", nil, "Product not found")
 		case utils.ErrInvalidSubCategory:
 			api.SendResponse(w, http.StatusBadRequest, "Failed to update product", nil, "Invalid sub-category")
+		case utils.ErrInvalidBrand:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to update product", nil, "The specified brand is invalid or deleted")
+		case utils.ErrInvalidProductStatus:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to update product", nil, "Status should be one of draft, scheduled, active or archived")
+		case utils.ErrInvalidPublishAt:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to update product", nil, "Scheduled product needs a future publish_at time in RFC3339 format")
 		case utils.ErrDuplicateProductName:
 			api.SendResponse(w, http.StatusConflict, "Failed to update product", nil, "Product name already exists")
+		case utils.ErrProductHasVariants:
+			api.SendResponse(w, http.StatusConflict, "Failed to update product", nil, "Product has variants, update the stock of each variant instead")
+		case utils.ErrInsufficientLocationStock:
+			api.SendResponse(w, http.StatusConflict, "Failed to update product", nil, "The primary location doesn't hold enough stock for the reduction, update the stock at each location instead")
 		default:
 			api.SendResponse(w, http.StatusInternalServerError, "Failed to update product", nil, "An unexpected error occurred")
 		}
@@ -234,6 +267,8 @@ func handleImageUploadError(w http.ResponseWriter, err error) {
 		api.SendResponse(w, http.StatusNotFound, "Failed to add image", nil, "Product not found")
 	case utils.ErrFileTooLarge:
 		api.SendResponse(w, http.StatusBadRequest, "Failed to add image", nil, "Image file is too large")
+	case utils.ErrImageDimensionsTooLarge:
+		api.SendResponse(w, http.StatusBadRequest, "Failed to add image", nil, fmt.Sprintf("Image width and height should be at most %d pixels", storage.MaxImageDimension))
 	case utils.ErrInvalidFileType:
 		api.SendResponse(w, http.StatusBadRequest, "Failed to add image", nil, "Invalid image file type")
 	case utils.ErrTooManyImages:
@@ -283,6 +318,41 @@ func (h *ProductHandler) DeleteProductImage(w http.ResponseWriter, r *http.Reque
 	api.SendResponse(w, http.StatusOK, "Image deleted successfully", nil, "")
 }
 
+// ReorderProductImages sets the gallery order of the product images, the first image becomes primary
+func (h *ProductHandler) ReorderProductImages(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(mux.Vars(r)["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid product ID", nil, "Product ID must be a number")
+		return
+	}
+
+	var req struct {
+		Images []domain.ProductImageOrder `json:"images"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to reorder images", nil, "Invalid request body")
+		return
+	}
+
+	images, err := h.productUseCase.ReorderImages(r.Context(), productID, req.Images)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to reorder images", nil, "Product not found")
+		case utils.ErrImageNotFound, utils.ErrInvalidImageOrder:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to reorder images", nil, utils.ErrInvalidImageOrder.Error())
+		case utils.ErrInvalidAltText:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to reorder images", nil, err.Error())
+		default:
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to reorder images", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Images reordered successfully", images, "")
+}
+
 func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
 	products, err := h.productUseCase.GetAllProducts(r.Context())
 	if err != nil {
@@ -298,10 +368,115 @@ func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request)
 	api.SendResponse(w, http.StatusOK, "Products retrieved successfully", products, "")
 }
 
+func (h *ProductHandler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to import products", nil, "Admin not authenticated")
+		return
+	}
+
+	err := r.ParseMultipartForm(10 << 20) // 10 MB max
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to import products", nil, "Invalid form data")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to import products", nil, "Please upload a csv or xlsx file as 'file'")
+		return
+	}
+	defer file.Close()
+
+	format, err := productcatalog.FormatFromFilename(header.Filename)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to import products", nil, "Supported file types: .csv, .xlsx")
+		return
+	}
+
+	result, err := h.productUseCase.ImportProducts(r.Context(), adminID, file, format)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidImportFile:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to import products", nil, "File could not be read")
+		case utils.ErrInvalidImportHeader:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to import products", nil,
+				"First row should be the header: "+strings.Join(productcatalog.Header, ","))
+		case utils.ErrEmptyImportFile:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to import products", nil, "File has no product rows")
+		case utils.ErrTooManyImportRows:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to import products", nil,
+				fmt.Sprintf("A file can have at most %d product rows", productcatalog.MaxRows))
+		case utils.ErrInsufficientLocationStock:
+			api.SendResponse(w, http.StatusConflict, "Failed to import products", nil, "The primary location doesn't hold enough stock for the reduction, update the stock at each location instead")
+		default:
+			log.Printf("error while importing products : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to import products", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Products imported", result, "")
+}
+
+func (h *ProductHandler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = productcatalog.FormatCSV
+	}
+	if format != productcatalog.FormatCSV && format != productcatalog.FormatExcel {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid format", nil, "Supported formats: csv, excel")
+		return
+	}
+
+	file, err := h.productUseCase.ExportProducts(r.Context(), format)
+	if err != nil {
+		log.Printf("error while exporting products : %v", err)
+		api.SendResponse(w, http.StatusInternalServerError, "Failed to export products", nil, "An unexpected error occurred")
+		return
+	}
+
+	switch format {
+	case productcatalog.FormatExcel:
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", "attachment; filename=products.xlsx")
+	default:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=products.csv")
+	}
+
+	w.Write(file)
+}
+
 func (h *ProductHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	params := parseQueryParams(r)
 
+	// cursor pagination skips the count query and keeps pages stable while products are added
+	if cursorParams, ok, err := pagination.FromRequest(r); ok {
+		if err != nil {
+			api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve products", nil, "Invalid cursor")
+			return
+		}
+		products, page, err := h.productUseCase.GetProductsByCursor(r.Context(), params, cursorParams)
+		if err == utils.ErrCursorWithSort {
+			api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve products", nil, "Sort and order can't be used with cursor pagination")
+			return
+		}
+		if err != nil {
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve products", nil, "An unexpected error occurred")
+			return
+		}
+		response := map[string]interface{}{
+			"products":    products,
+			"next_cursor": page.NextCursor,
+			"prev_cursor": page.PrevCursor,
+			"limit":       page.Limit,
+		}
+		api.SendResponse(w, http.StatusOK, "Products retrieved successfully", response, "")
+		return
+	}
+
 	// Call use case
 	products, totalCount, err := h.productUseCase.GetProducts(r.Context(), params)
 	if err != nil {
@@ -317,6 +492,16 @@ func (h *ProductHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
 		"total_pages": (totalCount + int64(params.Limit) - 1) / int64(params.Limit),
 	}
 
+	// facets are only computed when asked for, as they need a few extra queries
+	if includeFacets, _ := strconv.ParseBool(r.URL.Query().Get("facets")); includeFacets {
+		facets, err := h.productUseCase.GetProductFacets(r.Context(), params)
+		if err != nil {
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve products", nil, "An unexpected error occurred")
+			return
+		}
+		response["facets"] = facets
+	}
+
 	api.SendResponse(w, http.StatusOK, "Products retrieved successfully", response, "")
 }
 
@@ -339,11 +524,16 @@ func parseQueryParams(r *http.Request) domain.ProductQueryParams {
 	params.Category = r.URL.Query().Get("category")
 	params.Subcategory = r.URL.Query().Get("subcategory")
 	params.Search = r.URL.Query().Get("search")
+	params.Size = r.URL.Query().Get("size")
+	params.Color = r.URL.Query().Get("color")
 
 	params.MinPrice, _ = strconv.ParseFloat(r.URL.Query().Get("min_price"), 64)
 	params.MaxPrice, _ = strconv.ParseFloat(r.URL.Query().Get("max_price"), 64)
 
 	params.InStock, _ = strconv.ParseBool(r.URL.Query().Get("in_stock"))
+	params.MinRating, _ = strconv.ParseFloat(r.URL.Query().Get("min_rating"), 64)
+	params.MinDiscount, _ = strconv.ParseFloat(r.URL.Query().Get("min_discount"), 64)
+	params.MaxDiscount, _ = strconv.ParseFloat(r.URL.Query().Get("max_discount"), 64)
 
 	params.CreatedAfter = r.URL.Query().Get("created_after")
 	params.CreatedBefore = r.URL.Query().Get("created_before")
@@ -352,6 +542,28 @@ func parseQueryParams(r *http.Request) domain.ProductQueryParams {
 
 	params.Categories = r.URL.Query()["category"]
 
+	// multiple brands can be given either as repeated brand params or comma separated
+	params.Brand = r.URL.Query().Get("brand")
+	for _, brand := range r.URL.Query()["brand"] {
+		for _, b := range strings.Split(brand, ",") {
+			if b = strings.TrimSpace(b); b != "" {
+				params.Brands = append(params.Brands, b)
+			}
+		}
+	}
+
+	// filterable attributes are given as attr.<attribute slug>, like attr.fabric=polyester
+	for key, values := range r.URL.Query() {
+		slug, ok := strings.CutPrefix(key, "attr.")
+		if !ok || slug == "" || values[0] == "" {
+			continue
+		}
+		if params.Attributes == nil {
+			params.Attributes = make(map[string]string)
+		}
+		params.Attributes[strings.ToLower(slug)] = values[0]
+	}
+
 	return params
 }
 
@@ -378,3 +590,194 @@ func (h *ProductHandler) GetPublicProductByID(w http.ResponseWriter, r *http.Req
 
 	api.SendResponse(w, http.StatusOK, "Product retrieved successfully", product, "")
 }
+
+// GetPublicProductBySlug returns the product with the slug, old slugs of renamed products redirect to the current slug
+func (h *ProductHandler) GetPublicProductBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	product, currentSlug, err := h.productUseCase.GetPublicProductBySlug(r.Context(), slug)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Product not found", nil, "The requested product does not exist or has been deleted")
+		default:
+			log.Printf("Error retrieving product by slug: %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve product", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	if currentSlug != "" {
+		location := "/products/by-slug/" + currentSlug
+		w.Header().Set("Location", location)
+		api.SendResponse(w, http.StatusMovedPermanently, "Product has moved", map[string]string{
+			"slug":     currentSlug,
+			"location": location,
+		}, "")
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Product retrieved successfully", product, "")
+}
+
+func (h *ProductHandler) CreateProductVariant(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to create variant", nil, "Admin not authenticated")
+		return
+	}
+
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid product ID", nil, "Product ID must be a number")
+		return
+	}
+
+	var variant domain.ProductVariant
+	if err := json.NewDecoder(r.Body).Decode(&variant); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid request", nil, "Failed to parse request body")
+		return
+	}
+
+	err = h.productUseCase.CreateVariant(r.Context(), adminID, productID, &variant)
+	if err != nil {
+		handleVariantError(w, "Failed to create product variant", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusCreated, "Product variant created successfully", variant, "")
+}
+
+func (h *ProductHandler) GetProductVariants(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid product ID", nil, "Product ID must be a number")
+		return
+	}
+
+	variants, err := h.productUseCase.GetVariants(r.Context(), productID)
+	if err != nil {
+		handleVariantError(w, "Failed to retrieve product variants", err)
+		return
+	}
+
+	if len(variants) == 0 {
+		api.SendResponse(w, http.StatusOK, "No variants found", []struct{}{}, "")
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Product variants retrieved successfully", variants, "")
+}
+
+func (h *ProductHandler) UpdateProductVariant(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to update variant", nil, "Admin not authenticated")
+		return
+	}
+
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid product ID", nil, "Product ID must be a number")
+		return
+	}
+	variantID, err := strconv.ParseInt(vars["variantId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid variant ID", nil, "Variant ID must be a number")
+		return
+	}
+
+	var updateFields map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&updateFields); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid request", nil, "Failed to parse request body")
+		return
+	}
+
+	variant, err := h.productUseCase.UpdateVariant(r.Context(), adminID, productID, variantID, updateFields)
+	if err != nil {
+		handleVariantError(w, "Failed to update product variant", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Product variant updated successfully", variant, "")
+}
+
+func (h *ProductHandler) DeleteProductVariant(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to delete variant", nil, "Admin not authenticated")
+		return
+	}
+
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid product ID", nil, "Product ID must be a number")
+		return
+	}
+	variantID, err := strconv.ParseInt(vars["variantId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid variant ID", nil, "Variant ID must be a number")
+		return
+	}
+
+	err = h.productUseCase.DeleteVariant(r.Context(), adminID, productID, variantID)
+	if err != nil {
+		handleVariantError(w, "Failed to delete product variant", err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Product variant deleted successfully", nil, "")
+}
+
+func handleVariantError(w http.ResponseWriter, message string, err error) {
+	switch err {
+	case utils.ErrProductNotFound:
+		api.SendResponse(w, http.StatusNotFound, message, nil, "Product not found")
+	case utils.ErrVariantNotFound:
+		api.SendResponse(w, http.StatusNotFound, message, nil, "Product variant not found")
+	case utils.ErrInvalidVariantSKU:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Please provide a valid SKU without spaces")
+	case utils.ErrInvalidVariantAttributes:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "A variant should have a valid size or color")
+	case utils.ErrInvalidVariantPrice:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Please provide a valid variant price")
+	case utils.ErrInvalidStockQuantity:
+		api.SendResponse(w, http.StatusBadRequest, message, nil, "Please provide a valid stock quantity for the variant")
+	case utils.ErrDuplicateVariantSKU:
+		api.SendResponse(w, http.StatusConflict, message, nil, "SKU already exists")
+	case utils.ErrDuplicateVariant:
+		api.SendResponse(w, http.StatusConflict, message, nil, "A variant with the same size and color already exists for this product")
+	case utils.ErrInsufficientLocationStock:
+		api.SendResponse(w, http.StatusConflict, message, nil, "The primary location doesn't hold enough stock for the reduction, update the stock at each location instead")
+	default:
+		log.Printf("error while handling product variant : %v", err)
+		api.SendResponse(w, http.StatusInternalServerError, message, nil, "An unexpected error occurred")
+	}
+}
+
+func (h *ProductHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Invalid product ID", nil, "Product ID must be a number")
+		return
+	}
+
+	history, err := h.productUseCase.GetPriceHistory(r.Context(), productID)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to retrieve price history", nil, "Product not found")
+		default:
+			log.Printf("error while retrieving product price history : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve price history", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Price history retrieved successfully", history, "")
+}
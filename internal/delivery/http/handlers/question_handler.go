@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/delivery/http/middleware"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type QuestionHandler struct {
+	questionUseCase usecase.QuestionUseCase
+}
+
+func NewQuestionHandler(questionUseCase usecase.QuestionUseCase) *QuestionHandler {
+	return &QuestionHandler{questionUseCase: questionUseCase}
+}
+
+func (h *QuestionHandler) AskQuestion(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to add question", nil, "User not authenticated")
+		return
+	}
+
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to add question", nil, "Invalid product ID")
+		return
+	}
+
+	var input struct {
+		Question string `json:"question"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to add question", nil, "Invalid request body")
+		return
+	}
+
+	question, err := h.questionUseCase.AskQuestion(r.Context(), userID, productID, input.Question)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to add question", nil, "Product not found")
+		case utils.ErrInvalidQuestion:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to add question", nil, "Question should be between 5 and 1000 characters")
+		default:
+			log.Printf("error while adding question : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to add question", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusCreated, "Question added successfully", question, "")
+}
+
+func (h *QuestionHandler) AnswerQuestion(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to add answer", nil, "User not authenticated")
+		return
+	}
+
+	questionID, answer, ok := parseAnswerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.questionUseCase.AnswerQuestion(r.Context(), userID, questionID, answer)
+	if err != nil {
+		sendAnswerError(w, err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusCreated, "Answer added successfully", result, "")
+}
+
+func (h *QuestionHandler) AdminAnswerQuestion(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to add answer", nil, "Admin not authenticated")
+		return
+	}
+
+	questionID, answer, ok := parseAnswerRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.questionUseCase.AdminAnswerQuestion(r.Context(), adminID, questionID, answer)
+	if err != nil {
+		sendAnswerError(w, err)
+		return
+	}
+
+	api.SendResponse(w, http.StatusCreated, "Answer added successfully", result, "")
+}
+
+func (h *QuestionHandler) GetProductQuestions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve questions", nil, "Invalid product ID")
+		return
+	}
+
+	page, limit := parseReviewPagination(r)
+
+	questions, err := h.questionUseCase.GetProductQuestions(r.Context(), productID, page, limit)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to retrieve questions", nil, "Product not found")
+		default:
+			log.Printf("error while retrieving product questions : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve questions", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Questions retrieved successfully", questions, "")
+}
+
+func (h *QuestionHandler) GetQuestions(w http.ResponseWriter, r *http.Request) {
+	params := domain.QuestionQueryParams{
+		Status: r.URL.Query().Get("status"),
+	}
+	params.Page, params.Limit = parseReviewPagination(r)
+
+	if productID, err := strconv.ParseInt(r.URL.Query().Get("product_id"), 10, 64); err == nil {
+		params.ProductID = productID
+	}
+
+	questions, err := h.questionUseCase.GetQuestions(r.Context(), params)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidQAStatus:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve questions", nil, "Status should be published or hidden")
+		default:
+			log.Printf("error while retrieving questions : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve questions", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Questions retrieved successfully", questions, "")
+}
+
+func (h *QuestionHandler) PublishQuestion(w http.ResponseWriter, r *http.Request) {
+	h.updateQuestionStatus(w, r, utils.QAStatusPublished)
+}
+
+func (h *QuestionHandler) HideQuestion(w http.ResponseWriter, r *http.Request) {
+	h.updateQuestionStatus(w, r, utils.QAStatusHidden)
+}
+
+func (h *QuestionHandler) updateQuestionStatus(w http.ResponseWriter, r *http.Request, status string) {
+	vars := mux.Vars(r)
+	questionID, err := strconv.ParseInt(vars["questionId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update question", nil, "Invalid question ID")
+		return
+	}
+
+	question, err := h.questionUseCase.UpdateQuestionStatus(r.Context(), questionID, status)
+	if err != nil {
+		switch err {
+		case utils.ErrQuestionNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to update question", nil, "Question not found")
+		case utils.ErrQAAlreadyInStatus:
+			api.SendResponse(w, http.StatusConflict, "Failed to update question", nil, "Question is already "+status)
+		default:
+			log.Printf("error while updating question status : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to update question", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Question "+status+" successfully", question, "")
+}
+
+func (h *QuestionHandler) PublishAnswer(w http.ResponseWriter, r *http.Request) {
+	h.updateAnswerStatus(w, r, utils.QAStatusPublished)
+}
+
+func (h *QuestionHandler) HideAnswer(w http.ResponseWriter, r *http.Request) {
+	h.updateAnswerStatus(w, r, utils.QAStatusHidden)
+}
+
+func (h *QuestionHandler) updateAnswerStatus(w http.ResponseWriter, r *http.Request, status string) {
+	vars := mux.Vars(r)
+	answerID, err := strconv.ParseInt(vars["answerId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update answer", nil, "Invalid answer ID")
+		return
+	}
+
+	answer, err := h.questionUseCase.UpdateAnswerStatus(r.Context(), answerID, status)
+	if err != nil {
+		switch err {
+		case utils.ErrAnswerNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to update answer", nil, "Answer not found")
+		case utils.ErrQAAlreadyInStatus:
+			api.SendResponse(w, http.StatusConflict, "Failed to update answer", nil, "Answer is already "+status)
+		default:
+			log.Printf("error while updating answer status : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to update answer", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Answer "+status+" successfully", answer, "")
+}
+
+func (h *QuestionHandler) UpvoteAnswer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to upvote answer", nil, "User not authenticated")
+		return
+	}
+
+	vars := mux.Vars(r)
+	answerID, err := strconv.ParseInt(vars["answerId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to upvote answer", nil, "Invalid answer ID")
+		return
+	}
+
+	upvoteCount, err := h.questionUseCase.UpvoteAnswer(r.Context(), userID, answerID)
+	if err != nil {
+		switch err {
+		case utils.ErrAnswerNotFound, utils.ErrQuestionNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to upvote answer", nil, "Answer not found")
+		case utils.ErrCannotUpvoteOwnAnswer:
+			api.SendResponse(w, http.StatusForbidden, "Failed to upvote answer", nil, "You can't upvote your own answer")
+		case utils.ErrAlreadyUpvoted:
+			api.SendResponse(w, http.StatusConflict, "Failed to upvote answer", nil, "You have already upvoted this answer")
+		default:
+			log.Printf("error while upvoting answer : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to upvote answer", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Answer upvoted successfully", map[string]interface{}{
+		"answer_id":    answerID,
+		"upvote_count": upvoteCount,
+	}, "")
+}
+
+func (h *QuestionHandler) RemoveAnswerUpvote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to remove upvote", nil, "User not authenticated")
+		return
+	}
+
+	vars := mux.Vars(r)
+	answerID, err := strconv.ParseInt(vars["answerId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to remove upvote", nil, "Invalid answer ID")
+		return
+	}
+
+	upvoteCount, err := h.questionUseCase.RemoveAnswerUpvote(r.Context(), userID, answerID)
+	if err != nil {
+		switch err {
+		case utils.ErrAnswerNotFound, utils.ErrQuestionNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to remove upvote", nil, "Answer not found")
+		case utils.ErrUpvoteNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to remove upvote", nil, "You have not upvoted this answer")
+		default:
+			log.Printf("error while removing answer upvote : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to remove upvote", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Upvote removed successfully", map[string]interface{}{
+		"answer_id":    answerID,
+		"upvote_count": upvoteCount,
+	}, "")
+}
+
+// parseAnswerRequest reads the question id and the answer, error response is sent when they are invalid
+func parseAnswerRequest(w http.ResponseWriter, r *http.Request) (int64, string, bool) {
+	vars := mux.Vars(r)
+	questionID, err := strconv.ParseInt(vars["questionId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to add answer", nil, "Invalid question ID")
+		return 0, "", false
+	}
+
+	var input struct {
+		Answer string `json:"answer"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to add answer", nil, "Invalid request body")
+		return 0, "", false
+	}
+
+	return questionID, input.Answer, true
+}
+
+func sendAnswerError(w http.ResponseWriter, err error) {
+	switch err {
+	case utils.ErrQuestionNotFound:
+		api.SendResponse(w, http.StatusNotFound, "Failed to add answer", nil, "Question not found")
+	case utils.ErrInvalidAnswer:
+		api.SendResponse(w, http.StatusBadRequest, "Failed to add answer", nil, "Answer should be between 1 and 2000 characters")
+	case utils.ErrAnswerNotAllowed:
+		api.SendResponse(w, http.StatusForbidden, "Failed to add answer", nil, "Only buyers of the product can answer this question")
+	default:
+		log.Printf("error while adding answer : %v", err)
+		api.SendResponse(w, http.StatusInternalServerError, "Failed to add answer", nil, "An unexpected error occurred")
+	}
+}
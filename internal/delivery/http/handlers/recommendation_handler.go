@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/delivery/http/middleware"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type RecommendationHandler struct {
+	recommendationUseCase usecase.RecommendationUseCase
+}
+
+func NewRecommendationHandler(recommendationUseCase usecase.RecommendationUseCase) *RecommendationHandler {
+	return &RecommendationHandler{recommendationUseCase: recommendationUseCase}
+}
+
+func (h *RecommendationHandler) GetRelatedProducts(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(mux.Vars(r)["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve related products", nil, "Invalid product ID")
+		return
+	}
+
+	// invalid limit falls back to the default limit
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	products, err := h.recommendationUseCase.GetRelatedProducts(r.Context(), productID, limit)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to retrieve related products", nil, "Product not found")
+		default:
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve related products", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Related products retrieved successfully", products, "")
+}
+
+func (h *RecommendationHandler) GetCartRecommendations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to retrieve recommendations", nil, "User not authenticated")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	products, err := h.recommendationUseCase.GetCartRecommendations(r.Context(), userID, limit)
+	if err != nil {
+		api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve recommendations", nil, "An unexpected error occurred")
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Recommendations retrieved successfully", products, "")
+}
@@ -193,6 +193,12 @@ func (h *ReturnHandler) InitiateRefund(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *ReturnHandler) MarkOrderReturnedToSeller(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to mark order as returned", nil, "Admin not authenticated")
+		return
+	}
+
 	// Extract return ID from URL
 	vars := mux.Vars(r)
 	returnID, err := strconv.ParseInt(vars["returnId"], 10, 64)
@@ -202,7 +208,7 @@ func (h *ReturnHandler) MarkOrderReturnedToSeller(w http.ResponseWriter, r *http
 	}
 
 	// Call the use case method
-	returnRequest, err := h.returnUseCase.MarkOrderReturnedToSeller(r.Context(), returnID)
+	returnRequest, err := h.returnUseCase.MarkOrderReturnedToSeller(r.Context(), adminID, returnID)
 	if err != nil {
 		switch err {
 		case utils.ErrReturnRequestNotFound:
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/delivery/http/middleware"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type ReviewHandler struct {
+	reviewUseCase usecase.ReviewUseCase
+}
+
+func NewReviewHandler(reviewUseCase usecase.ReviewUseCase) *ReviewHandler {
+	return &ReviewHandler{reviewUseCase: reviewUseCase}
+}
+
+func (h *ReviewHandler) AddReview(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		api.SendResponse(w, http.StatusUnauthorized, "Failed to add review", nil, "User not authenticated")
+		return
+	}
+
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to add review", nil, "Invalid product ID")
+		return
+	}
+
+	var input struct {
+		Rating  int    `json:"rating"`
+		Comment string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to add review", nil, "Invalid request body")
+		return
+	}
+
+	review, err := h.reviewUseCase.AddReview(r.Context(), userID, productID, input.Rating, input.Comment)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to add review", nil, "Product not found")
+		case utils.ErrInvalidRating:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to add review", nil, "Rating should be between 1 and 5")
+		case utils.ErrReviewTooLong:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to add review", nil, "Review should be less than 2000 characters")
+		case utils.ErrReviewNotAllowed:
+			api.SendResponse(w, http.StatusForbidden, "Failed to add review", nil, "Product can be reviewed only after your order containing it is delivered")
+		case utils.ErrDuplicateReview:
+			api.SendResponse(w, http.StatusConflict, "Failed to add review", nil, "You have already reviewed this product")
+		default:
+			log.Printf("error while adding review : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to add review", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusCreated, "Review submitted successfully, it will be visible once approved", review, "")
+}
+
+func (h *ReviewHandler) GetProductReviews(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID, err := strconv.ParseInt(vars["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve reviews", nil, "Invalid product ID")
+		return
+	}
+
+	page, limit := parseReviewPagination(r)
+
+	reviews, totalCount, err := h.reviewUseCase.GetProductReviews(r.Context(), productID, page, limit)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to retrieve reviews", nil, "Product not found")
+		default:
+			log.Printf("error while retrieving product reviews : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve reviews", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"reviews":     reviews,
+		"total_count": totalCount,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": (totalCount + int64(limit) - 1) / int64(limit),
+	}
+
+	api.SendResponse(w, http.StatusOK, "Reviews retrieved successfully", response, "")
+}
+
+func (h *ReviewHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
+	params := domain.ReviewQueryParams{
+		Status: r.URL.Query().Get("status"),
+	}
+	params.Page, params.Limit = parseReviewPagination(r)
+
+	if productID, err := strconv.ParseInt(r.URL.Query().Get("product_id"), 10, 64); err == nil {
+		params.ProductID = productID
+	}
+
+	reviews, totalCount, err := h.reviewUseCase.GetReviews(r.Context(), params)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidReviewStatus:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to retrieve reviews", nil, "Status should be pending, approved or hidden")
+		default:
+			log.Printf("error while retrieving reviews : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve reviews", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"reviews":     reviews,
+		"total_count": totalCount,
+		"page":        params.Page,
+		"limit":       params.Limit,
+		"total_pages": (totalCount + int64(params.Limit) - 1) / int64(params.Limit),
+	}
+
+	api.SendResponse(w, http.StatusOK, "Reviews retrieved successfully", response, "")
+}
+
+func (h *ReviewHandler) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	h.updateReviewStatus(w, r, utils.ReviewStatusApproved)
+}
+
+func (h *ReviewHandler) HideReview(w http.ResponseWriter, r *http.Request) {
+	h.updateReviewStatus(w, r, utils.ReviewStatusHidden)
+}
+
+func (h *ReviewHandler) updateReviewStatus(w http.ResponseWriter, r *http.Request, status string) {
+	vars := mux.Vars(r)
+	reviewID, err := strconv.ParseInt(vars["reviewId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to update review", nil, "Invalid review ID")
+		return
+	}
+
+	review, err := h.reviewUseCase.UpdateReviewStatus(r.Context(), reviewID, status)
+	if err != nil {
+		switch err {
+		case utils.ErrReviewNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to update review", nil, "Review not found")
+		case utils.ErrReviewAlreadyInStatus:
+			api.SendResponse(w, http.StatusConflict, "Failed to update review", nil, "Review is already "+status)
+		default:
+			log.Printf("error while updating review status : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to update review", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Review "+status+" successfully", review, "")
+}
+
+func parseReviewPagination(r *http.Request) (int, int) {
+	page, limit := 1, 10
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	return page, limit
+}
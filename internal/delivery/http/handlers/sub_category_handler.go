@@ -188,7 +188,7 @@ func (h *SubCategoryHandler) SoftDeleteSubCategory(w http.ResponseWriter, r *htt
 		return
 	}
 
-	err = h.subCategoryUseCase.SoftDeleteSubCategory(r.Context(), categoryID, subCategoryID)
+	result, err := h.subCategoryUseCase.SoftDeleteSubCategory(r.Context(), categoryID, subCategoryID)
 	if err != nil {
 		switch err {
 		case utils.ErrCategoryNotFound:
@@ -203,5 +203,5 @@ func (h *SubCategoryHandler) SoftDeleteSubCategory(w http.ResponseWriter, r *htt
 		return
 	}
 
-	api.SendResponse(w, http.StatusOK, "Sub-category deleted successfully", nil, "")
+	api.SendResponse(w, http.StatusOK, "Sub-category deleted successfully", result, "")
 }
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type TrashHandler struct {
+	trashUseCase usecase.TrashUseCase
+}
+
+func NewTrashHandler(trashUseCase usecase.TrashUseCase) *TrashHandler {
+	return &TrashHandler{trashUseCase: trashUseCase}
+}
+
+func (h *TrashHandler) GetDeletedProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := h.trashUseCase.GetDeletedProducts(r.Context())
+	if err != nil {
+		api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve deleted products", nil, "An unexpected error occurred")
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Deleted products retrieved successfully", products, "")
+}
+
+func (h *TrashHandler) GetDeletedCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.trashUseCase.GetDeletedCategories(r.Context())
+	if err != nil {
+		api.SendResponse(w, http.StatusInternalServerError, "Failed to retrieve deleted categories", nil, "An unexpected error occurred")
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Deleted categories retrieved successfully", categories, "")
+}
+
+func (h *TrashHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(mux.Vars(r)["productId"], 10, 64)
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to restore product", nil, "Invalid product ID")
+		return
+	}
+
+	err = h.trashUseCase.RestoreProduct(r.Context(), productID)
+	if err != nil {
+		switch err {
+		case utils.ErrProductNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to restore product", nil, "Product not found in the trash")
+		case utils.ErrParentDeleted:
+			api.SendResponse(w, http.StatusConflict, "Failed to restore product", nil, "Sub category of the product is deleted, restore it first")
+		case utils.ErrDuplicateProductName:
+			api.SendResponse(w, http.StatusConflict, "Failed to restore product", nil, "An active product with the same name exists")
+		case utils.ErrSlugTaken:
+			api.SendResponse(w, http.StatusConflict, "Failed to restore product", nil, "An active product with the same slug exists")
+		default:
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to restore product", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Product restored successfully", nil, "")
+}
+
+func (h *TrashHandler) RestoreCategory(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := strconv.Atoi(mux.Vars(r)["categoryId"])
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to restore category", nil, "Invalid category ID")
+		return
+	}
+
+	result, err := h.trashUseCase.RestoreCategory(r.Context(), categoryID)
+	if err != nil {
+		switch err {
+		case utils.ErrCategoryNotFound:
+			api.SendResponse(w, http.StatusNotFound, "Failed to restore category", nil, "Category not found in the trash")
+		case utils.ErrParentDeleted:
+			api.SendResponse(w, http.StatusConflict, "Failed to restore category", nil, "Parent category is deleted, restore it first")
+		default:
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to restore category", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Category restored successfully", result, "")
+}
+
+// Purge permanently deletes the entries which are in the trash for more than older_than_days days
+func (h *TrashHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	olderThanDays, err := strconv.Atoi(r.URL.Query().Get("older_than_days"))
+	if err != nil {
+		api.SendResponse(w, http.StatusBadRequest, "Failed to purge trash", nil, utils.ErrInvalidRetentionDay.Error())
+		return
+	}
+
+	result, err := h.trashUseCase.Purge(r.Context(), olderThanDays)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidRetentionDay:
+			api.SendResponse(w, http.StatusBadRequest, "Failed to purge trash", nil, err.Error())
+		default:
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to purge trash", nil, "An unexpected error occurred")
+		}
+		return
+	}
+
+	api.SendResponse(w, http.StatusOK, "Trash purged successfully", result, "")
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/delivery/http/middleware"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/api"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/pagination"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 )
 
@@ -56,6 +57,28 @@ func (h *WalletHandler) GetWalletTransactions(w http.ResponseWriter, r *http.Req
 	order := r.URL.Query().Get("order")
 	transactionType := r.URL.Query().Get("type")
 
+	// cursor pagination only supports the newest first order
+	if cursorParams, ok, err := pagination.FromRequest(r); ok {
+		if err != nil {
+			api.SendResponse(w, http.StatusBadRequest, "Failed to get wallet transactions", nil, "Invalid cursor")
+			return
+		}
+		transactions, page, err := h.walletUseCase.GetWalletTransactionsByCursor(r.Context(), userID, transactionType, cursorParams)
+		if err != nil {
+			log.Printf("error : %v", err)
+			api.SendResponse(w, http.StatusInternalServerError, "Failed to get wallet transactions", nil, "An unexpected error occurred")
+			return
+		}
+		response := map[string]interface{}{
+			"transactions": transactions,
+			"next_cursor":  page.NextCursor,
+			"prev_cursor":  page.PrevCursor,
+			"limit":        page.Limit,
+		}
+		api.SendResponse(w, http.StatusOK, "Wallet transactions retrieved successfully", response, "")
+		return
+	}
+
 	// Validate and set default values
 	if page < 1 {
 		page = 1
@@ -10,6 +10,7 @@ import (
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/delivery/http/handlers"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/delivery/http/middleware"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/auth"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/storage"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 )
@@ -95,6 +96,15 @@ func NewRouter(userHandler *handlers.UserHandler,
 	salesHandler *handlers.SalesHandler,
 	analyticsHandler *handlers.AnalyticsHandler,
 	returnHandler *handlers.ReturnHandler,
+	reviewHandler *handlers.ReviewHandler,
+	brandHandler *handlers.BrandHandler,
+	offerHandler *handlers.OfferHandler,
+	attributeHandler *handlers.ProductAttributeHandler,
+	recommendationHandler *handlers.RecommendationHandler,
+	trashHandler *handlers.TrashHandler,
+	questionHandler *handlers.QuestionHandler,
+	locationHandler *handlers.LocationHandler,
+	imageStorage storage.ImageStorage,
 	templates *template.Template) http.Handler {
 	log.Println("Setting up router...")
 
@@ -119,6 +129,7 @@ func NewRouter(userHandler *handlers.UserHandler,
 	// Admin routes: Category management
 	r.HandleFunc("/admin/categories", chainMiddleware(jwtAuth, adminAuth)(categoryHandler.CreateCategory)).Methods("POST")
 	r.HandleFunc("/admin/categories", chainMiddleware(jwtAuth, adminAuth)(categoryHandler.GetAllCategories)).Methods("GET")
+	r.HandleFunc("/admin/categories/tree", chainMiddleware(jwtAuth, adminAuth)(categoryHandler.GetCategoryTree)).Methods("GET")
 	r.HandleFunc("/admin/categories/{categoryId}", chainMiddleware(jwtAuth, adminAuth)(categoryHandler.GetActiveCategoryByID)).Methods("GET")
 	r.HandleFunc("/admin/categories/{categoryId}", chainMiddleware(jwtAuth, adminAuth)(categoryHandler.UpdateCategory)).Methods("PUT")
 	r.HandleFunc("/admin/categories/{categoryId}", chainMiddleware(jwtAuth, adminAuth)(categoryHandler.SoftDeleteCategory)).Methods("DELETE")
@@ -130,27 +141,79 @@ func NewRouter(userHandler *handlers.UserHandler,
 	r.HandleFunc("/admin/categories/{categoryId}/subcategories/{subcategoryId}", chainMiddleware(jwtAuth, adminAuth)(subCategoryHandler.UpdateSubCategory)).Methods("PUT")
 	r.HandleFunc("/admin/categories/{categoryId}/subcategories/{subcategoryId}", chainMiddleware(jwtAuth, adminAuth)(subCategoryHandler.SoftDeleteSubCategory)).Methods("DELETE")
 
+	// Admin routes: Brand management
+	r.HandleFunc("/admin/brands", chainMiddleware(jwtAuth, adminAuth)(brandHandler.CreateBrand)).Methods("POST")
+	r.HandleFunc("/admin/brands", chainMiddleware(jwtAuth, adminAuth)(brandHandler.GetAllBrands)).Methods("GET")
+	r.HandleFunc("/admin/brands/{brandId}", chainMiddleware(jwtAuth, adminAuth)(brandHandler.GetBrandByID)).Methods("GET")
+	r.HandleFunc("/admin/brands/{brandId}", chainMiddleware(jwtAuth, adminAuth)(brandHandler.UpdateBrand)).Methods("PUT")
+	r.HandleFunc("/admin/brands/{brandId}", chainMiddleware(jwtAuth, adminAuth)(brandHandler.SoftDeleteBrand)).Methods("DELETE")
+
 	// Admin routes: Product management
 	r.HandleFunc("/admin/products", chainMiddleware(jwtAuth, adminAuth)(productHandler.CreateProduct)).Methods("POST")
 	r.HandleFunc("/admin/products", chainMiddleware(jwtAuth, adminAuth)(productHandler.GetAllProducts)).Methods("GET")
+	r.HandleFunc("/admin/products/import", chainMiddleware(jwtAuth, adminAuth)(productHandler.ImportProducts)).Methods("POST")
+	r.HandleFunc("/admin/products/export", chainMiddleware(jwtAuth, adminAuth)(productHandler.ExportProducts)).Methods("GET")
 	r.HandleFunc("/admin/products/{productId}", chainMiddleware(jwtAuth, adminAuth)(productHandler.GetProductByID)).Methods("GET")
 	r.HandleFunc("/admin/products/{productId}", chainMiddleware(jwtAuth, adminAuth)(productHandler.UpdateProduct)).Methods("PATCH")
 	r.HandleFunc("/admin/products/{productId}", chainMiddleware(jwtAuth, adminAuth)(productHandler.SoftDeleteProduct)).Methods("DELETE")
 
 	// Admin routes : Product image management
 	r.HandleFunc("/admin/products/{productId}/images", chainMiddleware(jwtAuth, adminAuth)(productHandler.AddProductImages)).Methods("POST")
+	r.HandleFunc("/admin/products/{productId}/images", chainMiddleware(jwtAuth, adminAuth)(productHandler.ReorderProductImages)).Methods("PUT")
 	r.HandleFunc("/admin/products/{productId}/images/{imageId}", chainMiddleware(jwtAuth, adminAuth)(productHandler.DeleteProductImage)).Methods("DELETE")
 
+	r.HandleFunc("/admin/products/{productId}/price-history", chainMiddleware(jwtAuth, adminAuth)(productHandler.GetPriceHistory)).Methods("GET")
+
+	// Admin routes : Trash bin, soft deleted products and categories
+	r.HandleFunc("/admin/trash/products", chainMiddleware(jwtAuth, adminAuth)(trashHandler.GetDeletedProducts)).Methods("GET")
+	r.HandleFunc("/admin/trash/products/{productId}/restore", chainMiddleware(jwtAuth, adminAuth)(trashHandler.RestoreProduct)).Methods("POST")
+	r.HandleFunc("/admin/trash/categories", chainMiddleware(jwtAuth, adminAuth)(trashHandler.GetDeletedCategories)).Methods("GET")
+	r.HandleFunc("/admin/trash/categories/{categoryId}/restore", chainMiddleware(jwtAuth, adminAuth)(trashHandler.RestoreCategory)).Methods("POST")
+	r.HandleFunc("/admin/trash", chainMiddleware(jwtAuth, adminAuth)(trashHandler.Purge)).Methods("DELETE")
+
+	// Admin routes : Product attributes, the schema is defined per sub category
+	r.HandleFunc("/admin/subcategories/{subcategoryId}/attributes", chainMiddleware(jwtAuth, adminAuth)(attributeHandler.CreateAttribute)).Methods("POST")
+	r.HandleFunc("/admin/subcategories/{subcategoryId}/attributes", chainMiddleware(jwtAuth, adminAuth)(attributeHandler.GetAttributes)).Methods("GET")
+	r.HandleFunc("/admin/attributes/{attributeId}", chainMiddleware(jwtAuth, adminAuth)(attributeHandler.UpdateAttribute)).Methods("PUT")
+	r.HandleFunc("/admin/attributes/{attributeId}", chainMiddleware(jwtAuth, adminAuth)(attributeHandler.DeleteAttribute)).Methods("DELETE")
+	r.HandleFunc("/admin/products/{productId}/attributes", chainMiddleware(jwtAuth, adminAuth)(attributeHandler.SetProductAttributes)).Methods("PUT")
+	r.HandleFunc("/admin/products/{productId}/attributes", chainMiddleware(jwtAuth, adminAuth)(attributeHandler.GetProductAttributes)).Methods("GET")
+
+	// Admin routes : Product variant management
+	r.HandleFunc("/admin/products/{productId}/variants", chainMiddleware(jwtAuth, adminAuth)(productHandler.CreateProductVariant)).Methods("POST")
+	r.HandleFunc("/admin/products/{productId}/variants", chainMiddleware(jwtAuth, adminAuth)(productHandler.GetProductVariants)).Methods("GET")
+	r.HandleFunc("/admin/products/{productId}/variants/{variantId}", chainMiddleware(jwtAuth, adminAuth)(productHandler.UpdateProductVariant)).Methods("PATCH")
+	r.HandleFunc("/admin/products/{productId}/variants/{variantId}", chainMiddleware(jwtAuth, adminAuth)(productHandler.DeleteProductVariant)).Methods("DELETE")
+
 	// Admin routes : Coupon management
 	r.HandleFunc("/admin/coupons", chainMiddleware(jwtAuth, adminAuth)(couponHandler.CreateCoupon)).Methods("POST")
 	r.HandleFunc("/admin/coupons/{coupon_id}", chainMiddleware(jwtAuth, adminAuth)(couponHandler.UpdateCoupon)).Methods("PATCH")
 
+	// Admin routes : Offer management
+	r.HandleFunc("/admin/offers", chainMiddleware(jwtAuth, adminAuth)(offerHandler.CreateOffer)).Methods("POST")
+	r.HandleFunc("/admin/offers", chainMiddleware(jwtAuth, adminAuth)(offerHandler.GetOffers)).Methods("GET")
+	r.HandleFunc("/admin/offers/{offerId}", chainMiddleware(jwtAuth, adminAuth)(offerHandler.DeactivateOffer)).Methods("DELETE")
+
 	// admin routes : order management
 	r.HandleFunc("/admin/orders", chainMiddleware(jwtAuth, adminAuth)(orderHandler.GetOrders)).Methods("GET")
 
 	// admin : inventory management
 	r.HandleFunc("/admin/inventory", chainMiddleware(jwtAuth, adminAuth)(inventoryHandler.GetInventory)).Methods("GET")
+	r.HandleFunc("/admin/inventory/consistency", chainMiddleware(jwtAuth, adminAuth)(inventoryHandler.CheckStockConsistency)).Methods("GET")
+	r.HandleFunc("/admin/inventory/alerts", chainMiddleware(jwtAuth, adminAuth)(inventoryHandler.GetStockAlerts)).Methods("GET")
+	r.HandleFunc("/admin/inventory/transfers", chainMiddleware(jwtAuth, adminAuth)(locationHandler.TransferStock)).Methods("POST")
+	r.HandleFunc("/admin/inventory/transfers", chainMiddleware(jwtAuth, adminAuth)(locationHandler.GetTransfers)).Methods("GET")
+	r.HandleFunc("/admin/inventory/{productId}/reorder-threshold", chainMiddleware(jwtAuth, adminAuth)(inventoryHandler.SetReorderThreshold)).Methods("PUT")
+	r.HandleFunc("/admin/inventory/{productId}/movements", chainMiddleware(jwtAuth, adminAuth)(inventoryHandler.GetStockMovements)).Methods("GET")
 	r.HandleFunc("/admin/inventory/{productId}", chainMiddleware(jwtAuth, adminAuth)(inventoryHandler.UpdateProductStock)).Methods("PATCH")
+	r.HandleFunc("/admin/inventory/{productId}/variants/{variantId}", chainMiddleware(jwtAuth, adminAuth)(inventoryHandler.UpdateVariantStock)).Methods("PATCH")
+
+	// admin : inventory locations
+	r.HandleFunc("/admin/locations", chainMiddleware(jwtAuth, adminAuth)(locationHandler.CreateLocation)).Methods("POST")
+	r.HandleFunc("/admin/locations", chainMiddleware(jwtAuth, adminAuth)(locationHandler.GetLocations)).Methods("GET")
+	r.HandleFunc("/admin/locations/{locationId}", chainMiddleware(jwtAuth, adminAuth)(locationHandler.UpdateLocation)).Methods("PATCH")
+	r.HandleFunc("/admin/locations/{locationId}/stock", chainMiddleware(jwtAuth, adminAuth)(locationHandler.GetLocationStock)).Methods("GET")
+	r.HandleFunc("/admin/locations/{locationId}/stock", chainMiddleware(jwtAuth, adminAuth)(locationHandler.SetLocationStock)).Methods("PUT")
 
 	// User routes : login, sign up
 	r.HandleFunc("/user/login", userHandler.Login).Methods("POST")
@@ -189,6 +252,7 @@ func NewRouter(userHandler *handlers.UserHandler,
 	r.HandleFunc("/user/cart/items/{itemId}", chainMiddleware(jwtAuth, userAuth)(cartHandler.UpdateCartItemQuantity)).Methods("PATCH")
 	r.HandleFunc("/user/cart/items/{itemId}", chainMiddleware(jwtAuth, userAuth)(cartHandler.DeleteCartItem)).Methods("DELETE")
 	r.HandleFunc("/user/cart/clear-cart", chainMiddleware(jwtAuth, userAuth)(cartHandler.ClearCart)).Methods("DELETE")
+	r.HandleFunc("/user/cart/recommendations", chainMiddleware(jwtAuth, userAuth)(recommendationHandler.GetCartRecommendations)).Methods("GET")
 
 	// User routes : Checkout
 	r.HandleFunc("/user/checkout", chainMiddleware(jwtAuth, userAuth)(checkoutHandler.CreateCheckout)).Methods("POST")
@@ -238,6 +302,26 @@ func NewRouter(userHandler *handlers.UserHandler,
 	// Admin initiate order cancellation
 	r.HandleFunc("/admin/orders/{orderId}/cancel", chainMiddleware(jwtAuth, adminAuth)(orderHandler.AdminCancelOrder)).Methods("POST")
 
+	// product reviews
+	r.HandleFunc("/user/products/{productId}/reviews", chainMiddleware(jwtAuth, userAuth)(reviewHandler.AddReview)).Methods("POST")
+	// admin : review moderation
+	r.HandleFunc("/admin/reviews", chainMiddleware(jwtAuth, adminAuth)(reviewHandler.GetReviews)).Methods("GET")
+	r.HandleFunc("/admin/reviews/{reviewId}/approve", chainMiddleware(jwtAuth, adminAuth)(reviewHandler.ApproveReview)).Methods("PATCH")
+	r.HandleFunc("/admin/reviews/{reviewId}/hide", chainMiddleware(jwtAuth, adminAuth)(reviewHandler.HideReview)).Methods("PATCH")
+
+	// product questions and answers
+	r.HandleFunc("/user/products/{productId}/questions", chainMiddleware(jwtAuth, userAuth)(questionHandler.AskQuestion)).Methods("POST")
+	r.HandleFunc("/user/questions/{questionId}/answers", chainMiddleware(jwtAuth, userAuth)(questionHandler.AnswerQuestion)).Methods("POST")
+	r.HandleFunc("/user/answers/{answerId}/upvote", chainMiddleware(jwtAuth, userAuth)(questionHandler.UpvoteAnswer)).Methods("POST")
+	r.HandleFunc("/user/answers/{answerId}/upvote", chainMiddleware(jwtAuth, userAuth)(questionHandler.RemoveAnswerUpvote)).Methods("DELETE")
+	// admin : answer questions and moderate questions and answers
+	r.HandleFunc("/admin/questions", chainMiddleware(jwtAuth, adminAuth)(questionHandler.GetQuestions)).Methods("GET")
+	r.HandleFunc("/admin/questions/{questionId}/answers", chainMiddleware(jwtAuth, adminAuth)(questionHandler.AdminAnswerQuestion)).Methods("POST")
+	r.HandleFunc("/admin/questions/{questionId}/publish", chainMiddleware(jwtAuth, adminAuth)(questionHandler.PublishQuestion)).Methods("PATCH")
+	r.HandleFunc("/admin/questions/{questionId}/hide", chainMiddleware(jwtAuth, adminAuth)(questionHandler.HideQuestion)).Methods("PATCH")
+	r.HandleFunc("/admin/answers/{answerId}/publish", chainMiddleware(jwtAuth, adminAuth)(questionHandler.PublishAnswer)).Methods("PATCH")
+	r.HandleFunc("/admin/answers/{answerId}/hide", chainMiddleware(jwtAuth, adminAuth)(questionHandler.HideAnswer)).Methods("PATCH")
+
 	// order invoice
 	r.HandleFunc("/user/orders/{orderId}/invoice", chainMiddleware(jwtAuth, userAuth)(orderHandler.GetOrderInvoice)).Methods("GET")
 
@@ -247,12 +331,25 @@ func NewRouter(userHandler *handlers.UserHandler,
 
 	// Public routes : Homepage
 	r.HandleFunc("/products", productHandler.GetProducts).Methods("GET")
+	r.HandleFunc("/products/by-slug/{slug:.+}", productHandler.GetPublicProductBySlug).Methods("GET")
 	r.HandleFunc("/products/{productId}", productHandler.GetPublicProductByID).Methods("GET")
+	r.HandleFunc("/products/{productId}/reviews", reviewHandler.GetProductReviews).Methods("GET")
+	r.HandleFunc("/products/{productId}/questions", questionHandler.GetProductQuestions).Methods("GET")
+	r.HandleFunc("/products/{productId}/related", recommendationHandler.GetRelatedProducts).Methods("GET")
 	r.HandleFunc("/coupons", couponHandler.GetAllCoupons).Methods("GET")
+	r.HandleFunc("/brands", brandHandler.GetAllBrands).Methods("GET")
+	r.HandleFunc("/categories", categoryHandler.GetPublicCategoryTree).Methods("GET")
+	r.HandleFunc("/categories/{categoryId}", categoryHandler.GetPublicCategory).Methods("GET")
+
+	// Public routes : product images, when the images are stored on the local disk
+	if staticStorage, ok := imageStorage.(storage.StaticImageStorage); ok {
+		r.PathPrefix(staticStorage.URLPrefix()).Handler(staticStorage.Handler()).Methods("GET")
+	}
 
 	// razorpay gateway: front end api end points
 	r.HandleFunc("/home/payment", paymentHandler.RenderPaymentPage).Methods("GET")
 	r.HandleFunc("/home/razorpay-payment", paymentHandler.ProcessRazorpayPayment).Methods("POST")
+	r.HandleFunc("/home/razorpay-payment-failed", paymentHandler.ProcessRazorpayPaymentFailure).Methods("POST")
 	r.HandleFunc("/payment-failure", paymentHandler.RenderPaymentFailurePage).Methods("GET")
 	r.HandleFunc("/payment-success", paymentHandler.RenderPaymentSuccessPage).Methods("GET")
 
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+type Brand struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	IsDeleted bool       `json:"is_deleted"`
+}
@@ -6,6 +6,7 @@ type CartItem struct {
 	ID        int64     `json:"id"`
 	UserID    int64     `json:"user_id"`
 	ProductID int64     `json:"product_id"`
+	VariantID *int64    `json:"variant_id,omitempty"`
 	Quantity  int       `json:"quantity"`
 	Price     float64   `json:"price"`
 	Subtotal  float64   `json:"subtotal"`
@@ -14,8 +15,9 @@ type CartItem struct {
 }
 
 type AddToCartInput struct {
-	ProductID int64 `json:"product_id"`
-	Quantity  int   `json:"quantity"`
+	ProductID int64  `json:"product_id"`
+	VariantID *int64 `json:"variant_id,omitempty"`
+	Quantity  int    `json:"quantity"`
 }
 
 type CartItemWithProduct struct {
@@ -33,6 +35,7 @@ type UpdatedCartItemResponse struct {
 	ID        int64     `json:"id"`
 	UserID    int64     `json:"user_id"`
 	ProductID int64     `json:"product_id"`
+	VariantID *int64    `json:"variant_id,omitempty"`
 	Quantity  int       `json:"quantity"`
 	Price     float64   `json:"price"`
 	Subtotal  float64   `json:"subtotal"`
@@ -3,11 +3,40 @@ package domain
 import "time"
 
 type Category struct {
-	ID        int        `json:"id"`
-	Name      string     `json:"name"`
-	Slug      string     `json:"slug"`
+	ID int `json:"id"`
+	// ParentID is nil for a top level category
+	ParentID *int   `json:"parent_id,omitempty"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	// Path is the materialised path of ids from the top level category, like /1/4/9/
+	Path      string     `json:"path"`
+	Depth     int        `json:"depth"`
+	ImageURL  string     `json:"image_url"`
+	BannerURL string     `json:"banner_url"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty"` // * used to represent it as either a time stamp or null value
 	IsDeleted bool       `json:"is_deleted"`
+	// Children is filled only when the category tree is retrieved
+	Children []*Category `json:"children,omitempty"`
+}
+
+// PublicCategory is a category of the storefront navigation, ProductCount includes the products of the sub categories
+type PublicCategory struct {
+	ID           int               `json:"id"`
+	Name         string            `json:"name"`
+	Slug         string            `json:"slug"`
+	ImageURL     string            `json:"image_url"`
+	BannerURL    string            `json:"banner_url"`
+	ProductCount int               `json:"product_count"`
+	Children     []*PublicCategory `json:"children"`
+}
+
+// CategoryCascadeResult is the number of rows affected by the cascading delete or restore of a category
+type CategoryCascadeResult struct {
+	CategoryID           int   `json:"category_id"`
+	SubCategories        int64 `json:"sub_categories"`
+	Products             int64 `json:"products"`
+	CartItemsRemoved     int64 `json:"cart_items_removed,omitempty"`
+	WishlistItemsRemoved int64 `json:"wishlist_items_removed,omitempty"`
 }
@@ -16,6 +16,8 @@ type CheckoutSession struct {
 	CouponApplied     bool             `json:"coupon_applied"`
 	ShippingAddressID int64            `json:"shipping_address_id,omitempty"`
 	ShippingAddress   *ShippingAddress `json:"shipping_address,omitempty"`
+	// ReservedUntil is the time till the stock of the cart items is held for the checkout
+	ReservedUntil *time.Time `json:"reserved_until,omitempty"`
 }
 
 type CheckoutItem struct {
@@ -58,6 +60,7 @@ type CheckoutSummary struct {
 type CheckoutItemDetail struct {
 	ID        int64   `json:"id"`
 	ProductID int64   `json:"product_id"`
+	VariantID *int64  `json:"variant_id,omitempty"`
 	Name      string  `json:"name"`
 	Quantity  int     `json:"quantity"`
 	Price     float64 `json:"price"`
@@ -1,13 +1,25 @@
 package domain
 
+import "time"
+
 type InventoryItem struct {
-	ID            int64   `json:"id,omitempty"`
-	ProductID     int64   `json:"product_id"`
-	ProductName   string  `json:"product_name"`
-	CategoryID    int64   `json:"category_id"`
-	CategoryName  string  `json:"category_name"`
-	StockQuantity int     `json:"stock_quantity"`
-	Price         float64 `json:"price"`
+	ID            int64  `json:"id,omitempty"`
+	ProductID     int64  `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	VariantID     *int64 `json:"variant_id,omitempty"`
+	SKU           string `json:"sku,omitempty"`
+	Size          string `json:"size,omitempty"`
+	Color         string `json:"color,omitempty"`
+	CategoryID    int64  `json:"category_id"`
+	CategoryName  string `json:"category_name"`
+	StockQuantity int    `json:"stock_quantity"`
+	// ReservedQuantity is held for checkouts and unpaid orders, AvailableQuantity is the stock which can still be sold
+	ReservedQuantity  int     `json:"reserved_quantity"`
+	AvailableQuantity int     `json:"available_quantity"`
+	ReorderThreshold  *int    `json:"reorder_threshold,omitempty"`
+	Price             float64 `json:"price"`
+	// Locations is the stock of the item at each location, only set when the inventory is broken down by location
+	Locations []*LocationStock `json:"locations,omitempty"`
 }
 
 type InventoryQueryParams struct {
@@ -15,10 +27,37 @@ type InventoryQueryParams struct {
 	ProductName   string
 	CategoryID    int64
 	CategoryName  string
+	SKU           string
+	Size          string
+	Color         string
 	StockLessThan *int
 	StockMoreThan *int
-	Page          int
-	Limit         int
-	SortBy        string
-	SortOrder     string
+	// LocationID lists the items in stock at the location, Breakdown "location" adds the stock at each location
+	LocationID int64
+	Breakdown  string
+	Page       int
+	Limit      int
+	SortBy     string
+	SortOrder  string
+}
+
+// StockAlert is raised when the stock of a product falls to its reorder threshold, it's resolved once the stock is back above it
+type StockAlert struct {
+	ID                       int64      `json:"id"`
+	ProductID                int64      `json:"product_id"`
+	ProductName              string     `json:"product_name"`
+	ReorderThreshold         int        `json:"reorder_threshold"`
+	StockQuantity            int        `json:"stock_quantity"`
+	DailySalesVelocity       float64    `json:"daily_sales_velocity"`
+	SuggestedReorderQuantity int        `json:"suggested_reorder_quantity"`
+	Status                   string     `json:"status"`
+	EmailedAt                *time.Time `json:"emailed_at,omitempty"`
+	ResolvedAt               *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt                time.Time  `json:"created_at"`
+}
+
+type StockAlertQueryParams struct {
+	Status string
+	Page   int
+	Limit  int
 }
@@ -0,0 +1,79 @@
+package domain
+
+import "time"
+
+// InventoryLocation is a warehouse or store holding stock, locations with a lower priority are used first
+type InventoryLocation struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Code      string    `json:"code"`
+	Pincode   string    `json:"pincode"`
+	Priority  int       `json:"priority"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// StockUnits and ItemCount aggregate the stock held at the location
+	StockUnits int64 `json:"stock_units"`
+	ItemCount  int64 `json:"item_count"`
+}
+
+type InventoryLocationUpdate struct {
+	Name     *string `json:"name"`
+	Pincode  *string `json:"pincode"`
+	Priority *int    `json:"priority"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// LocationStock is the stock of a product, or of one of its variants when VariantID is set, at a location
+type LocationStock struct {
+	LocationID    int64     `json:"location_id"`
+	LocationCode  string    `json:"location_code"`
+	LocationName  string    `json:"location_name"`
+	Pincode       string    `json:"-"`
+	Priority      int       `json:"-"`
+	ProductID     int64     `json:"product_id"`
+	ProductName   string    `json:"product_name,omitempty"`
+	VariantID     *int64    `json:"variant_id,omitempty"`
+	SKU           string    `json:"sku,omitempty"`
+	StockQuantity int       `json:"stock_quantity"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type LocationStockQueryParams struct {
+	LocationID int64
+	ProductID  int64
+	Page       int
+	Limit      int
+}
+
+// OrderAllocation is the stock of an order item taken from a location
+type OrderAllocation struct {
+	ID         int64      `json:"id"`
+	OrderID    int64      `json:"order_id"`
+	LocationID int64      `json:"location_id"`
+	ProductID  int64      `json:"product_id"`
+	VariantID  *int64     `json:"variant_id,omitempty"`
+	Quantity   int        `json:"quantity"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
+
+type StockTransfer struct {
+	ID             int64     `json:"id"`
+	FromLocationID int64     `json:"from_location_id"`
+	ToLocationID   int64     `json:"to_location_id"`
+	ProductID      int64     `json:"product_id"`
+	VariantID      *int64    `json:"variant_id,omitempty"`
+	Quantity       int       `json:"quantity"`
+	Note           string    `json:"note,omitempty"`
+	CreatedBy      int64     `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type StockTransferQueryParams struct {
+	LocationID int64
+	ProductID  int64
+	Page       int
+	Limit      int
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+type Offer struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	DiscountType  string    `json:"discount_type"`
+	DiscountValue float64   `json:"discount_value"`
+	ProductID     *int64    `json:"product_id,omitempty"`
+	SubCategoryID *int      `json:"sub_category_id,omitempty"`
+	CategoryID    *int      `json:"category_id,omitempty"`
+	StartsAt      time.Time `json:"starts_at"`
+	EndsAt        time.Time `json:"ends_at"`
+	IsActive      bool      `json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type CreateOfferInput struct {
+	Name          string  `json:"name"`
+	DiscountType  string  `json:"discount_type"`
+	DiscountValue float64 `json:"discount_value"`
+	ProductID     *int64  `json:"product_id,omitempty"`
+	SubCategoryID *int    `json:"sub_category_id,omitempty"`
+	CategoryID    *int    `json:"category_id,omitempty"`
+	StartsAt      string  `json:"starts_at"`
+	EndsAt        string  `json:"ends_at"`
+}
+
+type OfferQueryParams struct {
+	Page       int
+	Limit      int
+	ActiveOnly bool
+}
@@ -42,6 +42,7 @@ type OrderItem struct {
 	ID          int64   `json:"id"`
 	OrderID     int64   `json:"order_id"`
 	ProductID   int64   `json:"product_id"`
+	VariantID   *int64  `json:"variant_id,omitempty"`
 	ProductName string  `json:"product_name"`
 	Quantity    int     `json:"quantity"`
 	Price       float64 `json:"price"`
@@ -12,11 +12,22 @@ type Product struct {
 	Price          float64    `json:"price"`
 	StockQuantity  int        `json:"stock_quantity"`
 	SubCategoryID  int        `json:"sub_category_id"`
+	BrandID        *int       `json:"brand_id,omitempty"`
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
 	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
 	PrimaryImageID *int64     `json:"primary_image_id,omitempty"`
 	IsDeleted      bool       `json:"is_deleted"`
+	AverageRating  float64    `json:"average_rating"`
+	RatingCount    int        `json:"rating_count"`
+	Status         string     `json:"status"`
+	// PublishAt is the time a scheduled product goes live
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// Price after the best running offer, same as Price when there is no offer
+	OfferPrice         float64 `json:"offer_price"`
+	DiscountPercentage float64 `json:"discount_percentage"`
+	// ThumbnailURL is the small rendition of the primary image, only set in the listings
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
 }
 
 type ProductQueryParams struct {
@@ -41,18 +52,59 @@ type ProductQueryParams struct {
 	UpdatedAfter  string
 	UpdatedBefore string
 	Categories    []string
+	Brands        []string
+	// Attributes filters on the filterable attributes, attribute slug to value
+	Attributes map[string]string
 }
 
 type PublicProduct struct {
-	ID              int64     `json:"id"`
-	Name            string    `json:"name"`
-	Slug            string    `json:"slug"`
-	Description     string    `json:"description"`
-	Price           float64   `json:"price"`
-	StockQuantity   int       `json:"stock_quantity"`
-	CategoryName    string    `json:"category_name"`
-	SubcategoryName string    `json:"subcategory_name"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	Images          []string  `json:"images"`
+	ID                 int64                    `json:"id"`
+	Name               string                   `json:"name"`
+	Slug               string                   `json:"slug"`
+	Description        string                   `json:"description"`
+	Price              float64                  `json:"price"`
+	OriginalPrice      float64                  `json:"original_price"`
+	OfferPrice         float64                  `json:"offer_price"`
+	DiscountPercentage float64                  `json:"discount_percentage"`
+	StockQuantity      int                      `json:"stock_quantity"`
+	CategoryName       string                   `json:"category_name"`
+	SubcategoryName    string                   `json:"subcategory_name"`
+	BrandID            *int                     `json:"brand_id,omitempty"`
+	BrandName          string                   `json:"brand_name,omitempty"`
+	BrandSlug          string                   `json:"brand_slug,omitempty"`
+	CreatedAt          time.Time                `json:"created_at"`
+	UpdatedAt          time.Time                `json:"updated_at"`
+	Images             []*PublicProductImage    `json:"images"`
+	AverageRating      float64                  `json:"average_rating"`
+	RatingCount        int                      `json:"rating_count"`
+	Variants           []*PublicProductVariant  `json:"variants,omitempty"`
+	Attributes         []*ProductAttributeValue `json:"attributes"`
+	Questions          *ProductQuestionPage     `json:"questions"`
+}
+
+// ProductFacets holds the product counts of each filter option for the current listing query
+type ProductFacets struct {
+	Categories    []FacetCount      `json:"categories"`
+	Subcategories []FacetCount      `json:"subcategories"`
+	Brands        []FacetCount      `json:"brands"`
+	PriceRanges   []PriceRangeCount `json:"price_ranges"`
+	Stock         StockFacetCount   `json:"stock"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// PriceRangeCount is the count of products priced from Min up to (not including) Max, Max is nil for the last range
+type PriceRangeCount struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int64    `json:"count"`
+}
+
+type StockFacetCount struct {
+	InStock    int64 `json:"in_stock"`
+	OutOfStock int64 `json:"out_of_stock"`
 }
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// ProductAttribute is a specification defined on a sub category, like fabric for jerseys.
+// It applies to the products of the sub category and of all the sub categories below it
+type ProductAttribute struct {
+	ID            int       `json:"id"`
+	SubCategoryID int       `json:"sub_category_id"`
+	Name          string    `json:"name"`
+	Slug          string    `json:"slug"`
+	Type          string    `json:"type"`
+	AllowedValues []string  `json:"allowed_values"`
+	IsFilterable  bool      `json:"is_filterable"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ProductAttributeValue struct {
+	AttributeID int    `json:"attribute_id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+}
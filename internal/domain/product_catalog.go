@@ -0,0 +1,25 @@
+package domain
+
+// ProductCatalogRow is a product as it appears in the import and export files
+type ProductCatalogRow struct {
+	Name            string
+	Description     string
+	Price           float64
+	StockQuantity   int
+	SubCategorySlug string
+	BrandSlug       string
+}
+
+type ProductImportResult struct {
+	TotalRows int                     `json:"total_rows"`
+	Created   int                     `json:"created"`
+	Updated   int                     `json:"updated"`
+	Failed    int                     `json:"failed"`
+	Errors    []ProductImportRowError `json:"errors"`
+}
+
+// ProductImportRowError reports why a row was not imported, Row is the line number in the file
+type ProductImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
@@ -3,9 +3,30 @@ package domain
 import "time"
 
 type ProductImage struct {
-	ID        int64     `json:"id"`
-	ProductID int64     `json:"product_id"`
-	ImageURL  string    `json:"image_url"`
+	ID        int64  `json:"id"`
+	ProductID int64  `json:"product_id"`
+	ImageURL  string `json:"image_url"`
+	// resized renditions, listings use the thumbnail
+	ThumbnailURL string `json:"thumbnail_url"`
+	MediumURL    string `json:"medium_url"`
+	AltText      string `json:"alt_text"`
+	// position in the gallery, the primary image comes first
+	Position  int       `json:"position"`
 	IsPrimary bool      `json:"is_primary"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// ProductImageOrder is an entry of the gallery re-sequencing request, alt text is kept when it's not given
+type ProductImageOrder struct {
+	ID      int64   `json:"id"`
+	AltText *string `json:"alt_text,omitempty"`
+}
+
+// PublicProductImage is a gallery image as shown in the product details
+type PublicProductImage struct {
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	MediumURL    string `json:"medium_url"`
+	AltText      string `json:"alt_text"`
+	Position     int    `json:"position"`
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// ProductPriceHistory records a change in the price of a product, or of one of its variants when VariantID is set
+type ProductPriceHistory struct {
+	ID        int64     `json:"id"`
+	ProductID int64     `json:"product_id"`
+	VariantID *int64    `json:"variant_id,omitempty"`
+	OldPrice  float64   `json:"old_price"`
+	NewPrice  float64   `json:"new_price"`
+	ChangedBy *int64    `json:"changed_by,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
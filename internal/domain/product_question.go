@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+type ProductQuestion struct {
+	ID        int64            `json:"id"`
+	ProductID int64            `json:"product_id"`
+	UserID    int64            `json:"user_id"`
+	UserName  string           `json:"user_name,omitempty"`
+	Question  string           `json:"question"`
+	Status    string           `json:"status"`
+	Answers   []*ProductAnswer `json:"answers"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// ProductAnswer is written either by an admin or by a user who bought the product
+type ProductAnswer struct {
+	ID              int64     `json:"id"`
+	QuestionID      int64     `json:"question_id"`
+	UserID          *int64    `json:"user_id,omitempty"`
+	AdminID         *int64    `json:"admin_id,omitempty"`
+	UserName        string    `json:"user_name,omitempty"`
+	Answer          string    `json:"answer"`
+	IsAdminAnswer   bool      `json:"is_admin_answer"`
+	IsVerifiedBuyer bool      `json:"is_verified_buyer"`
+	UpvoteCount     int       `json:"upvote_count"`
+	Status          string    `json:"status"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type QuestionQueryParams struct {
+	ProductID int64
+	Status    string
+	Page      int
+	Limit     int
+}
+
+// ProductQuestionPage is a page of the product questions along with the pagination details
+type ProductQuestionPage struct {
+	Questions  []*ProductQuestion `json:"questions"`
+	TotalCount int64              `json:"total_count"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalPages int64              `json:"total_pages"`
+}
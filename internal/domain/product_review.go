@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+type ProductReview struct {
+	ID        int64     `json:"id"`
+	ProductID int64     `json:"product_id"`
+	UserID    int64     `json:"user_id"`
+	UserName  string    `json:"user_name,omitempty"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ReviewQueryParams struct {
+	ProductID int64
+	Status    string
+	Page      int
+	Limit     int
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+type ProductVariant struct {
+	ID            int64   `json:"id"`
+	ProductID     int64   `json:"product_id"`
+	SKU           string  `json:"sku"`
+	Size          string  `json:"size,omitempty"`
+	Color         string  `json:"color,omitempty"`
+	Price         float64 `json:"price"`
+	StockQuantity int     `json:"stock_quantity"`
+	// ReservedQuantity is the stock held for checkouts and unpaid orders
+	ReservedQuantity int        `json:"reserved_quantity"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+	IsDeleted        bool       `json:"is_deleted"`
+}
+
+type PublicProductVariant struct {
+	ID            int64   `json:"id"`
+	SKU           string  `json:"sku"`
+	Size          string  `json:"size,omitempty"`
+	Color         string  `json:"color,omitempty"`
+	Price         float64 `json:"price"`
+	OfferPrice    float64 `json:"offer_price"`
+	StockQuantity int     `json:"stock_quantity"`
+}
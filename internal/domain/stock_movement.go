@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// StockChange describes why the stock is changed, it is recorded along with every stock movement.
+// LocationID is the location whose stock is changed, the primary location is used when it's nil
+type StockChange struct {
+	Reason        string `json:"reason"`
+	ReferenceType string `json:"reference_type"`
+	ReferenceID   *int64 `json:"reference_id,omitempty"`
+	ActorType     string `json:"actor_type"`
+	ActorID       *int64 `json:"actor_id,omitempty"`
+	LocationID    *int64 `json:"location_id,omitempty"`
+}
+
+// StockMovement is a change in the stock of a product, or of one of its variants when VariantID is set
+type StockMovement struct {
+	ID             int64  `json:"id"`
+	ProductID      int64  `json:"product_id"`
+	VariantID      *int64 `json:"variant_id,omitempty"`
+	QuantityChange int    `json:"quantity_change"`
+	StockAfter     int    `json:"stock_after"`
+	StockChange
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type StockMovementQueryParams struct {
+	ProductID int64
+	VariantID *int64
+	Page      int
+	Limit     int
+}
+
+// StockDiscrepancy is a product or variant whose stock differs from the stock recomputed from its movements
+type StockDiscrepancy struct {
+	ProductID      int64  `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	VariantID      *int64 `json:"variant_id,omitempty"`
+	SKU            string `json:"sku,omitempty"`
+	StockQuantity  int    `json:"stock_quantity"`
+	LedgerQuantity int    `json:"ledger_quantity"`
+	Difference     int    `json:"difference"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// StockReservation is the stock held for a checkout, it moves to the order when the order is placed
+type StockReservation struct {
+	ID         int64     `json:"id"`
+	CheckoutID int64     `json:"checkout_id"`
+	OrderID    *int64    `json:"order_id,omitempty"`
+	UserID     int64     `json:"user_id"`
+	ProductID  int64     `json:"product_id"`
+	VariantID  *int64    `json:"variant_id,omitempty"`
+	Quantity   int       `json:"quantity"`
+	Status     string    `json:"status"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,8 @@
+package domain
+
+// TrashPurgeResult is the summary of a trash purge
+type TrashPurgeResult struct {
+	PurgedProducts   int64 `json:"purged_products"`
+	PurgedCategories int64 `json:"purged_categories"`
+	RemovedImages    int   `json:"removed_images"`
+}
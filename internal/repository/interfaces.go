@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/pagination"
 )
 
 type UserRepository interface {
@@ -51,7 +52,23 @@ type CategoryRepository interface {
 	Create(ctx context.Context, category *domain.Category) error
 	GetByID(ctx context.Context, id int) (*domain.Category, error)
 	GetAll(ctx context.Context) ([]*domain.Category, error)
+	GetTree(ctx context.Context) ([]*domain.Category, error)
+	GetProductCounts(ctx context.Context) (map[int]int, error)
+	GetDeleted(ctx context.Context) ([]*domain.Category, error)
+	GetDeletedByID(ctx context.Context, id int) (*domain.Category, error)
+	IsActiveInTree(ctx context.Context, id int) (bool, error)
+	Restore(ctx context.Context, id int) (*domain.CategoryCascadeResult, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 	Update(ctx context.Context, category *domain.Category) error
+	SoftDelete(ctx context.Context, id int) (*domain.CategoryCascadeResult, error)
+}
+
+type BrandRepository interface {
+	Create(ctx context.Context, brand *domain.Brand) error
+	GetByID(ctx context.Context, id int) (*domain.Brand, error)
+	GetBySlug(ctx context.Context, slug string) (*domain.Brand, error)
+	GetAll(ctx context.Context) ([]*domain.Brand, error)
+	Update(ctx context.Context, brand *domain.Brand) error
 	SoftDelete(ctx context.Context, id int) error
 }
 
@@ -59,39 +76,59 @@ type SubCategoryRepository interface {
 	Create(ctx context.Context, subCategory *domain.SubCategory) error
 	GetByCategoryID(ctx context.Context, categoryID int) ([]*domain.SubCategory, error)
 	GetByID(ctx context.Context, id int) (*domain.SubCategory, error)
+	GetBySlug(ctx context.Context, slug string) (*domain.SubCategory, error)
 	Update(ctx context.Context, subCategory *domain.SubCategory) error
-	SoftDelete(ctx context.Context, id int) error
+	SoftDelete(ctx context.Context, id int) (*domain.CategoryCascadeResult, error)
 }
 
 type ProductRepository interface {
-	Create(ctx context.Context, product *domain.Product) error
+	Create(ctx context.Context, product *domain.Product, createdBy int64) error
 	SlugExists(ctx context.Context, slug string) (bool, error)
+	ResolveSlug(ctx context.Context, slug string) (int64, string, error)
+	GetDeleted(ctx context.Context) ([]*domain.Product, error)
+	GetDeletedByID(ctx context.Context, id int64) (*domain.Product, error)
+	Restore(ctx context.Context, id int64) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, []string, error)
 	NameExists(ctx context.Context, name string) (bool, error)
-	Update(ctx context.Context, product *domain.Product) error
+	NameExistsExceptSlug(ctx context.Context, name, slug string) (bool, error)
+	Update(ctx context.Context, product *domain.Product, changedBy int64) error
 	SoftDelete(ctx context.Context, id int64) error
 	GetByID(ctx context.Context, id int64) (*domain.Product, error)
 	NameExistsBeforeUpdate(ctx context.Context, name string, excludeID int64) (bool, error)
-	AddImage(ctx context.Context, productID int64, imageURL string, isPrimary bool) error
+	AddImage(ctx context.Context, image *domain.ProductImage) error
 	GetImageCount(ctx context.Context, productID int64) (int, error)
 	DeleteImage(ctx context.Context, productID int64, imageURL string) error
 	GetProductImages(ctx context.Context, productID int64) ([]*domain.ProductImage, error)
-	SetImageAsPrimary(ctx context.Context, productID int64, imageID int64) error
 	UpdateProductPrimaryImage(ctx context.Context, productID int64, imageID *int64) error
 	GetImageByURL(ctx context.Context, productID int64, imageURL string) (*domain.ProductImage, error)
 	GetPrimaryImage(ctx context.Context, productID int64) (*domain.ProductImage, error)
 	UpdateImagePrimary(ctx context.Context, imageID int64, isPrimary bool) error
 	GetImageByID(ctx context.Context, imageID int64) (*domain.ProductImage, error)
 	DeleteImageByID(ctx context.Context, imageID int64) error
+	ReorderImages(ctx context.Context, productID int64, order []domain.ProductImageOrder) error
 	GetAll(ctx context.Context) ([]*domain.Product, error)
-	UpdateStockQuantity(ctx context.Context, productID int64, quantity int) error
+	PublishScheduledProducts(ctx context.Context) (int64, error)
+	UpdateStockQuantity(ctx context.Context, productID int64, quantity int, changedBy int64) error
 	GetProducts(ctx context.Context, params domain.ProductQueryParams) ([]*domain.Product, int64, error)
+	GetProductsByCursor(ctx context.Context, params domain.ProductQueryParams, page pagination.Params) ([]*domain.Product, pagination.Page, error)
+	GetProductFacets(ctx context.Context, params domain.ProductQueryParams) (*domain.ProductFacets, error)
+	BulkUpsert(ctx context.Context, products []*domain.Product, changedBy int64) ([]bool, error)
+	GetPriceHistory(ctx context.Context, productID int64) ([]*domain.ProductPriceHistory, error)
+	GetCatalog(ctx context.Context) ([]*domain.ProductCatalogRow, error)
 	GetPublicProductByID(ctx context.Context, id int64) (*domain.PublicProduct, error)
-	UpdateStockTx(ctx context.Context, tx *sql.Tx, productID int64, quantity int) error
+	UpdateStockTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64, quantity int, change domain.StockChange) error
+	CreateVariant(ctx context.Context, variant *domain.ProductVariant, createdBy int64) error
+	GetVariantByID(ctx context.Context, variantID int64) (*domain.ProductVariant, error)
+	GetVariantsByProductID(ctx context.Context, productID int64) ([]*domain.ProductVariant, error)
+	UpdateVariant(ctx context.Context, variant *domain.ProductVariant, changedBy int64) error
+	SoftDeleteVariant(ctx context.Context, variantID int64, deletedBy int64) error
+	HasVariants(ctx context.Context, productID int64) (bool, error)
+	UpdateVariantStockQuantity(ctx context.Context, variantID int64, quantity int, changedBy int64) error
 }
 
 type CartRepository interface {
 	AddCartItem(ctx context.Context, item *domain.CartItem) error
-	GetCartItemByProductID(ctx context.Context, userID, productID int64) (*domain.CartItem, error)
+	GetCartItemByProductID(ctx context.Context, userID, productID int64, variantID *int64) (*domain.CartItem, error)
 	UpdateCartItem(ctx context.Context, item *domain.CartItem) error
 	GetCartByUserID(ctx context.Context, userID int64) ([]*domain.CartItem, error)
 	UpdateCartItemQuantity(ctx context.Context, cartItem *domain.CartItem) error
@@ -127,8 +164,10 @@ type CheckoutRepository interface {
 type OrderRepository interface {
 	GetByID(ctx context.Context, id int64) (*domain.Order, error)
 	GetUserOrders(ctx context.Context, userID int64, page int) ([]*domain.Order, int64, error)
+	GetUserOrdersByCursor(ctx context.Context, userID int64, params pagination.Params) ([]*domain.Order, pagination.Page, error)
 	UpdateOrderStatus(ctx context.Context, orderID int64, status string) error
 	GetOrders(ctx context.Context, limit, offset int) ([]*domain.Order, int64, error)
+	GetOrdersByCursor(ctx context.Context, params pagination.Params) ([]*domain.Order, pagination.Page, error)
 	GetPaymentByOrderID(ctx context.Context, orderID int64) (*domain.Payment, error)
 	UpdatePayment(ctx context.Context, payment *domain.Payment) error
 	UpdateOrderStatusTx(ctx context.Context, tx *sql.Tx, orderID int64, status string) error
@@ -142,6 +181,7 @@ type OrderRepository interface {
 	UpdateOrderHasReturnRequest(ctx context.Context, orderID int64, hasReturnRequest bool) error
 	UpdateOrderDeliveryStatus(ctx context.Context, tx *sql.Tx, orderID int64, deliveryStatus, orderStatus string, deliveredAt *time.Time) error
 	IsOrderDelivered(ctx context.Context, orderID int64) (bool, error)
+	HasDeliveredOrderForProduct(ctx context.Context, userID, productID int64) (bool, error)
 	GetOrderByID(ctx context.Context, id int64) (*domain.Order, error)
 	GetByIDTx(ctx context.Context, tx *sql.Tx, id int64) (*domain.Order, error)
 	GetOrderItemsTx(ctx context.Context, tx *sql.Tx, orderID int64) ([]*domain.OrderItem, error)
@@ -157,6 +197,32 @@ type OrderRepository interface {
 
 type InventoryRepository interface {
 	GetInventory(ctx context.Context, params domain.InventoryQueryParams) ([]*domain.InventoryItem, int64, error)
+	GetStockMovements(ctx context.Context, params domain.StockMovementQueryParams) ([]*domain.StockMovement, int64, error)
+	GetStockDiscrepancies(ctx context.Context, productID int64) ([]*domain.StockDiscrepancy, error)
+	SetReorderThreshold(ctx context.Context, productID int64, threshold *int) error
+	ResolveStockAlerts(ctx context.Context) (int64, error)
+	CreateStockAlerts(ctx context.Context, windowDays, coverDays int) (int64, error)
+	GetUnsentStockAlerts(ctx context.Context) ([]*domain.StockAlert, error)
+	MarkStockAlertsEmailed(ctx context.Context, alertIDs []int64) error
+	GetStockAlerts(ctx context.Context, params domain.StockAlertQueryParams) ([]*domain.StockAlert, int64, error)
+}
+
+type LocationRepository interface {
+	BeginTx(ctx context.Context) (*sql.Tx, error)
+	Create(ctx context.Context, location *domain.InventoryLocation) error
+	GetByID(ctx context.Context, id int64) (*domain.InventoryLocation, error)
+	GetAll(ctx context.Context) ([]*domain.InventoryLocation, error)
+	Update(ctx context.Context, location *domain.InventoryLocation) error
+	HasStock(ctx context.Context, locationID int64) (bool, error)
+	CountActive(ctx context.Context) (int64, error)
+	GetLocationStock(ctx context.Context, params domain.LocationStockQueryParams) ([]*domain.LocationStock, int64, error)
+	LockItemStockTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64) (string, error)
+	GetItemStockTx(ctx context.Context, tx *sql.Tx, locationID, productID int64, variantID *int64) (int, error)
+	GetAllocatableStockTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64) ([]*domain.LocationStock, error)
+	CreateAllocationTx(ctx context.Context, tx *sql.Tx, allocation *domain.OrderAllocation) error
+	ReleaseAllocationsTx(ctx context.Context, tx *sql.Tx, orderID int64) ([]*domain.OrderAllocation, error)
+	CreateTransferTx(ctx context.Context, tx *sql.Tx, transfer *domain.StockTransfer) error
+	GetTransfers(ctx context.Context, params domain.StockTransferQueryParams) ([]*domain.StockTransfer, int64, error)
 }
 
 type WishlistRepository interface {
@@ -172,6 +238,7 @@ type WalletRepository interface {
 	AddBalance(ctx context.Context, tx *sql.Tx, userID int64, amount float64) error
 	CreateTransaction(ctx context.Context, tx *sql.Tx, transaction *domain.WalletTransaction) error
 	GetTransactions(ctx context.Context, userID int64, page, limit int, sort, order, transactionType string) ([]*domain.WalletTransaction, int64, error)
+	GetTransactionsByCursor(ctx context.Context, userID int64, transactionType string, params pagination.Params) ([]*domain.WalletTransaction, pagination.Page, error)
 	CreateTransactionTx(ctx context.Context, tx *sql.Tx, transaction *domain.WalletTransaction) error
 	UpdateBalanceTx(ctx context.Context, tx *sql.Tx, userID int64, amount float64) error
 	GetWalletTx(ctx context.Context, tx *sql.Tx, userID int64) (*domain.Wallet, error)
@@ -211,5 +278,65 @@ type PaymentRepository interface {
 	GetByRazorpayOrderID(ctx context.Context, razorpayOrderID string) (*domain.Payment, error)
 	UpdatePayment(ctx context.Context, payment *domain.Payment) error
 	GetByOrderIDTx(ctx context.Context, tx *sql.Tx, orderID int64) (*domain.Payment, error)
+	GetByIDForUpdateTx(ctx context.Context, tx *sql.Tx, paymentID int64) (*domain.Payment, error)
 	UpdateStatusTx(ctx context.Context, tx *sql.Tx, paymentID int64, status string) error
+	UpdatePaymentTx(ctx context.Context, tx *sql.Tx, payment *domain.Payment) error
+}
+
+type StockReservationRepository interface {
+	Reserve(ctx context.Context, checkoutID, userID int64, items []*domain.CartItem, expiresAt time.Time) error
+	GetReservedQuantity(ctx context.Context, productID int64, variantID *int64, excludeCheckoutID int64) (int, error)
+	ReserveForOrderTx(ctx context.Context, tx *sql.Tx, checkoutID, orderID, userID int64, items []*domain.CartItem, expiresAt time.Time) error
+	LockForOrderTx(ctx context.Context, tx *sql.Tx, orderID int64) ([]*domain.StockReservation, error)
+	CommitForOrderTx(ctx context.Context, tx *sql.Tx, orderID int64) error
+	RenewForOrderTx(ctx context.Context, tx *sql.Tx, orderID int64, expiresAt time.Time) error
+	IsStockCommittedTx(ctx context.Context, tx *sql.Tx, orderID int64) (bool, error)
+	ReleaseForOrderTx(ctx context.Context, tx *sql.Tx, orderID int64) error
+	ExpireReservations(ctx context.Context) (int64, error)
+}
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review *domain.ProductReview) error
+	GetByID(ctx context.Context, reviewID int64) (*domain.ProductReview, error)
+	ExistsForUserProduct(ctx context.Context, userID, productID int64) (bool, error)
+	GetReviews(ctx context.Context, params domain.ReviewQueryParams) ([]*domain.ProductReview, int64, error)
+	UpdateStatus(ctx context.Context, reviewID int64, status string) error
+}
+
+type QuestionRepository interface {
+	CreateQuestion(ctx context.Context, question *domain.ProductQuestion) error
+	GetQuestionByID(ctx context.Context, questionID int64) (*domain.ProductQuestion, error)
+	GetQuestions(ctx context.Context, params domain.QuestionQueryParams) ([]*domain.ProductQuestion, int64, error)
+	UpdateQuestionStatus(ctx context.Context, questionID int64, status string) error
+	CreateAnswer(ctx context.Context, answer *domain.ProductAnswer) error
+	GetAnswerByID(ctx context.Context, answerID int64) (*domain.ProductAnswer, error)
+	GetAnswersByQuestionIDs(ctx context.Context, questionIDs []int64, status string) ([]*domain.ProductAnswer, error)
+	UpdateAnswerStatus(ctx context.Context, answerID int64, status string) error
+	AddAnswerUpvote(ctx context.Context, answerID, userID int64) (int, error)
+	RemoveAnswerUpvote(ctx context.Context, answerID, userID int64) (int, error)
+}
+
+type ProductAttributeRepository interface {
+	Create(ctx context.Context, attribute *domain.ProductAttribute) error
+	GetByID(ctx context.Context, id int) (*domain.ProductAttribute, error)
+	GetForSubCategory(ctx context.Context, subCategoryID int) ([]*domain.ProductAttribute, error)
+	SlugExistsInTree(ctx context.Context, subCategoryID int, slug string, excludeID int) (bool, error)
+	Update(ctx context.Context, attribute *domain.ProductAttribute) error
+	Delete(ctx context.Context, id int) error
+	SetProductValues(ctx context.Context, productID int64, values map[int]string) error
+	GetProductValues(ctx context.Context, productID int64) ([]*domain.ProductAttributeValue, error)
+}
+
+type RecommendationRepository interface {
+	RefreshProductAffinities(ctx context.Context) (int64, error)
+	GetRelatedProducts(ctx context.Context, productID int64, limit int) ([]*domain.Product, error)
+	GetCartRecommendations(ctx context.Context, userID int64, limit int) ([]*domain.Product, error)
+}
+
+type OfferRepository interface {
+	Create(ctx context.Context, offer *domain.Offer) error
+	GetByID(ctx context.Context, offerID int64) (*domain.Offer, error)
+	GetAll(ctx context.Context, params domain.OfferQueryParams) ([]*domain.Offer, int64, error)
+	Deactivate(ctx context.Context, offerID int64) error
+	GetActiveOffersForProduct(ctx context.Context, productID int64) ([]*domain.Offer, error)
 }
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type brandRepository struct {
+	db *sql.DB
+}
+
+func NewBrandRepository(db *sql.DB) *brandRepository {
+	return &brandRepository{db: db}
+}
+
+// Create inserts a new brand into the database
+// If a brand with the same name or slug already exists, it returns a duplicate brand error
+func (r *brandRepository) Create(ctx context.Context, brand *domain.Brand) error {
+	query := `INSERT INTO brands (name, slug, created_at, updated_at, is_deleted)
+              VALUES ($1, $2, $3, $4, $5)
+              RETURNING id`
+
+	err := r.db.QueryRowContext(ctx, query,
+		brand.Name, brand.Slug, brand.CreatedAt, brand.UpdatedAt, false).Scan(&brand.ID)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Code == "23505" {
+			return utils.ErrDuplicateBrand
+		}
+		log.Printf("error while creating brand : %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetByID retrieves a brand which is not soft deleted
+func (r *brandRepository) GetByID(ctx context.Context, id int) (*domain.Brand, error) {
+	query := `SELECT id, name, slug, created_at, updated_at, deleted_at, is_deleted
+				FROM brands WHERE id = $1 AND is_deleted = FALSE`
+
+	var brand domain.Brand
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&brand.ID,
+		&brand.Name,
+		&brand.Slug,
+		&brand.CreatedAt,
+		&brand.UpdatedAt,
+		&brand.DeletedAt,
+		&brand.IsDeleted,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrBrandNotFound
+		}
+		log.Printf("error while retrieving brand using id : %v", err)
+		return nil, err
+	}
+
+	return &brand, nil
+}
+
+func (r *brandRepository) GetBySlug(ctx context.Context, slug string) (*domain.Brand, error) {
+	query := `SELECT id, name, slug, created_at, updated_at, deleted_at, is_deleted
+				FROM brands WHERE slug = $1 AND is_deleted = FALSE`
+
+	var brand domain.Brand
+	err := r.db.QueryRowContext(ctx, query, slug).Scan(
+		&brand.ID,
+		&brand.Name,
+		&brand.Slug,
+		&brand.CreatedAt,
+		&brand.UpdatedAt,
+		&brand.DeletedAt,
+		&brand.IsDeleted,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrBrandNotFound
+		}
+		log.Printf("error while retrieving brand using slug : %v", err)
+		return nil, err
+	}
+
+	return &brand, nil
+}
+
+// GetAll retrieves all brands that are not soft deleted
+func (r *brandRepository) GetAll(ctx context.Context) ([]*domain.Brand, error) {
+	query := `SELECT id, name, slug, created_at, updated_at, deleted_at, is_deleted
+				FROM brands
+				WHERE is_deleted = FALSE
+				ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("error while retrieving brands : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var brands []*domain.Brand
+	for rows.Next() {
+		var brand domain.Brand
+		err := rows.Scan(
+			&brand.ID,
+			&brand.Name,
+			&brand.Slug,
+			&brand.CreatedAt,
+			&brand.UpdatedAt,
+			&brand.DeletedAt,
+			&brand.IsDeleted,
+		)
+		if err != nil {
+			log.Printf("error while scanning brand row : %v", err)
+			return nil, err
+		}
+		brands = append(brands, &brand)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("error encountered during brand rows iteration : %v", err)
+		return nil, err
+	}
+	return brands, nil
+}
+
+// Update modifies the name and slug of an existing brand
+func (r *brandRepository) Update(ctx context.Context, brand *domain.Brand) error {
+	query := `UPDATE brands
+				SET name = $1, slug = $2, updated_at = $3
+				WHERE id = $4 AND is_deleted = FALSE`
+
+	result, err := r.db.ExecContext(ctx, query, brand.Name, brand.Slug, brand.UpdatedAt, brand.ID)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Code == "23505" {
+			return utils.ErrDuplicateBrand
+		}
+		log.Printf("error while updating brand : %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("error getting rows affected : %v", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return utils.ErrBrandNotFound
+	}
+
+	return nil
+}
+
+// SoftDelete marks a brand as deleted, products of the brand are kept as they are
+func (r *brandRepository) SoftDelete(ctx context.Context, id int) error {
+	query := `UPDATE brands
+				SET deleted_at = $1, is_deleted = TRUE
+				WHERE id = $2 AND is_deleted = FALSE`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC(), id)
+	if err != nil {
+		log.Printf("error executing brand soft delete query : %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("error getting rows affected : %v", err)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return utils.ErrBrandNotFound
+	}
+
+	return nil
+}
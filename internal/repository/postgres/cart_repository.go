@@ -20,18 +20,19 @@ func NewCartRepository(db *sql.DB) *cartRepository {
 /*
 AddCartItem:
 - Add cart item details to cart_items table
-- user_id, product_id, quantity, created_at, updated_at
+- user_id, product_id, variant_id, quantity, created_at, updated_at
 */
 func (r *cartRepository) AddCartItem(ctx context.Context, item *domain.CartItem) error {
 	query := `
-		INSERT INTO cart_items (user_id, product_id, quantity,price, subtotal, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO cart_items (user_id, product_id, variant_id, quantity,price, subtotal, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`
 
 	err := r.db.QueryRowContext(ctx, query,
 		item.UserID,
 		item.ProductID,
+		item.VariantID,
 		item.Quantity,
 		item.Price,
 		item.Subtotal,
@@ -45,19 +46,21 @@ func (r *cartRepository) AddCartItem(ctx context.Context, item *domain.CartItem)
 
 /*
 GetCartItemByProductID:
-- Get cart item details from cart_items table using user id and product id
+- Get cart item details from cart_items table using user id, product id and variant id
+- variant id is nil for products without variants
 */
-func (r *cartRepository) GetCartItemByProductID(ctx context.Context, userID, productID int64) (*domain.CartItem, error) {
+func (r *cartRepository) GetCartItemByProductID(ctx context.Context, userID, productID int64, variantID *int64) (*domain.CartItem, error) {
 	query := `
-		SELECT id, user_id, product_id, quantity, created_at, updated_at
+		SELECT id, user_id, product_id, variant_id, quantity, created_at, updated_at
 		FROM cart_items
-		WHERE user_id = $1 AND product_id = $2
+		WHERE user_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3
 	`
 	var item domain.CartItem
-	err := r.db.QueryRowContext(ctx, query, userID, productID).Scan(
+	err := r.db.QueryRowContext(ctx, query, userID, productID, variantID).Scan(
 		&item.ID,
 		&item.UserID,
 		&item.ProductID,
+		&item.VariantID,
 		&item.Quantity,
 		&item.CreatedAt,
 		&item.UpdatedAt,
@@ -98,7 +101,7 @@ func (r *cartRepository) UpdateCartItem(ctx context.Context, item *domain.CartIt
 
 func (r *cartRepository) GetCartByUserID(ctx context.Context, userID int64) ([]*domain.CartItem, error) {
 	query := `
-		SELECT id, user_id, product_id, quantity, created_at, updated_at, price, subtotal
+		SELECT id, user_id, product_id, variant_id, quantity, created_at, updated_at, price, subtotal
 		FROM cart_items
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -117,6 +120,7 @@ func (r *cartRepository) GetCartByUserID(ctx context.Context, userID int64) ([]*
 			&ci.ID,
 			&ci.UserID,
 			&ci.ProductID,
+			&ci.VariantID,
 			&ci.Quantity,
 			&ci.CreatedAt,
 			&ci.UpdatedAt,
@@ -192,7 +196,7 @@ func (r *cartRepository) DeleteCartItem(ctx context.Context, itemID int64) error
 // - Retrieve cart item details from cart_items table
 func (r *cartRepository) GetCartItemByID(ctx context.Context, itemID int64) (*domain.CartItem, error) {
 	query := `
-        SELECT id, user_id, product_id, quantity, created_at, updated_at, price, subtotal
+        SELECT id, user_id, product_id, variant_id, quantity, created_at, updated_at, price, subtotal
         FROM cart_items
         WHERE id = $1
     `
@@ -201,6 +205,7 @@ func (r *cartRepository) GetCartItemByID(ctx context.Context, itemID int64) (*do
 		&item.ID,
 		&item.UserID,
 		&item.ProductID,
+		&item.VariantID,
 		&item.Quantity,
 		&item.CreatedAt,
 		&item.UpdatedAt,
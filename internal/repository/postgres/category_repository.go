@@ -19,19 +19,11 @@ func NewCategoryRepository(db *sql.DB) *categoryRepository {
 	return &categoryRepository{db: db}
 }
 
-// Create inserts a new category into the database
+// Create inserts a new category into the database, under its parent category if one is given
 // It accepts a context for request scoping and a Category struct containing the category details
-// If a category with the same name or slug already exists, it returns a duplicate category error
+// If a category with the same name under the same parent or the same slug already exists, it returns a duplicate category error
 func (r *categoryRepository) Create(ctx context.Context, category *domain.Category) error {
-
-	// SQL query to insert a new category and return the new category's ID
-	query := `INSERT INTO categories (name, slug, created_at,updated_at, is_deleted) 
-              VALUES ($1, $2, $3, $4, $5) 
-              RETURNING id`
-
-	// Execute the query with the provided category details and scan the returned ID into the category struct
-	err := r.db.QueryRowContext(ctx, query,
-		category.Name, category.Slug, category.CreatedAt, category.UpdatedAt, false).Scan(&category.ID)
+	err := insertCategory(ctx, r.db, category)
 	if err != nil {
 		// Type assert the error to a PostgreSQL error to handle specific database errors
 		pqErr, ok := err.(*pq.Error)
@@ -44,17 +36,41 @@ func (r *categoryRepository) Create(ctx context.Context, category *domain.Catego
 	return nil
 }
 
-// GetByID retrieves a category from the database by its ID
+/*
+insertCategory:
+- The id is taken from the sequence first, so that the path of the category can be built in the same statement
+- Path of the category is the path of its parent followed by its own id, a top level category has the path /id/
+*/
+func insertCategory(ctx context.Context, db *sql.DB, category *domain.Category) error {
+	query := `
+		INSERT INTO categories (id, parent_id, name, slug, path, depth, image_url, banner_url, created_at, updated_at, is_deleted)
+		SELECT n.id, $1, $2, $3, COALESCE(pc.path, '/') || n.id || '/', COALESCE(pc.depth + 1, 0), $4, $5, $6, $7, false
+		FROM (SELECT nextval('categories_id_seq') AS id) n
+		LEFT JOIN categories pc ON pc.id = $1
+		RETURNING id, path, depth
+	`
+
+	return db.QueryRowContext(ctx, query,
+		category.ParentID, category.Name, category.Slug, category.ImageURL, category.BannerURL, category.CreatedAt, category.UpdatedAt,
+	).Scan(&category.ID, &category.Path, &category.Depth)
+}
+
+// GetByID retrieves a category from the database by its ID, the category can be at any level of the tree
 // It returns a pointer to the Category struct if found, or an error if the category does not exist, soft deleted or an issue occurs during the query
 func (r *categoryRepository) GetByID(ctx context.Context, id int) (*domain.Category, error) {
-	query := `SELECT id, name, slug, created_at, updated_at, deleted_at , is_deleted
+	query := `SELECT id, parent_id, name, slug, path, depth, image_url, banner_url, created_at, updated_at, deleted_at , is_deleted
 				FROM categories WHERE id = $1 AND is_deleted = FALSE`
 
 	var category domain.Category
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&category.ID,
+		&category.ParentID,
 		&category.Name,
 		&category.Slug,
+		&category.Path,
+		&category.Depth,
+		&category.ImageURL,
+		&category.BannerURL,
 		&category.CreatedAt,
 		&category.UpdatedAt,
 		&category.DeletedAt,
@@ -72,15 +88,67 @@ func (r *categoryRepository) GetByID(ctx context.Context, id int) (*domain.Categ
 	return &category, nil
 }
 
-// GetAll retrieves all categories from the database that are not soft-deleted (deleted_at IS NULL)
+// GetAll retrieves all top level categories from the database that are not soft-deleted (deleted_at IS NULL)
 // It returns a slice of pointers to Category structs, or an error if the query fails or issues occur during row iteration
 func (r *categoryRepository) GetAll(ctx context.Context) ([]*domain.Category, error) {
-	query := `SELECT id, name, slug, created_at, deleted_at,updated_at, is_deleted
+	query := `SELECT id, parent_id, name, slug, path, depth, image_url, banner_url, created_at, deleted_at,updated_at, is_deleted
 				FROM categories 
-				WHERE is_deleted = FALSE
+				WHERE is_deleted = FALSE AND parent_id IS NULL
 				ORDER BY id`
 
+	return r.queryCategories(ctx, query)
+}
+
+// GetTree retrieves all the categories that are not soft-deleted, a parent category always comes before its children
+func (r *categoryRepository) GetTree(ctx context.Context) ([]*domain.Category, error) {
+	query := `SELECT id, parent_id, name, slug, path, depth, image_url, banner_url, created_at, deleted_at, updated_at, is_deleted
+				FROM categories
+				WHERE is_deleted = FALSE
+				ORDER BY depth, name`
+
+	return r.queryCategories(ctx, query)
+}
+
+/*
+GetProductCounts:
+- Count the listed products of each category that is not soft-deleted
+- Products of the sub categories are counted in all of their ancestors
+- Products of a soft-deleted sub category are not counted
+*/
+func (r *categoryRepository) GetProductCounts(ctx context.Context) (map[int]int, error) {
+	query := `
+		SELECT c.id, COUNT(p.id)
+		FROM categories c
+		JOIN categories dc ON dc.path LIKE c.path || '%' AND dc.is_deleted = FALSE
+		LEFT JOIN products p ON p.sub_category_id = dc.id AND p.is_deleted = FALSE AND p.status = 'active'
+		WHERE c.is_deleted = FALSE
+		GROUP BY c.id
+	`
 	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("error while counting products of the categories : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var categoryID, count int
+		if err := rows.Scan(&categoryID, &count); err != nil {
+			log.Printf("error while scanning category product count : %v", err)
+			return nil, err
+		}
+		counts[categoryID] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *categoryRepository) queryCategories(ctx context.Context, query string, args ...interface{}) ([]*domain.Category, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Printf("error while retrieving category details : %v", err)
 		return nil, err
@@ -92,8 +160,13 @@ func (r *categoryRepository) GetAll(ctx context.Context) ([]*domain.Category, er
 		var category domain.Category
 		err := rows.Scan(
 			&category.ID,
+			&category.ParentID,
 			&category.Name,
 			&category.Slug,
+			&category.Path,
+			&category.Depth,
+			&category.ImageURL,
+			&category.BannerURL,
 			&category.CreatedAt,
 			&category.DeletedAt,
 			&category.UpdatedAt,
@@ -117,10 +190,10 @@ func (r *categoryRepository) GetAll(ctx context.Context) ([]*domain.Category, er
 // It returns an error if the update fails, the category is not found, or a unique constraint is violated
 func (r *categoryRepository) Update(ctx context.Context, category *domain.Category) error {
 	query := `UPDATE categories 
-				SET name = $1, slug = $2, updated_at = NOW() 
-				WHERE id = $3 AND is_deleted = FALSE`
+				SET name = $1, slug = $2, image_url = $3, banner_url = $4, updated_at = NOW() 
+				WHERE id = $5 AND is_deleted = FALSE`
 
-	result, err := r.db.ExecContext(ctx, query, category.Name, category.Slug, category.ID)
+	result, err := r.db.ExecContext(ctx, query, category.Name, category.Slug, category.ImageURL, category.BannerURL, category.ID)
 	if err != nil {
 		pqErr, ok := err.(*pq.Error)
 		if ok {
@@ -146,29 +219,253 @@ func (r *categoryRepository) Update(ctx context.Context, category *domain.Catego
 	return nil
 }
 
-// SoftDelete marks a category as deleted by setting the deleted_at timestamp
-// It returns an error if the operation fails or the category does not exist or is already deleted
-func (r *categoryRepository) SoftDelete(ctx context.Context, id int) error {
-	query := `UPDATE categories 
-				SET deleted_at = $1, is_deleted = TRUE 
-				WHERE id = $2 AND is_deleted = FALSE`
+/*
+SoftDelete:
+- Mark the category as deleted, the categories under it and their products are deleted along with it in one transaction
+- Returns the number of rows deleted by the cascade
+- It returns an error if the category does not exist or is already deleted
+*/
+func (r *categoryRepository) SoftDelete(ctx context.Context, id int) (*domain.CategoryCascadeResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("error while starting transaction : %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.ExecContext(ctx, query, time.Now().UTC(), id)
+	deletedAt := time.Now().UTC()
+	var path string
+	query := `UPDATE categories 
+				SET deleted_at = $1, is_deleted = TRUE, deleted_by_category_id = NULL
+				WHERE id = $2 AND is_deleted = FALSE
+				RETURNING path`
+	err = tx.QueryRowContext(ctx, query, deletedAt, id).Scan(&path)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Printf("No rows affected, category not found or already deleted")
+			return nil, utils.ErrCategoryNotFound
+		}
 		log.Printf("Error executing soft delete query: %v", err)
-		return err
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	result, err := cascadeCategorySoftDeleteTx(ctx, tx, id, path, deletedAt)
 	if err != nil {
-		log.Printf("Error getting rows affected: %v", err)
-		return err
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("error while committing transaction : %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+/*
+cascadeCategorySoftDeleteTx:
+- Soft delete the active categories under the given category and the active products of all of them
+- Rows are marked with the category id, so that restoring the category brings back exactly these rows
+- Deleted products are removed from the carts and wishlists of the users
+*/
+func cascadeCategorySoftDeleteTx(ctx context.Context, tx *sql.Tx, id int, path string, deletedAt time.Time) (*domain.CategoryCascadeResult, error) {
+	result := &domain.CategoryCascadeResult{CategoryID: id}
+
+	query := `UPDATE categories
+				SET deleted_at = $1, is_deleted = TRUE, deleted_by_category_id = $2
+				WHERE path LIKE $3 || '%' AND id <> $2 AND is_deleted = FALSE`
+	res, err := tx.ExecContext(ctx, query, deletedAt, id, path)
+	if err != nil {
+		log.Printf("error while soft deleting sub categories : %v", err)
+		return nil, err
+	}
+	if result.SubCategories, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	query = `UPDATE products
+				SET deleted_at = $1, is_deleted = TRUE, deleted_by_category_id = $2
+				WHERE is_deleted = FALSE
+				  AND sub_category_id IN (SELECT id FROM categories WHERE path LIKE $3 || '%')`
+	res, err = tx.ExecContext(ctx, query, deletedAt, id, path)
+	if err != nil {
+		log.Printf("error while soft deleting products of the category : %v", err)
+		return nil, err
+	}
+	if result.Products, err = res.RowsAffected(); err != nil {
+		return nil, err
 	}
 
+	cascadedProducts := `SELECT id FROM products WHERE deleted_by_category_id = $1 AND is_deleted = TRUE`
+
+	res, err = tx.ExecContext(ctx, `DELETE FROM cart_items WHERE product_id IN (`+cascadedProducts+`)`, id)
+	if err != nil {
+		log.Printf("error while removing deleted products from carts : %v", err)
+		return nil, err
+	}
+	if result.CartItemsRemoved, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	res, err = tx.ExecContext(ctx, `DELETE FROM wishlist_items WHERE product_id IN (`+cascadedProducts+`)`, id)
+	if err != nil {
+		log.Printf("error while removing deleted products from wishlists : %v", err)
+		return nil, err
+	}
+	if result.WishlistItemsRemoved, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetDeleted retrieves the soft deleted categories of any level, most recently deleted first
+func (r *categoryRepository) GetDeleted(ctx context.Context) ([]*domain.Category, error) {
+	query := `SELECT id, parent_id, name, slug, path, depth, image_url, banner_url, created_at, deleted_at, updated_at, is_deleted
+				FROM categories
+				WHERE is_deleted = TRUE
+				ORDER BY deleted_at DESC, id DESC`
+
+	categories, err := r.queryCategories(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if categories == nil {
+		categories = []*domain.Category{}
+	}
+	return categories, nil
+}
+
+// GetDeletedByID retrieves a soft deleted category, a category which is not deleted is not found
+func (r *categoryRepository) GetDeletedByID(ctx context.Context, id int) (*domain.Category, error) {
+	query := `SELECT id, parent_id, name, slug, path, depth, image_url, banner_url, created_at, deleted_at, updated_at, is_deleted
+				FROM categories
+				WHERE id = $1 AND is_deleted = TRUE`
+
+	categories, err := r.queryCategories(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(categories) == 0 {
+		return nil, utils.ErrCategoryNotFound
+	}
+	return categories[0], nil
+}
+
+// IsActiveInTree reports whether the category and all of its ancestors are not soft deleted
+func (r *categoryRepository) IsActiveInTree(ctx context.Context, id int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM categories WHERE id = $1)
+				AND NOT EXISTS (
+					SELECT 1 FROM categories c
+					JOIN categories a ON c.path LIKE a.path || '%'
+					WHERE c.id = $1 AND a.is_deleted = TRUE)`
+
+	var active bool
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&active)
+	if err != nil {
+		log.Printf("error while checking if the category is active in the tree : %v", err)
+		return false, err
+	}
+	return active, nil
+}
+
+/*
+Restore:
+- Bring a soft deleted category back along with the categories and products deleted by its cascade
+- Rows deleted on their own before the cascade stay in the trash, so are the rows under them
+*/
+func (r *categoryRepository) Restore(ctx context.Context, id int) (*domain.CategoryCascadeResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("error while starting transaction : %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE categories 
+				SET deleted_at = NULL, is_deleted = FALSE, deleted_by_category_id = NULL, updated_at = NOW() 
+				WHERE id = $1 AND is_deleted = TRUE`
+
+	res, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("Error executing restore query: %v", err)
+		return nil, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting rows affected: %v", err)
+		return nil, err
+	}
 	if rowsAffected == 0 {
-		log.Printf("No rows affected, category not found or already deleted")
-		return utils.ErrCategoryNotFound
+		return nil, utils.ErrCategoryNotFound
 	}
 
-	return nil
+	result := &domain.CategoryCascadeResult{CategoryID: id}
+
+	// the statement sees the categories as they were before it, so the ones restored by it are excluded from the check
+	query = `UPDATE categories c
+				SET deleted_at = NULL, is_deleted = FALSE, deleted_by_category_id = NULL, updated_at = NOW()
+				WHERE c.deleted_by_category_id = $1
+				  AND NOT EXISTS (
+					SELECT 1 FROM categories a
+					WHERE c.path LIKE a.path || '%' AND a.id <> c.id AND a.is_deleted = TRUE
+					  AND a.deleted_by_category_id IS DISTINCT FROM $1)`
+	res, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("error while restoring sub categories : %v", err)
+		return nil, err
+	}
+	if result.SubCategories, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	query = `UPDATE products p
+				SET deleted_at = NULL, is_deleted = FALSE, deleted_by_category_id = NULL, updated_at = NOW()
+				WHERE p.deleted_by_category_id = $1
+				  AND NOT EXISTS (
+					SELECT 1 FROM categories c
+					JOIN categories a ON c.path LIKE a.path || '%'
+					WHERE c.id = p.sub_category_id AND a.is_deleted = TRUE)`
+	res, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("error while restoring products of the category : %v", err)
+		return nil, err
+	}
+	if result.Products, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("error while committing transaction : %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+/*
+PurgeDeleted:
+- Permanently delete the categories soft deleted before the given time
+- A category is only purged when no products are left under it, deleted or not
+- Categories under it must be purged along with it
+- The trash usecase purges the products first, so their categories can go in the same run
+*/
+func (r *categoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM categories c
+		WHERE c.is_deleted = TRUE AND c.deleted_at < $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM categories d
+			JOIN products p ON p.sub_category_id = d.id
+			WHERE d.path LIKE c.path || '%')
+		  AND NOT EXISTS (
+			SELECT 1 FROM categories d
+			WHERE d.path LIKE c.path || '%' AND d.id <> c.id
+			  AND (d.is_deleted = FALSE OR d.deleted_at >= $1))
+	`
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		log.Printf("error while purging deleted categories : %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
 }
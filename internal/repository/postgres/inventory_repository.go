@@ -7,7 +7,9 @@ import (
 	"log"
 	"strings"
 
+	"github.com/lib/pq"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 )
 
 type inventoryRepository struct {
@@ -18,12 +20,26 @@ func NewInventoryRepository(db *sql.DB) *inventoryRepository {
 	return &inventoryRepository{db: db}
 }
 
+/*
+GetInventory:
+- Products with variants are listed once for each active variant, with the variant price and stock
+- Products without variants are listed with the product price and stock
+- Reserved quantity is the stock held by the active reservations of the product or the variant
+- Stock is the total over the locations, the stock at each location is added when broken down by location
+*/
 func (r *inventoryRepository) GetInventory(ctx context.Context, params domain.InventoryQueryParams) ([]*domain.InventoryItem, int64, error) {
 	query := `
-        SELECT p.id, p.name, p.price, p.stock_quantity, c.id, c.name
+        SELECT p.id, p.name AS product_name, pv.id, pv.sku, pv.size, pv.color,
+               COALESCE(pv.price, p.price) AS price,
+               COALESCE(pv.stock_quantity, p.stock_quantity) AS stock_quantity,
+               COALESCE((SELECT SUM(r.quantity) FROM stock_reservations r
+                         WHERE r.product_id = p.id AND (pv.id IS NULL OR r.variant_id = pv.id)
+                           AND ` + activeReservationFilter + `), 0) AS reserved_quantity,
+               p.reorder_threshold, c.id, c.name AS category_name
         FROM products p
         JOIN sub_categories sc ON p.sub_category_id = sc.id
         JOIN categories c ON sc.parent_category_id = c.id
+        LEFT JOIN product_variants pv ON pv.product_id = p.id AND pv.is_deleted = false
         WHERE 1=1
     `
 
@@ -55,18 +71,44 @@ func (r *inventoryRepository) GetInventory(ctx context.Context, params domain.In
 		argCount++
 	}
 
+	if params.SKU != "" {
+		conditions = append(conditions, fmt.Sprintf("LOWER(pv.sku) = LOWER($%d)", argCount))
+		args = append(args, params.SKU)
+		argCount++
+	}
+
+	if params.Size != "" {
+		conditions = append(conditions, fmt.Sprintf("LOWER(pv.size) = LOWER($%d)", argCount))
+		args = append(args, params.Size)
+		argCount++
+	}
+
+	if params.Color != "" {
+		conditions = append(conditions, fmt.Sprintf("LOWER(pv.color) = LOWER($%d)", argCount))
+		args = append(args, params.Color)
+		argCount++
+	}
+
 	if params.StockLessThan != nil {
-		conditions = append(conditions, fmt.Sprintf("p.stock_quantity < $%d", argCount))
+		conditions = append(conditions, fmt.Sprintf("COALESCE(pv.stock_quantity, p.stock_quantity) < $%d", argCount))
 		args = append(args, *params.StockLessThan)
 		argCount++
 	}
 
 	if params.StockMoreThan != nil {
-		conditions = append(conditions, fmt.Sprintf("p.stock_quantity > $%d", argCount))
+		conditions = append(conditions, fmt.Sprintf("COALESCE(pv.stock_quantity, p.stock_quantity) > $%d", argCount))
 		args = append(args, *params.StockMoreThan)
 		argCount++
 	}
 
+	if params.LocationID != 0 {
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (SELECT 1 FROM location_stock ls
+			WHERE ls.location_id = $%d AND ls.product_id = p.id AND COALESCE(ls.variant_id, 0) = COALESCE(pv.id, 0)
+			  AND ls.stock_quantity > 0)`, argCount))
+		args = append(args, params.LocationID)
+		argCount++
+	}
+
 	if len(conditions) > 0 {
 		query += " AND " + strings.Join(conditions, " AND ")
 	}
@@ -102,11 +144,17 @@ func (r *inventoryRepository) GetInventory(ctx context.Context, params domain.In
 	var items []*domain.InventoryItem
 	for rows.Next() {
 		var item domain.InventoryItem
-		err := rows.Scan(&item.ProductID, &item.ProductName, &item.Price, &item.StockQuantity, &item.CategoryID, &item.CategoryName)
+		var sku, size, color sql.NullString
+		err := rows.Scan(&item.ProductID, &item.ProductName, &item.VariantID, &sku, &size, &color,
+			&item.Price, &item.StockQuantity, &item.ReservedQuantity, &item.ReorderThreshold, &item.CategoryID, &item.CategoryName)
 		if err != nil {
 			log.Printf("error while retrieving inventory item : %v", err)
 			return nil, 0, err
 		}
+		item.SKU = sku.String
+		item.Size = size.String
+		item.Color = color.String
+		item.AvailableQuantity = item.StockQuantity - item.ReservedQuantity
 		items = append(items, &item)
 	}
 
@@ -114,5 +162,335 @@ func (r *inventoryRepository) GetInventory(ctx context.Context, params domain.In
 		return nil, 0, err
 	}
 
+	if params.Breakdown == utils.InventoryBreakdownLocation && len(items) > 0 {
+		err = r.addLocationBreakdown(ctx, items)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return items, total, nil
 }
+
+/*
+addLocationBreakdown:
+- Add the stock at each location to the inventory items, in the priority order of the locations
+- Locations which don't hold the item are left out
+*/
+func (r *inventoryRepository) addLocationBreakdown(ctx context.Context, items []*domain.InventoryItem) error {
+	productIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	query := `
+		SELECT l.id, l.code, l.name, ls.product_id, ls.variant_id, ls.stock_quantity, ls.updated_at
+		FROM location_stock ls
+		JOIN inventory_locations l ON ls.location_id = l.id
+		WHERE ls.product_id = ANY($1) AND ls.stock_quantity > 0
+		ORDER BY l.priority, l.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(productIDs))
+	if err != nil {
+		log.Printf("error while retrieving the stock at the locations : %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	type itemKey struct {
+		productID int64
+		variantID int64
+	}
+	byItem := make(map[itemKey]*domain.InventoryItem, len(items))
+	for _, item := range items {
+		key := itemKey{productID: item.ProductID}
+		if item.VariantID != nil {
+			key.variantID = *item.VariantID
+		}
+		item.Locations = []*domain.LocationStock{}
+		byItem[key] = item
+	}
+
+	for rows.Next() {
+		var s domain.LocationStock
+		err := rows.Scan(&s.LocationID, &s.LocationCode, &s.LocationName, &s.ProductID, &s.VariantID, &s.StockQuantity, &s.UpdatedAt)
+		if err != nil {
+			log.Printf("error while scanning the stock at the location : %v", err)
+			return err
+		}
+		key := itemKey{productID: s.ProductID}
+		if s.VariantID != nil {
+			key.variantID = *s.VariantID
+		}
+		if item, ok := byItem[key]; ok {
+			item.Locations = append(item.Locations, &s)
+		}
+	}
+
+	return rows.Err()
+}
+
+/*
+GetStockMovements:
+- Movements of the product and its variants, latest first
+- Movements of a single variant are retrieved when the variant is given
+*/
+func (r *inventoryRepository) GetStockMovements(ctx context.Context, params domain.StockMovementQueryParams) ([]*domain.StockMovement, int64, error) {
+	where := " WHERE product_id = $1"
+	args := []interface{}{params.ProductID}
+	if params.VariantID != nil {
+		args = append(args, *params.VariantID)
+		where += fmt.Sprintf(" AND variant_id = $%d", len(args))
+	}
+
+	var total int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stock_movements"+where, args...).Scan(&total)
+	if err != nil {
+		log.Printf("error while counting stock movements : %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, product_id, variant_id, quantity_change, stock_after, reason,
+			reference_type, reference_id, actor_type, actor_id, location_id, created_at
+		FROM stock_movements` + where + fmt.Sprintf(`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	args = append(args, params.Limit, (params.Page-1)*params.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while retrieving stock movements : %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := []*domain.StockMovement{}
+	for rows.Next() {
+		var m domain.StockMovement
+		err := rows.Scan(&m.ID, &m.ProductID, &m.VariantID, &m.QuantityChange, &m.StockAfter, &m.Reason,
+			&m.ReferenceType, &m.ReferenceID, &m.ActorType, &m.ActorID, &m.LocationID, &m.CreatedAt)
+		if err != nil {
+			log.Printf("error while scanning stock movement : %v", err)
+			return nil, 0, err
+		}
+		movements = append(movements, &m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return movements, total, nil
+}
+
+/*
+GetStockDiscrepancies:
+- Recompute the stock of every product and variant as the sum of its stock movements
+- Product stock is compared with the movements without a variant, variant stock with the movements of the variant
+- Only the products and variants whose stock differs from the ledger are returned
+- All the products are checked when productID is 0
+*/
+func (r *inventoryRepository) GetStockDiscrepancies(ctx context.Context, productID int64) ([]*domain.StockDiscrepancy, error) {
+	productFilter, variantFilter := "", ""
+	var args []interface{}
+	if productID != 0 {
+		args = append(args, productID)
+		productFilter = " WHERE p.id = $1"
+		variantFilter = " WHERE pv.product_id = $1"
+	}
+
+	query := `
+		SELECT p.id, p.name, NULL::BIGINT, '', p.stock_quantity, COALESCE(SUM(sm.quantity_change), 0) AS ledger_quantity
+		FROM products p
+		LEFT JOIN stock_movements sm ON sm.product_id = p.id AND sm.variant_id IS NULL` + productFilter + `
+		GROUP BY p.id
+		HAVING p.stock_quantity <> COALESCE(SUM(sm.quantity_change), 0)
+		UNION ALL
+		SELECT p.id, p.name, pv.id, pv.sku, pv.stock_quantity, COALESCE(SUM(sm.quantity_change), 0) AS ledger_quantity
+		FROM product_variants pv
+		JOIN products p ON pv.product_id = p.id
+		LEFT JOIN stock_movements sm ON sm.variant_id = pv.id` + variantFilter + `
+		GROUP BY p.id, pv.id
+		HAVING pv.stock_quantity <> COALESCE(SUM(sm.quantity_change), 0)
+		ORDER BY 1, 3 NULLS FIRST
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while checking stock against the ledger : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	discrepancies := []*domain.StockDiscrepancy{}
+	for rows.Next() {
+		var d domain.StockDiscrepancy
+		err := rows.Scan(&d.ProductID, &d.ProductName, &d.VariantID, &d.SKU, &d.StockQuantity, &d.LedgerQuantity)
+		if err != nil {
+			log.Printf("error while scanning stock discrepancy : %v", err)
+			return nil, err
+		}
+		d.Difference = d.StockQuantity - d.LedgerQuantity
+		discrepancies = append(discrepancies, &d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return discrepancies, nil
+}
+
+// SetReorderThreshold sets the stock level at which the product raises a low stock alert, nil stops watching the product
+func (r *inventoryRepository) SetReorderThreshold(ctx context.Context, productID int64, threshold *int) error {
+	query := `UPDATE products SET reorder_threshold = $1, updated_at = NOW() WHERE id = $2 AND is_deleted = false`
+	result, err := r.db.ExecContext(ctx, query, threshold, productID)
+	if err != nil {
+		log.Printf("error while updating reorder threshold : %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return utils.ErrProductNotFound
+	}
+
+	return nil
+}
+
+/*
+ResolveStockAlerts:
+- Resolve the open alerts of the products whose stock is back above the threshold
+- Alerts of the deleted products and of the products which are not watched anymore are resolved as well
+*/
+func (r *inventoryRepository) ResolveStockAlerts(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE stock_alerts a
+		SET status = $1, resolved_at = NOW()
+		FROM products p
+		WHERE a.product_id = p.id AND a.status = $2
+			AND (p.is_deleted OR p.reorder_threshold IS NULL OR p.stock_quantity > p.reorder_threshold)
+	`
+	result, err := r.db.ExecContext(ctx, query, utils.StockAlertStatusResolved, utils.StockAlertStatusOpen)
+	if err != nil {
+		log.Printf("error while resolving stock alerts : %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+/*
+CreateStockAlerts:
+- Open an alert for every watched product whose stock is at or below its threshold, unless it already has an open alert
+- Daily sales velocity is the quantity sold in the trailing window of not cancelled orders, divided by the days of the window
+- Suggested reorder brings the stock to the threshold plus the sales expected in the cover days
+*/
+func (r *inventoryRepository) CreateStockAlerts(ctx context.Context, windowDays, coverDays int) (int64, error) {
+	query := `
+		WITH sales AS (
+			SELECT oi.product_id, SUM(oi.quantity)::NUMERIC / $1::INTEGER AS velocity
+			FROM order_items oi
+			JOIN orders o ON oi.order_id = o.id
+			WHERE o.is_cancelled = false AND o.created_at >= NOW() - make_interval(days => $1::INTEGER)
+			GROUP BY oi.product_id
+		)
+		INSERT INTO stock_alerts (product_id, reorder_threshold, stock_quantity, daily_sales_velocity, suggested_reorder_quantity, status)
+		SELECT p.id, p.reorder_threshold, p.stock_quantity, ROUND(COALESCE(s.velocity, 0), 2),
+			GREATEST(p.reorder_threshold + CEIL(COALESCE(s.velocity, 0) * $2::INTEGER)::INTEGER - p.stock_quantity, 0),
+			$3
+		FROM products p
+		LEFT JOIN sales s ON s.product_id = p.id
+		WHERE p.is_deleted = false AND p.reorder_threshold IS NOT NULL AND p.stock_quantity <= p.reorder_threshold
+		ON CONFLICT (product_id) WHERE status = 'open' DO NOTHING
+	`
+	result, err := r.db.ExecContext(ctx, query, windowDays, coverDays, utils.StockAlertStatusOpen)
+	if err != nil {
+		log.Printf("error while creating stock alerts : %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetUnsentStockAlerts retrieves the open alerts which are not emailed yet
+func (r *inventoryRepository) GetUnsentStockAlerts(ctx context.Context) ([]*domain.StockAlert, error) {
+	query := stockAlertColumns + ` WHERE a.status = $1 AND a.emailed_at IS NULL ORDER BY a.id`
+	rows, err := r.db.QueryContext(ctx, query, utils.StockAlertStatusOpen)
+	if err != nil {
+		log.Printf("error while retrieving unsent stock alerts : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanStockAlerts(rows)
+}
+
+func (r *inventoryRepository) MarkStockAlertsEmailed(ctx context.Context, alertIDs []int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE stock_alerts SET emailed_at = NOW() WHERE id = ANY($1)`, pq.Array(alertIDs))
+	if err != nil {
+		log.Printf("error while marking stock alerts as emailed : %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetStockAlerts retrieves the alerts with the given status, all the alerts when the status is empty, latest first
+func (r *inventoryRepository) GetStockAlerts(ctx context.Context, params domain.StockAlertQueryParams) ([]*domain.StockAlert, int64, error) {
+	where := ""
+	var args []interface{}
+	if params.Status != "" {
+		args = append(args, params.Status)
+		where = " WHERE a.status = $1"
+	}
+
+	var total int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stock_alerts a"+where, args...).Scan(&total)
+	if err != nil {
+		log.Printf("error while counting stock alerts : %v", err)
+		return nil, 0, err
+	}
+
+	query := stockAlertColumns + where + fmt.Sprintf(" ORDER BY a.created_at DESC, a.id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, params.Limit, (params.Page-1)*params.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while retrieving stock alerts : %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	alerts, err := scanStockAlerts(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return alerts, total, nil
+}
+
+const stockAlertColumns = `
+	SELECT a.id, a.product_id, p.name, a.reorder_threshold, a.stock_quantity, a.daily_sales_velocity,
+		a.suggested_reorder_quantity, a.status, a.emailed_at, a.resolved_at, a.created_at
+	FROM stock_alerts a
+	JOIN products p ON a.product_id = p.id`
+
+func scanStockAlerts(rows *sql.Rows) ([]*domain.StockAlert, error) {
+	alerts := []*domain.StockAlert{}
+	for rows.Next() {
+		var a domain.StockAlert
+		err := rows.Scan(&a.ID, &a.ProductID, &a.ProductName, &a.ReorderThreshold, &a.StockQuantity, &a.DailySalesVelocity,
+			&a.SuggestedReorderQuantity, &a.Status, &a.EmailedAt, &a.ResolvedAt, &a.CreatedAt)
+		if err != nil {
+			log.Printf("error while scanning stock alert : %v", err)
+			return nil, err
+		}
+		alerts = append(alerts, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
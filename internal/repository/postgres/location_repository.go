@@ -0,0 +1,445 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type locationRepository struct {
+	db *sql.DB
+}
+
+func NewLocationRepository(db *sql.DB) *locationRepository {
+	return &locationRepository{db: db}
+}
+
+func (r *locationRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.db.BeginTx(ctx, nil)
+}
+
+// mapLocationUniqueViolation converts unique violations on inventory_locations table to the respective errors
+func mapLocationUniqueViolation(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code != "23505" {
+		return nil
+	}
+	if pqErr.Constraint == "inventory_locations_code_key" {
+		return utils.ErrDuplicateLocationCode
+	}
+	return utils.ErrDuplicateLocationName
+}
+
+func (r *locationRepository) Create(ctx context.Context, location *domain.InventoryLocation) error {
+	query := `
+		INSERT INTO inventory_locations (name, code, pincode, priority, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query, location.Name, location.Code, location.Pincode, location.Priority,
+		location.IsActive, location.CreatedAt, location.UpdatedAt).Scan(&location.ID)
+	if err != nil {
+		if dupErr := mapLocationUniqueViolation(err); dupErr != nil {
+			return dupErr
+		}
+		log.Printf("error while creating inventory location : %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *locationRepository) GetByID(ctx context.Context, id int64) (*domain.InventoryLocation, error) {
+	query := `
+		SELECT id, name, code, pincode, priority, is_active, created_at, updated_at
+		FROM inventory_locations
+		WHERE id = $1
+	`
+	var l domain.InventoryLocation
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&l.ID, &l.Name, &l.Code, &l.Pincode, &l.Priority,
+		&l.IsActive, &l.CreatedAt, &l.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrLocationNotFound
+		}
+		log.Printf("error while retrieving inventory location : %v", err)
+		return nil, err
+	}
+	return &l, nil
+}
+
+/*
+GetAll:
+- Get all the locations in their priority order
+- Stock units and the number of items in stock are aggregated for each location
+*/
+func (r *locationRepository) GetAll(ctx context.Context) ([]*domain.InventoryLocation, error) {
+	query := `
+		SELECT l.id, l.name, l.code, l.pincode, l.priority, l.is_active, l.created_at, l.updated_at,
+		       COALESCE(SUM(ls.stock_quantity), 0), COUNT(ls.id)
+		FROM inventory_locations l
+		LEFT JOIN location_stock ls ON ls.location_id = l.id AND ls.stock_quantity > 0
+		GROUP BY l.id
+		ORDER BY l.priority, l.id
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("error while retrieving inventory locations : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []*domain.InventoryLocation{}
+	for rows.Next() {
+		var l domain.InventoryLocation
+		err := rows.Scan(&l.ID, &l.Name, &l.Code, &l.Pincode, &l.Priority, &l.IsActive, &l.CreatedAt, &l.UpdatedAt,
+			&l.StockUnits, &l.ItemCount)
+		if err != nil {
+			log.Printf("error while scanning inventory location : %v", err)
+			return nil, err
+		}
+		locations = append(locations, &l)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+// Update updates the details of the location, the code of a location can't be changed
+func (r *locationRepository) Update(ctx context.Context, location *domain.InventoryLocation) error {
+	query := `
+		UPDATE inventory_locations
+		SET name = $1, pincode = $2, priority = $3, is_active = $4, updated_at = $5
+		WHERE id = $6
+	`
+	result, err := r.db.ExecContext(ctx, query, location.Name, location.Pincode, location.Priority,
+		location.IsActive, location.UpdatedAt, location.ID)
+	if err != nil {
+		if dupErr := mapLocationUniqueViolation(err); dupErr != nil {
+			return dupErr
+		}
+		log.Printf("error while updating inventory location : %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("error while checking updated inventory location : %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		return utils.ErrLocationNotFound
+	}
+	return nil
+}
+
+// HasStock reports whether the location holds stock which can be sold, stock of a product in the trash isn't counted
+func (r *locationRepository) HasStock(ctx context.Context, locationID int64) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM location_stock ls
+			JOIN products p ON ls.product_id = p.id
+			LEFT JOIN product_variants pv ON ls.variant_id = pv.id
+			WHERE ls.location_id = $1 AND ls.stock_quantity > 0
+			  AND p.is_deleted = false AND (pv.id IS NULL OR pv.is_deleted = false)
+		)
+	`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, locationID).Scan(&exists)
+	if err != nil {
+		log.Printf("error while checking if the location holds stock : %v", err)
+	}
+	return exists, err
+}
+
+func (r *locationRepository) CountActive(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM inventory_locations WHERE is_active = true`).Scan(&count)
+	if err != nil {
+		log.Printf("error while counting active inventory locations : %v", err)
+	}
+	return count, err
+}
+
+/*
+GetLocationStock:
+- Get the stock held at the location, for the products without variants and for the variants
+- Filtered by the product when it's given
+*/
+func (r *locationRepository) GetLocationStock(ctx context.Context, params domain.LocationStockQueryParams) ([]*domain.LocationStock, int64, error) {
+	where := " WHERE ls.location_id = $1 AND ls.stock_quantity > 0"
+	args := []interface{}{params.LocationID}
+	if params.ProductID != 0 {
+		args = append(args, params.ProductID)
+		where += fmt.Sprintf(" AND ls.product_id = $%d", len(args))
+	}
+
+	var total int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM location_stock ls"+where, args...).Scan(&total)
+	if err != nil {
+		log.Printf("error while counting location stock : %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT l.id, l.code, l.name, ls.product_id, p.name, ls.variant_id, COALESCE(pv.sku, ''),
+		       ls.stock_quantity, ls.updated_at
+		FROM location_stock ls
+		JOIN inventory_locations l ON ls.location_id = l.id
+		JOIN products p ON ls.product_id = p.id
+		LEFT JOIN product_variants pv ON ls.variant_id = pv.id` + where + fmt.Sprintf(`
+		ORDER BY p.name, ls.product_id, ls.variant_id NULLS FIRST
+		LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	args = append(args, params.Limit, (params.Page-1)*params.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while retrieving location stock : %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	stocks := []*domain.LocationStock{}
+	for rows.Next() {
+		var s domain.LocationStock
+		err := rows.Scan(&s.LocationID, &s.LocationCode, &s.LocationName, &s.ProductID, &s.ProductName, &s.VariantID, &s.SKU,
+			&s.StockQuantity, &s.UpdatedAt)
+		if err != nil {
+			log.Printf("error while scanning location stock : %v", err)
+			return nil, 0, err
+		}
+		stocks = append(stocks, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return stocks, total, nil
+}
+
+/*
+LockItemStockTx:
+- Lock the product row, and then the variant row when the variant is given, till the end of the transaction
+- Every stock change locks these rows before the location stock, so locking them first keeps the same lock order
+- Returns the name of the product, to report the item when its stock is short
+*/
+func (r *locationRepository) LockItemStockTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64) (string, error) {
+	var name string
+	err := tx.QueryRowContext(ctx, `SELECT name FROM products WHERE id = $1 FOR UPDATE`, productID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", utils.ErrProductNotFound
+	}
+	if err != nil {
+		log.Printf("error while locking the stock of the item : %v", err)
+		return "", err
+	}
+
+	if variantID != nil {
+		var id int64
+		err = tx.QueryRowContext(ctx, `SELECT id FROM product_variants WHERE id = $1 AND product_id = $2 FOR UPDATE`,
+			*variantID, productID).Scan(&id)
+		if err == sql.ErrNoRows {
+			return "", utils.ErrVariantNotFound
+		}
+		if err != nil {
+			log.Printf("error while locking the stock of the item : %v", err)
+			return "", err
+		}
+	}
+	return name, nil
+}
+
+// GetItemStockTx gets the stock of the product or of its variant at the location, the location stock row is locked
+func (r *locationRepository) GetItemStockTx(ctx context.Context, tx *sql.Tx, locationID, productID int64, variantID *int64) (int, error) {
+	query := `
+		SELECT stock_quantity FROM location_stock
+		WHERE location_id = $1 AND product_id = $2 AND COALESCE(variant_id, 0) = COALESCE($3::BIGINT, 0)
+		FOR UPDATE
+	`
+	var stock int
+	err := tx.QueryRowContext(ctx, query, locationID, productID, variantID).Scan(&stock)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		log.Printf("error while retrieving the stock at the location : %v", err)
+		return 0, err
+	}
+	return stock, nil
+}
+
+/*
+GetAllocatableStockTx:
+- Get the active locations holding the stock of the product or of its variant, in their priority order
+- The location stock rows are locked, the caller locks the item with LockItemStockTx first
+*/
+func (r *locationRepository) GetAllocatableStockTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64) ([]*domain.LocationStock, error) {
+	query := `
+		SELECT l.id, l.code, l.name, l.pincode, l.priority, ls.product_id, ls.variant_id, ls.stock_quantity, ls.updated_at
+		FROM location_stock ls
+		JOIN inventory_locations l ON ls.location_id = l.id
+		WHERE ls.product_id = $1 AND COALESCE(ls.variant_id, 0) = COALESCE($2::BIGINT, 0)
+		  AND l.is_active = true AND ls.stock_quantity > 0
+		ORDER BY l.priority, l.id
+		FOR UPDATE OF ls
+	`
+	rows, err := tx.QueryContext(ctx, query, productID, variantID)
+	if err != nil {
+		log.Printf("error while retrieving the stock at the locations : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stocks []*domain.LocationStock
+	for rows.Next() {
+		var s domain.LocationStock
+		err := rows.Scan(&s.LocationID, &s.LocationCode, &s.LocationName, &s.Pincode, &s.Priority,
+			&s.ProductID, &s.VariantID, &s.StockQuantity, &s.UpdatedAt)
+		if err != nil {
+			log.Printf("error while scanning the stock at the location : %v", err)
+			return nil, err
+		}
+		stocks = append(stocks, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stocks, nil
+}
+
+func (r *locationRepository) CreateAllocationTx(ctx context.Context, tx *sql.Tx, allocation *domain.OrderAllocation) error {
+	query := `
+		INSERT INTO order_allocations (order_id, location_id, product_id, variant_id, quantity, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := tx.QueryRowContext(ctx, query, allocation.OrderID, allocation.LocationID, allocation.ProductID,
+		allocation.VariantID, allocation.Quantity, allocation.Status, allocation.CreatedAt).Scan(&allocation.ID)
+	if err != nil {
+		log.Printf("error while recording the allocation of the order : %v", err)
+		return err
+	}
+	return nil
+}
+
+/*
+ReleaseAllocationsTx:
+- Mark the allocations of the order as released
+- Returns the allocations which were released now, allocations released before are not returned again
+*/
+func (r *locationRepository) ReleaseAllocationsTx(ctx context.Context, tx *sql.Tx, orderID int64) ([]*domain.OrderAllocation, error) {
+	query := `
+		UPDATE order_allocations
+		SET status = $1, released_at = $2
+		WHERE order_id = $3 AND status = $4
+		RETURNING id, order_id, location_id, product_id, variant_id, quantity, status, created_at, released_at
+	`
+	rows, err := tx.QueryContext(ctx, query, utils.OrderAllocationStatusReleased, time.Now().UTC(),
+		orderID, utils.OrderAllocationStatusAllocated)
+	if err != nil {
+		log.Printf("error while releasing the allocations of the order : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allocations []*domain.OrderAllocation
+	for rows.Next() {
+		var a domain.OrderAllocation
+		err := rows.Scan(&a.ID, &a.OrderID, &a.LocationID, &a.ProductID, &a.VariantID, &a.Quantity, &a.Status,
+			&a.CreatedAt, &a.ReleasedAt)
+		if err != nil {
+			log.Printf("error while scanning the released allocation : %v", err)
+			return nil, err
+		}
+		allocations = append(allocations, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+func (r *locationRepository) CreateTransferTx(ctx context.Context, tx *sql.Tx, transfer *domain.StockTransfer) error {
+	query := `
+		INSERT INTO stock_transfers (from_location_id, to_location_id, product_id, variant_id, quantity, note, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	err := tx.QueryRowContext(ctx, query, transfer.FromLocationID, transfer.ToLocationID, transfer.ProductID,
+		transfer.VariantID, transfer.Quantity, transfer.Note, transfer.CreatedBy, transfer.CreatedAt).Scan(&transfer.ID)
+	if err != nil {
+		log.Printf("error while recording the stock transfer : %v", err)
+		return err
+	}
+	return nil
+}
+
+/*
+GetTransfers:
+- Get the stock transfers, latest first
+- Filtered by the location, either side of the transfer, and by the product when they are given
+*/
+func (r *locationRepository) GetTransfers(ctx context.Context, params domain.StockTransferQueryParams) ([]*domain.StockTransfer, int64, error) {
+	where := " WHERE 1=1"
+	var args []interface{}
+	if params.LocationID != 0 {
+		args = append(args, params.LocationID)
+		where += fmt.Sprintf(" AND (from_location_id = $%d OR to_location_id = $%d)", len(args), len(args))
+	}
+	if params.ProductID != 0 {
+		args = append(args, params.ProductID)
+		where += fmt.Sprintf(" AND product_id = $%d", len(args))
+	}
+
+	var total int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM stock_transfers"+where, args...).Scan(&total)
+	if err != nil {
+		log.Printf("error while counting stock transfers : %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, from_location_id, to_location_id, product_id, variant_id, quantity, COALESCE(note, ''), created_by, created_at
+		FROM stock_transfers` + where + fmt.Sprintf(`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	args = append(args, params.Limit, (params.Page-1)*params.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while retrieving stock transfers : %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	transfers := []*domain.StockTransfer{}
+	for rows.Next() {
+		var t domain.StockTransfer
+		err := rows.Scan(&t.ID, &t.FromLocationID, &t.ToLocationID, &t.ProductID, &t.VariantID, &t.Quantity,
+			&t.Note, &t.CreatedBy, &t.CreatedAt)
+		if err != nil {
+			log.Printf("error while scanning stock transfer : %v", err)
+			return nil, 0, err
+		}
+		transfers = append(transfers, &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return transfers, total, nil
+}
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type offerRepository struct {
+	db *sql.DB
+}
+
+func NewOfferRepository(db *sql.DB) *offerRepository {
+	return &offerRepository{db: db}
+}
+
+func (r *offerRepository) Create(ctx context.Context, offer *domain.Offer) error {
+	query := `
+		INSERT INTO offers (name, discount_type, discount_value, product_id, sub_category_id, category_id,
+			starts_at, ends_at, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		offer.Name,
+		offer.DiscountType,
+		offer.DiscountValue,
+		offer.ProductID,
+		offer.SubCategoryID,
+		offer.CategoryID,
+		offer.StartsAt,
+		offer.EndsAt,
+		offer.IsActive,
+		offer.CreatedAt,
+		offer.UpdatedAt,
+	).Scan(&offer.ID)
+	if err != nil {
+		log.Printf("error while creating offer : %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *offerRepository) GetByID(ctx context.Context, offerID int64) (*domain.Offer, error) {
+	query := `
+		SELECT id, name, discount_type, discount_value, product_id, sub_category_id, category_id,
+			starts_at, ends_at, is_active, created_at, updated_at
+		FROM offers
+		WHERE id = $1
+	`
+	offer, err := scanOffer(r.db.QueryRowContext(ctx, query, offerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrOfferNotFound
+		}
+		log.Printf("error while retrieving offer using id : %v", err)
+		return nil, err
+	}
+	return offer, nil
+}
+
+/*
+GetAll:
+- If active only is set, offers which are active and running now are listed
+- Latest offers are listed first
+*/
+func (r *offerRepository) GetAll(ctx context.Context, params domain.OfferQueryParams) ([]*domain.Offer, int64, error) {
+	whereClause := ""
+	if params.ActiveOnly {
+		whereClause = " WHERE is_active = true AND NOW() BETWEEN starts_at AND ends_at"
+	}
+
+	var totalCount int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM offers"+whereClause).Scan(&totalCount)
+	if err != nil {
+		log.Printf("error while counting offers : %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, name, discount_type, discount_value, product_id, sub_category_id, category_id,
+			starts_at, ends_at, is_active, created_at, updated_at
+		FROM offers` + whereClause + `
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, params.Limit, (params.Page-1)*params.Limit)
+	if err != nil {
+		log.Printf("error while retrieving offers : %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var offers []*domain.Offer
+	for rows.Next() {
+		offer, err := scanOffer(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		offers = append(offers, offer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return offers, totalCount, nil
+}
+
+func (r *offerRepository) Deactivate(ctx context.Context, offerID int64) error {
+	query := `UPDATE offers SET is_active = false, updated_at = NOW() WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, offerID)
+	if err != nil {
+		log.Printf("error while deactivating offer : %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return utils.ErrOfferNotFound
+	}
+	return nil
+}
+
+// GetActiveOffersForProduct retrieves the running offers of the product and of the categories it is under, at any level of the category tree
+func (r *offerRepository) GetActiveOffersForProduct(ctx context.Context, productID int64) ([]*domain.Offer, error) {
+	query := `
+		SELECT o.id, o.name, o.discount_type, o.discount_value, o.product_id, o.sub_category_id, o.category_id,
+			o.starts_at, o.ends_at, o.is_active, o.created_at, o.updated_at
+		FROM products p
+		JOIN sub_categories sc ON p.sub_category_id = sc.id
+		JOIN offers o ON o.product_id = p.id OR sc.path LIKE '%/' || COALESCE(o.sub_category_id, o.category_id) || '/%'
+		WHERE p.id = $1 AND o.is_active = true AND NOW() BETWEEN o.starts_at AND o.ends_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		log.Printf("error while retrieving active offers of the product : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var offers []*domain.Offer
+	for rows.Next() {
+		offer, err := scanOffer(rows)
+		if err != nil {
+			return nil, err
+		}
+		offers = append(offers, offer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOffer(row rowScanner) (*domain.Offer, error) {
+	var offer domain.Offer
+	err := row.Scan(
+		&offer.ID, &offer.Name, &offer.DiscountType, &offer.DiscountValue,
+		&offer.ProductID, &offer.SubCategoryID, &offer.CategoryID,
+		&offer.StartsAt, &offer.EndsAt, &offer.IsActive, &offer.CreatedAt, &offer.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
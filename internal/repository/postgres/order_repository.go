@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"slices"
 	"time"
 
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/pagination"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 )
 
@@ -183,6 +185,97 @@ func (r *orderRepository) GetOrders(ctx context.Context, limit, offset int) ([]*
 	return orders, totalOrders, nil
 }
 
+// GetUserOrdersByCursor retrieves a keyset page of the orders made by the user, newest first
+func (r *orderRepository) GetUserOrdersByCursor(ctx context.Context, userID int64, params pagination.Params) ([]*domain.Order, pagination.Page, error) {
+	return r.getOrdersByCursor(ctx, &userID, params)
+}
+
+// GetOrdersByCursor retrieves a keyset page of all the orders, newest first
+func (r *orderRepository) GetOrdersByCursor(ctx context.Context, params pagination.Params) ([]*domain.Order, pagination.Page, error) {
+	return r.getOrdersByCursor(ctx, nil, params)
+}
+
+/*
+getOrdersByCursor:
+- Orders are paged on (created_at, id) instead of an offset, so no count query is needed
+- One more order than the limit is fetched to know if there is a following page
+- Orders fetched backwards (prev cursor) are reversed to keep the newest first order
+*/
+func (r *orderRepository) getOrdersByCursor(ctx context.Context, userID *int64, params pagination.Params) ([]*domain.Order, pagination.Page, error) {
+	query := `
+        SELECT id, user_id, total_amount, discount_amount, final_amount, shipping_address_id, 
+               coupon_applied, has_return_request, created_at, updated_at, delivered_at, 
+               order_status, delivery_status
+        FROM orders
+        WHERE true
+    `
+	var args []interface{}
+	if userID != nil {
+		args = append(args, *userID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+
+	condition, cursorArgs, orderBy := params.Clause("created_at", "id", len(args)+1)
+	if condition != "" {
+		query += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+	query += orderBy
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while fetching orders page from orders table : %v", err)
+		return nil, pagination.Page{}, err
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		var o domain.Order
+		err := rows.Scan(
+			&o.ID,
+			&o.UserID,
+			&o.TotalAmount,
+			&o.DiscountAmount,
+			&o.FinalAmount,
+			&o.ShippingAddressID,
+			&o.CouponApplied,
+			&o.HasReturnRequest,
+			&o.CreatedAt,
+			&o.UpdatedAt,
+			&o.DeliveredAt,
+			&o.OrderStatus,
+			&o.DeliveryStatus,
+		)
+		if err != nil {
+			log.Printf("error while scanning rows : %v", err)
+			return nil, pagination.Page{}, err
+		}
+		orders = append(orders, &o)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("error while iterating over the rows of orders : %v", err)
+		return nil, pagination.Page{}, err
+	}
+
+	hasMore := len(orders) > params.Limit
+	if hasMore {
+		orders = orders[:params.Limit]
+	}
+	if params.IsPrev() {
+		slices.Reverse(orders)
+	}
+
+	var first, last *pagination.Cursor
+	if len(orders) > 0 {
+		first = &pagination.Cursor{CreatedAt: &orders[0].CreatedAt, ID: orders[0].ID}
+		last = &pagination.Cursor{CreatedAt: &orders[len(orders)-1].CreatedAt, ID: orders[len(orders)-1].ID}
+	}
+
+	return orders, pagination.NewPage(params, hasMore, first, last), nil
+}
+
 func (r *orderRepository) UpdatePayment(ctx context.Context, payment *domain.Payment) error {
 	query := `
         UPDATE payments
@@ -248,14 +341,14 @@ func (r *orderRepository) CreateOrder(ctx context.Context, tx *sql.Tx, order *do
 /*
 AddOrderItem:
 - Add order item entry in order_items table
-- order_id, product_id, quantity, price
+- order_id, product_id, variant_id, quantity, price
 */
 func (r *orderRepository) AddOrderItem(ctx context.Context, tx *sql.Tx, item *domain.OrderItem) error {
 	query := `
-        INSERT INTO order_items (order_id, product_id, quantity, price)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO order_items (order_id, product_id, variant_id, quantity, price)
+        VALUES ($1, $2, $3, $4, $5)
     `
-	_, err := tx.ExecContext(ctx, query, item.OrderID, item.ProductID, item.Quantity, item.Price)
+	_, err := tx.ExecContext(ctx, query, item.OrderID, item.ProductID, item.VariantID, item.Quantity, item.Price)
 	if err != nil {
 		log.Printf("error while adding order item entry : %v", err)
 		return err
@@ -355,7 +448,7 @@ GetOrderItems:
 */
 func (r *orderRepository) GetOrderItems(ctx context.Context, orderID int64) ([]domain.OrderItem, error) {
 	query := `
-        SELECT id, order_id, product_id, quantity, price
+        SELECT id, order_id, product_id, variant_id, quantity, price
         FROM order_items
         WHERE order_id = $1
     `
@@ -373,6 +466,7 @@ func (r *orderRepository) GetOrderItems(ctx context.Context, orderID int64) ([]d
 		err := rows.Scan(&item.ID,
 			&item.OrderID,
 			&item.ProductID,
+			&item.VariantID,
 			&item.Quantity,
 			&item.Price)
 		if err != nil {
@@ -467,6 +561,25 @@ func (r *orderRepository) IsOrderDelivered(ctx context.Context, orderID int64) (
 	return isDelivered, err
 }
 
+// HasDeliveredOrderForProduct checks whether the user has a delivered order containing the product
+func (r *orderRepository) HasDeliveredOrderForProduct(ctx context.Context, userID, productID int64) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1
+			FROM orders o
+			JOIN order_items oi ON oi.order_id = o.id
+			WHERE o.user_id = $1 AND oi.product_id = $2 AND o.delivery_status = $3
+		)
+	`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, userID, productID, utils.DeliveryStatusDelivered).Scan(&exists)
+	if err != nil {
+		log.Printf("error while checking delivered orders of the product : %v", err)
+		return false, err
+	}
+	return exists, nil
+}
+
 func (r *orderRepository) GetOrderByID(ctx context.Context, id int64) (*domain.Order, error) {
 	query := `
         SELECT id, user_id, total_amount, discount_amount, final_amount, delivery_status, 
@@ -613,11 +726,13 @@ func (r *orderRepository) GetByIDTx(ctx context.Context, tx *sql.Tx, id int64) (
 	return &order, nil
 }
 
+// GetOrderItemsTx retrieves the items in product and variant order, so that their stock rows are locked in the same order as the order placement
 func (r *orderRepository) GetOrderItemsTx(ctx context.Context, tx *sql.Tx, orderID int64) ([]*domain.OrderItem, error) {
 	query := `
-        SELECT id, order_id, product_id, quantity, price
+        SELECT id, order_id, product_id, variant_id, quantity, price
         FROM order_items
         WHERE order_id = $1
+        ORDER BY product_id, variant_id NULLS FIRST
     `
 	rows, err := tx.QueryContext(ctx, query, orderID)
 	if err != nil {
@@ -628,7 +743,7 @@ func (r *orderRepository) GetOrderItemsTx(ctx context.Context, tx *sql.Tx, order
 	var items []*domain.OrderItem
 	for rows.Next() {
 		item := &domain.OrderItem{}
-		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.Price); err != nil {
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.VariantID, &item.Quantity, &item.Price); err != nil {
 			return nil, fmt.Errorf("failed to scan order item: %w", err)
 		}
 		items = append(items, item)
@@ -138,6 +138,26 @@ func (r *paymentRepository) UpdatePayment(ctx context.Context, payment *domain.P
 	return err
 }
 
+// UpdatePaymentTx updates the same payment details as UpdatePayment, as part of the given transaction
+func (r *paymentRepository) UpdatePaymentTx(ctx context.Context, tx *sql.Tx, payment *domain.Payment) error {
+	query := `
+        UPDATE payments
+        SET payment_status = $1, razorpay_payment_id = $2, razorpay_signature = $3, updated_at = $4
+        WHERE id = $5
+    `
+	_, err := tx.ExecContext(ctx, query,
+		payment.Status,
+		payment.RazorpayPaymentID,
+		payment.RazorpaySignature,
+		payment.UpdatedAt,
+		payment.ID)
+	if err != nil {
+		log.Printf("failed to update payment: %v", err)
+		return err
+	}
+	return nil
+}
+
 func (r *paymentRepository) GetByRazorpayOrderID(ctx context.Context, razorpayOrderID string) (*domain.Payment, error) {
 	query := `SELECT id, order_id, amount, payment_method, payment_status, created_at, updated_at, 
               razorpay_order_id, razorpay_payment_id, razorpay_signature
@@ -214,6 +234,46 @@ func (r *paymentRepository) GetByOrderIDTx(ctx context.Context, tx *sql.Tx, orde
 	return &payment, nil
 }
 
+/*
+GetByIDForUpdateTx:
+- Get payment details from payments table using payment id
+- The payment row is locked till the end of the transaction, so that the payment is processed only once
+*/
+func (r *paymentRepository) GetByIDForUpdateTx(ctx context.Context, tx *sql.Tx, paymentID int64) (*domain.Payment, error) {
+	query := `
+        SELECT id, order_id, amount, payment_method, payment_status, created_at, updated_at,
+               razorpay_order_id, razorpay_payment_id, razorpay_signature
+        FROM payments
+        WHERE id = $1
+        FOR UPDATE
+    `
+	var payment domain.Payment
+	var razorpayPaymentID, razorpaySignature sql.NullString
+
+	err := tx.QueryRowContext(ctx, query, paymentID).Scan(
+		&payment.ID, &payment.OrderID, &payment.Amount, &payment.PaymentMethod, &payment.Status,
+		&payment.CreatedAt, &payment.UpdatedAt, &payment.RazorpayOrderID,
+		&razorpayPaymentID, &razorpaySignature,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, utils.ErrPaymentNotFound
+	}
+	if err != nil {
+		log.Printf("failed to lock payment: %v", err)
+		return nil, err
+	}
+
+	if razorpayPaymentID.Valid {
+		payment.RazorpayPaymentID = razorpayPaymentID.String
+	}
+	if razorpaySignature.Valid {
+		payment.RazorpaySignature = razorpaySignature.String
+	}
+
+	return &payment, nil
+}
+
 /*
 UpdateStatusTx:
 - Update payment_status for the given payment id
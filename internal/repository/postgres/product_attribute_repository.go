@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type productAttributeRepository struct {
+	db *sql.DB
+}
+
+func NewProductAttributeRepository(db *sql.DB) *productAttributeRepository {
+	return &productAttributeRepository{db: db}
+}
+
+const productAttributeColumns = `pa.id, pa.sub_category_id, pa.name, pa.slug, pa.type, pa.allowed_values, pa.is_filterable, pa.created_at, pa.updated_at`
+
+func (r *productAttributeRepository) Create(ctx context.Context, attribute *domain.ProductAttribute) error {
+	query := `
+		INSERT INTO product_attributes (sub_category_id, name, slug, type, allowed_values, is_filterable, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		attribute.SubCategoryID, attribute.Name, attribute.Slug, attribute.Type,
+		pq.Array(attribute.AllowedValues), attribute.IsFilterable, attribute.CreatedAt, attribute.UpdatedAt,
+	).Scan(&attribute.ID)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Code == "23505" {
+			return utils.ErrDuplicateAttribute
+		}
+		log.Printf("error while creating product attribute : %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *productAttributeRepository) GetByID(ctx context.Context, id int) (*domain.ProductAttribute, error) {
+	query := `SELECT ` + productAttributeColumns + ` FROM product_attributes pa WHERE pa.id = $1`
+
+	attribute, err := scanProductAttribute(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrAttributeNotFound
+		}
+		log.Printf("error while retrieving product attribute : %v", err)
+		return nil, err
+	}
+	return attribute, nil
+}
+
+// GetForSubCategory retrieves the attributes which apply to the products of the sub category,
+// the ones defined on the sub category itself and on the sub categories above it
+func (r *productAttributeRepository) GetForSubCategory(ctx context.Context, subCategoryID int) ([]*domain.ProductAttribute, error) {
+	query := `
+		SELECT ` + productAttributeColumns + `
+		FROM sub_categories sc
+		JOIN product_attributes pa ON sc.path LIKE '%/' || pa.sub_category_id || '/%'
+		WHERE sc.id = $1
+		ORDER BY pa.name
+	`
+	rows, err := r.db.QueryContext(ctx, query, subCategoryID)
+	if err != nil {
+		log.Printf("error while retrieving product attributes of sub category : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []*domain.ProductAttribute{}
+	for rows.Next() {
+		attribute, err := scanProductAttribute(rows)
+		if err != nil {
+			log.Printf("error while scanning product attribute : %v", err)
+			return nil, err
+		}
+		attributes = append(attributes, attribute)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+/*
+SlugExistsInTree:
+- Check the slug against the attributes which apply to the same products as an attribute of the sub category
+- Those are the attributes of the sub categories above it and of the sub categories below it
+- A duplicate within the sub category itself is reported by the unique constraint of the table
+- The attribute being updated is left out with excludeID, 0 when creating
+*/
+func (r *productAttributeRepository) SlugExistsInTree(ctx context.Context, subCategoryID int, slug string, excludeID int) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1
+			FROM sub_categories sc
+			JOIN product_attributes pa ON pa.slug = $2 AND pa.id <> $3
+			JOIN sub_categories psc ON pa.sub_category_id = psc.id
+			WHERE sc.id = $1 AND psc.id <> sc.id
+			  AND (sc.path LIKE psc.path || '%' OR psc.path LIKE sc.path || '%')
+		)
+	`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, subCategoryID, slug, excludeID).Scan(&exists)
+	if err != nil {
+		log.Printf("error while checking if the attribute slug exists in the category tree : %v", err)
+	}
+	return exists, err
+}
+
+/*
+Update:
+- Update the name, allowed values and filterability of the attribute, type of an attribute can't be changed
+- Allowed values of a select attribute can only be removed when no product has them
+- Values of the products follow the new spelling of an allowed value, the values are stored as written in the allowed values
+*/
+func (r *productAttributeRepository) Update(ctx context.Context, attribute *domain.ProductAttribute) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE product_attributes
+		SET name = $1, slug = $2, allowed_values = $3, is_filterable = $4, updated_at = $5
+		WHERE id = $6
+	`
+	result, err := tx.ExecContext(ctx, query,
+		attribute.Name, attribute.Slug, pq.Array(attribute.AllowedValues), attribute.IsFilterable, time.Now().UTC(), attribute.ID)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Code == "23505" {
+			return utils.ErrDuplicateAttribute
+		}
+		log.Printf("error while updating product attribute : %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return utils.ErrAttributeNotFound
+	}
+
+	if attribute.Type == utils.AttributeTypeSelect {
+		var inUse bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM product_attribute_values
+				WHERE attribute_id = $1 AND LOWER(value) <> ALL(SELECT LOWER(v) FROM unnest($2::TEXT[]) v)
+			)`, attribute.ID, pq.Array(attribute.AllowedValues)).Scan(&inUse)
+		if err != nil {
+			log.Printf("error while checking the product values of the attribute : %v", err)
+			return err
+		}
+		if inUse {
+			return utils.ErrAllowedValueInUse
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE product_attribute_values pav
+			SET value = allowed.value
+			FROM unnest($2::TEXT[]) AS allowed(value)
+			WHERE pav.attribute_id = $1 AND LOWER(pav.value) = LOWER(allowed.value) AND pav.value <> allowed.value
+		`, attribute.ID, pq.Array(attribute.AllowedValues))
+		if err != nil {
+			log.Printf("error while updating the product values of the attribute : %v", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the attribute along with its values on the products
+func (r *productAttributeRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM product_attributes WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("error while deleting product attribute : %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return utils.ErrAttributeNotFound
+	}
+	return nil
+}
+
+// SetProductValues replaces the attribute values of the product with the given values, attribute id to value
+func (r *productAttributeRepository) SetProductValues(ctx context.Context, productID int64, values map[int]string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM product_attribute_values WHERE product_id = $1`, productID)
+	if err != nil {
+		log.Printf("error while removing product attribute values : %v", err)
+		return err
+	}
+
+	for attributeID, value := range values {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO product_attribute_values (product_id, attribute_id, value) VALUES ($1, $2, $3)`,
+			productID, attributeID, value)
+		if err != nil {
+			log.Printf("error while adding product attribute value : %v", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *productAttributeRepository) GetProductValues(ctx context.Context, productID int64) ([]*domain.ProductAttributeValue, error) {
+	return getProductAttributeValues(ctx, r.db, productID)
+}
+
+// getProductAttributeValues is shared with the product repository, which adds the values to the public product
+func getProductAttributeValues(ctx context.Context, db *sql.DB, productID int64) ([]*domain.ProductAttributeValue, error) {
+	query := `
+		SELECT pa.id, pa.name, pa.slug, pa.type, pav.value
+		FROM product_attribute_values pav
+		JOIN product_attributes pa ON pa.id = pav.attribute_id
+		WHERE pav.product_id = $1
+		ORDER BY pa.name
+	`
+	rows, err := db.QueryContext(ctx, query, productID)
+	if err != nil {
+		log.Printf("error while retrieving product attribute values : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []*domain.ProductAttributeValue{}
+	for rows.Next() {
+		var value domain.ProductAttributeValue
+		err := rows.Scan(&value.AttributeID, &value.Name, &value.Slug, &value.Type, &value.Value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, &value)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func scanProductAttribute(row rowScanner) (*domain.ProductAttribute, error) {
+	var attribute domain.ProductAttribute
+	err := row.Scan(
+		&attribute.ID, &attribute.SubCategoryID, &attribute.Name, &attribute.Slug, &attribute.Type,
+		pq.Array(&attribute.AllowedValues), &attribute.IsFilterable, &attribute.CreatedAt, &attribute.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &attribute, nil
+}
@@ -6,11 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/lib/pq"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/pagination"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 )
 
@@ -22,20 +24,35 @@ func NewProductRepository(db *sql.DB) *productRepository {
 	return &productRepository{db: db}
 }
 
-func (r *productRepository) Create(ctx context.Context, product *domain.Product) error {
+/*
+Create:
+- Add the product entry in products table
+- Initial stock of the product is recorded as its first stock movement, the stock is held at the primary location
+*/
+func (r *productRepository) Create(ctx context.Context, product *domain.Product, createdBy int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO products (name, slug, description, price, stock_quantity, sub_category_id, created_at, updated_at, is_deleted)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO products (name, slug, description, price, stock_quantity, sub_category_id, brand_id, status, publish_at, created_at, updated_at, is_deleted)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		product.Name,
 		product.Slug,
 		product.Description,
 		product.Price,
 		product.StockQuantity,
 		product.SubCategoryID,
+		product.BrandID,
+		product.Status,
+		product.PublishAt,
 		product.CreatedAt,
 		product.UpdatedAt, false).Scan(&product.ID)
 
@@ -56,7 +73,18 @@ func (r *productRepository) Create(ctx context.Context, product *domain.Product)
 		return err
 	}
 
-	return nil
+	change := adminStockChange(utils.StockMovementReasonInitialStock, createdBy)
+	err = r.adjustLocationStockTx(ctx, tx, product.ID, nil, product.StockQuantity, &change)
+	if err != nil {
+		return err
+	}
+
+	err = r.addStockMovementTx(ctx, tx, product.ID, nil, 0, product.StockQuantity, change)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *productRepository) SlugExists(ctx context.Context, slug string) (bool, error) {
@@ -80,13 +108,24 @@ func (r *productRepository) NameExists(ctx context.Context, name string) (bool,
 	return exists, err
 }
 
+// NameExistsExceptSlug checks the name the same way as NameExists, leaving out the product with the given slug which the import updates
+func (r *productRepository) NameExistsExceptSlug(ctx context.Context, name, slug string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM products WHERE LOWER(name) = LOWER($1) AND slug <> $2 AND is_deleted = false)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, name, slug).Scan(&exists)
+	if err != nil {
+		log.Printf("error while checking if product name exists: %v", err)
+	}
+	return exists, err
+}
+
 /*
 GetByID:
 - Get product details from products table
-- id, name, slug, description, price, stock_quantity, sub_category_id, created_at, updated_at, deleted_at, is_deleted
+- id, name, slug, description, price, stock_quantity, sub_category_id, status, publish_at, created_at, updated_at, deleted_at, is_deleted
 */
 func (r *productRepository) GetByID(ctx context.Context, id int64) (*domain.Product, error) {
-	query := `SELECT id, name, slug, description, price, stock_quantity, sub_category_id, created_at, updated_at, deleted_at, is_deleted
+	query := `SELECT id, name, slug, description, price, stock_quantity, sub_category_id, brand_id, status, publish_at, created_at, updated_at, deleted_at, is_deleted
               FROM products WHERE id = $1 AND is_deleted = false`
 
 	var product domain.Product
@@ -98,6 +137,9 @@ func (r *productRepository) GetByID(ctx context.Context, id int64) (*domain.Prod
 		&product.Price,
 		&product.StockQuantity,
 		&product.SubCategoryID,
+		&product.BrandID,
+		&product.Status,
+		&product.PublishAt,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 		&product.DeletedAt,
@@ -114,21 +156,54 @@ func (r *productRepository) GetByID(ctx context.Context, id int64) (*domain.Prod
 	return &product, nil
 }
 
-func (r *productRepository) Update(ctx context.Context, product *domain.Product) error {
+/*
+Update:
+- Lock the product row and get its current price
+- Update the product details
+- If the price is changed, the change is recorded in the price history along with the admin who made it
+- If the stock is changed, the change is recorded as a stock movement and applied to the stock at the primary location
+*/
+func (r *productRepository) Update(ctx context.Context, product *domain.Product, changedBy int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldPrice float64
+	var oldSlug string
+	var oldStock int
+	err = tx.QueryRowContext(ctx, `SELECT price, slug, stock_quantity FROM products WHERE id = $1 AND is_deleted = false FOR UPDATE`, product.ID).
+		Scan(&oldPrice, &oldSlug, &oldStock)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrProductNotFound
+		}
+		log.Printf("error while retrieving current product price : %v", err)
+		return err
+	}
+
 	query := `UPDATE products 
 			SET name = $1, slug = $2, description = $3, price = $4, 
-              stock_quantity = $5, sub_category_id = $6, updated_at = $7
-              WHERE id = $8 AND is_deleted = false`
+              stock_quantity = $5, sub_category_id = $6, brand_id = $7, status = $8, publish_at = $9, updated_at = $10
+              WHERE id = $11 AND is_deleted = false
+              RETURNING price, stock_quantity`
 
-	result, err := r.db.ExecContext(ctx, query,
+	var newPrice float64
+	var newStock int
+	err = tx.QueryRowContext(ctx, query,
 		product.Name,
 		product.Slug,
 		product.Description,
 		product.Price,
 		product.StockQuantity,
 		product.SubCategoryID,
+		product.BrandID,
+		product.Status,
+		product.PublishAt,
 		time.Now().UTC(),
-		product.ID)
+		product.ID).Scan(&newPrice, &newStock)
 
 	if err != nil {
 		pqErr, ok := err.(*pq.Error)
@@ -142,19 +217,282 @@ func (r *productRepository) Update(ctx context.Context, product *domain.Product)
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if newPrice != oldPrice {
+		err = r.addPriceHistoryTx(ctx, tx, product.ID, nil, oldPrice, newPrice, changedBy)
+		if err != nil {
+			return err
+		}
+	}
+
+	if product.Slug != oldSlug {
+		err = r.addSlugHistoryTx(ctx, tx, product.ID, oldSlug, product.Slug)
+		if err != nil {
+			return err
+		}
+	}
+
+	change := adminStockChange(utils.StockMovementReasonAdjustment, changedBy)
+	err = r.adjustLocationStockTx(ctx, tx, product.ID, nil, newStock-oldStock, &change)
 	if err != nil {
-		log.Printf("Error while checking rows affected %v:", err)
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return utils.ErrProductNotFound
+	err = r.addStockMovementTx(ctx, tx, product.ID, nil, oldStock, newStock, change)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+/*
+addSlugHistoryTx:
+- Keep the old slug of the product so that the old urls still resolve
+- An old slug reused by another product now points to that product
+- The new slug is removed from the history, the current slug always wins
+*/
+func (r *productRepository) addSlugHistoryTx(ctx context.Context, tx *sql.Tx, productID int64, oldSlug, newSlug string) error {
+	query := `
+		INSERT INTO product_slug_history (product_id, slug, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (slug) DO UPDATE SET product_id = EXCLUDED.product_id, created_at = EXCLUDED.created_at
+	`
+	_, err := tx.ExecContext(ctx, query, productID, oldSlug, time.Now().UTC())
+	if err != nil {
+		log.Printf("error while adding product slug history : %v", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM product_slug_history WHERE slug = $1`, newSlug)
+	if err != nil {
+		log.Printf("error while removing the new slug from the slug history : %v", err)
+		return err
+	}
+	return nil
+}
+
+/*
+ResolveSlug:
+- Find the listed product with the given slug, or the product which had the slug before
+- Returns the product id and its current slug, the current slug differs when the product was renamed
+*/
+func (r *productRepository) ResolveSlug(ctx context.Context, slug string) (int64, string, error) {
+	query := `
+		SELECT id, slug FROM (
+			SELECT p.id, p.slug, 0 AS rank
+			FROM products p
+			WHERE p.slug = $1 AND p.is_deleted = false AND p.status = 'active'
+			UNION ALL
+			SELECT p.id, p.slug, 1 AS rank
+			FROM product_slug_history h
+			JOIN products p ON h.product_id = p.id
+			WHERE h.slug = $1 AND p.is_deleted = false AND p.status = 'active'
+		) resolved
+		ORDER BY rank
+		LIMIT 1
+	`
+	var productID int64
+	var currentSlug string
+	err := r.db.QueryRowContext(ctx, query, slug).Scan(&productID, &currentSlug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, "", utils.ErrProductNotFound
+		}
+		log.Printf("error while resolving product slug : %v", err)
+		return 0, "", err
+	}
+	return productID, currentSlug, nil
+}
+
+// addPriceHistoryTx records a price change of the product or of its variant
+func (r *productRepository) addPriceHistoryTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64, oldPrice, newPrice float64, changedBy int64) error {
+	query := `
+		INSERT INTO product_price_history (product_id, variant_id, old_price, new_price, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := tx.ExecContext(ctx, query, productID, variantID, oldPrice, newPrice, changedBy, time.Now().UTC())
+	if err != nil {
+		log.Printf("error while recording product price history : %v", err)
+		return err
+	}
+	return nil
+}
+
+// addStockMovementTx records a change in the stock of the product or of its variant, nothing is recorded when the stock is unchanged
+func (r *productRepository) addStockMovementTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64, oldStock, newStock int, change domain.StockChange) error {
+	if newStock == oldStock {
+		return nil
+	}
+
+	query := `
+		INSERT INTO stock_movements (product_id, variant_id, quantity_change, stock_after, reason,
+			reference_type, reference_id, actor_type, actor_id, location_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := tx.ExecContext(ctx, query, productID, variantID, newStock-oldStock, newStock, change.Reason,
+		change.ReferenceType, change.ReferenceID, change.ActorType, change.ActorID, change.LocationID, time.Now().UTC())
+	if err != nil {
+		log.Printf("error while recording stock movement : %v", err)
+		return err
+	}
+	return nil
+}
+
+/*
+adjustLocationStockTx:
+- Apply the stock change of the product or of its variant to the stock at the location of the change
+- Without a location the primary location is used, it's the active location with the lowest priority
+- Restocking an inactive location restocks the primary location instead, stock of inactive locations can't be sold
+- The location used is set on the change, so that the stock movement records it
+- Called after the product or variant row is updated, the row lock orders the concurrent changes of the same stock
+*/
+func (r *productRepository) adjustLocationStockTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64, quantity int, change *domain.StockChange) error {
+	if quantity == 0 {
+		return nil
+	}
+
+	if change.LocationID == nil || quantity > 0 {
+		var locationID int64
+		err := tx.QueryRowContext(ctx, `
+			SELECT id FROM inventory_locations
+			WHERE is_active = true
+			ORDER BY (id = $1::BIGINT) IS TRUE DESC, priority, id
+			LIMIT 1`, change.LocationID).Scan(&locationID)
+		if err == sql.ErrNoRows {
+			return utils.ErrNoActiveLocation
+		}
+		if err != nil {
+			log.Printf("error while retrieving the location of the stock change : %v", err)
+			return err
+		}
+		change.LocationID = &locationID
+	}
+
+	if quantity > 0 {
+		query := `
+			INSERT INTO location_stock (location_id, product_id, variant_id, stock_quantity, updated_at)
+			VALUES ($1, $2, $3, $4, NOW())
+			ON CONFLICT (location_id, product_id, (COALESCE(variant_id, 0))) DO UPDATE
+			SET stock_quantity = location_stock.stock_quantity + EXCLUDED.stock_quantity,
+				updated_at = NOW()
+		`
+		_, err := tx.ExecContext(ctx, query, *change.LocationID, productID, variantID, quantity)
+		if err != nil {
+			log.Printf("error while adding stock at the location : %v", err)
+			return err
+		}
+		return nil
 	}
 
+	query := `
+		UPDATE location_stock
+		SET stock_quantity = stock_quantity + $1, updated_at = NOW()
+		WHERE location_id = $2 AND product_id = $3 AND COALESCE(variant_id, 0) = COALESCE($4::BIGINT, 0)
+		  AND stock_quantity + $1 >= 0
+	`
+	result, err := tx.ExecContext(ctx, query, quantity, *change.LocationID, productID, variantID)
+	if err != nil {
+		log.Printf("error while removing stock from the location : %v", err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("error while checking the stock removed from the location : %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		return utils.ErrInsufficientLocationStock
+	}
 	return nil
 }
 
+/*
+clearLocationStockTx:
+- Remove the stock of the product, or of its variant, from every location holding it
+- Each location is recorded as a stock movement of the item, starting from the given stock of the item
+- Returns the stock of the item left after the removal
+*/
+func (r *productRepository) clearLocationStockTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64, stock int, change domain.StockChange) (int, error) {
+	query := `
+		DELETE FROM location_stock
+		WHERE product_id = $1 AND COALESCE(variant_id, 0) = COALESCE($2::BIGINT, 0)
+		RETURNING location_id, stock_quantity
+	`
+	rows, err := tx.QueryContext(ctx, query, productID, variantID)
+	if err != nil {
+		log.Printf("error while removing the stock from the locations : %v", err)
+		return 0, err
+	}
+	defer rows.Close()
+
+	var removed []*domain.LocationStock
+	for rows.Next() {
+		var ls domain.LocationStock
+		if err := rows.Scan(&ls.LocationID, &ls.StockQuantity); err != nil {
+			log.Printf("error while scanning the stock removed from the location : %v", err)
+			return 0, err
+		}
+		removed = append(removed, &ls)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, ls := range removed {
+		locationChange := change
+		locationChange.LocationID = &ls.LocationID
+		err = r.addStockMovementTx(ctx, tx, productID, variantID, stock, stock-ls.StockQuantity, locationChange)
+		if err != nil {
+			return 0, err
+		}
+		stock -= ls.StockQuantity
+	}
+
+	return stock, nil
+}
+
+// adminStockChange is the stock change made by the admin from the admin panel
+func adminStockChange(reason string, adminID int64) domain.StockChange {
+	return domain.StockChange{
+		Reason:        reason,
+		ReferenceType: utils.StockReferenceAdmin,
+		ActorType:     utils.StockActorAdmin,
+		ActorID:       &adminID,
+	}
+}
+
+// GetPriceHistory retrieves the price changes of the product and its variants, latest first
+func (r *productRepository) GetPriceHistory(ctx context.Context, productID int64) ([]*domain.ProductPriceHistory, error) {
+	query := `
+		SELECT id, product_id, variant_id, old_price, new_price, changed_by, changed_at
+		FROM product_price_history
+		WHERE product_id = $1
+		ORDER BY changed_at DESC, id DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		log.Printf("error while retrieving product price history : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []*domain.ProductPriceHistory{}
+	for rows.Next() {
+		var h domain.ProductPriceHistory
+		err := rows.Scan(&h.ID, &h.ProductID, &h.VariantID, &h.OldPrice, &h.NewPrice, &h.ChangedBy, &h.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, &h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
 func (r *productRepository) SoftDelete(ctx context.Context, id int64) error {
 	query := `UPDATE products SET deleted_at = $1, is_deleted = true WHERE id = $2 AND is_deleted = false`
 
@@ -187,7 +525,11 @@ func (r *productRepository) NameExistsBeforeUpdate(ctx context.Context, name str
 	return exists, err
 }
 
-func (r *productRepository) AddImage(ctx context.Context, productID int64, imageURL string, isPrimary bool) error {
+// productImageColumns are the columns of a product image, the images uploaded before the renditions fall back to the original
+const productImageColumns = `id, product_id, image_url, COALESCE(thumbnail_url, image_url), COALESCE(medium_url, image_url),
+		alt_text, position, is_primary, created_at`
+
+func (r *productRepository) AddImage(ctx context.Context, image *domain.ProductImage) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		log.Printf("failed to begin transaction : %v", err)
@@ -195,23 +537,32 @@ func (r *productRepository) AddImage(ctx context.Context, productID int64, image
 	}
 	defer tx.Rollback()
 
-	// If this is a primary image, update all other images to non-primary
-	if isPrimary {
-		_, err = tx.ExecContext(ctx, "UPDATE product_images SET is_primary = false WHERE product_id = $1", productID)
+	// A primary image goes to the front of the gallery, the rest of the images become non-primary.
+	// Other images are added at the end of the gallery
+	image.Position = 0
+	if image.IsPrimary {
+		_, err = tx.ExecContext(ctx, "UPDATE product_images SET is_primary = false, position = position + 1 WHERE product_id = $1", image.ProductID)
 		if err != nil {
 			log.Printf("failed to update primary image status of rest of the images : %v", err)
 			return err
 		}
+	} else {
+		err = tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(position) + 1, 0) FROM product_images WHERE product_id = $1", image.ProductID).
+			Scan(&image.Position)
+		if err != nil {
+			log.Printf("failed to get the position of the new image : %v", err)
+			return err
+		}
 	}
 	// Insert the new image
 	query := `
-		INSERT INTO product_images (product_id, image_url, is_primary)
-		VALUES ($1, $2, $3)
-		RETURNING id
+		INSERT INTO product_images (product_id, image_url, thumbnail_url, medium_url, alt_text, position, is_primary)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
 	`
 
-	var imageID int64
-	err = tx.QueryRowContext(ctx, query, productID, imageURL, isPrimary).Scan(&imageID)
+	err = tx.QueryRowContext(ctx, query, image.ProductID, image.ImageURL, image.ThumbnailURL, image.MediumURL,
+		image.AltText, image.Position, image.IsPrimary).Scan(&image.ID, &image.CreatedAt)
 	if err != nil {
 		pqErr, ok := err.(*pq.Error)
 		if ok && pqErr.Code == "23505" { // Unique violation
@@ -222,8 +573,8 @@ func (r *productRepository) AddImage(ctx context.Context, productID int64, image
 	}
 
 	// If this is a primary image, update the product's primary_image_id
-	if isPrimary {
-		_, err = tx.ExecContext(ctx, "UPDATE products SET primary_image_id = $1 WHERE id = $2", imageID, productID)
+	if image.IsPrimary {
+		_, err = tx.ExecContext(ctx, "UPDATE products SET primary_image_id = $1 WHERE id = $2", image.ID, image.ProductID)
 		if err != nil {
 			log.Printf("failed to update the product's primary_image_id : %v", err)
 			return err
@@ -252,13 +603,14 @@ func (r *productRepository) DeleteImage(ctx context.Context, productID int64, im
 }
 func (r *productRepository) GetImageByURL(ctx context.Context, productID int64, imageURL string) (*domain.ProductImage, error) {
 	query := `
-		SELECT id, product_id, image_url, is_primary, created_at
+		SELECT ` + productImageColumns + `
 		FROM product_images
 		WHERE product_id = $1 AND image_url = $2
 	`
 	var image domain.ProductImage
 	err := r.db.QueryRowContext(ctx, query, productID, imageURL).Scan(
-		&image.ID, &image.ProductID, &image.ImageURL, &image.IsPrimary, &image.CreatedAt,
+		&image.ID, &image.ProductID, &image.ImageURL, &image.ThumbnailURL, &image.MediumURL,
+		&image.AltText, &image.Position, &image.IsPrimary, &image.CreatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -271,10 +623,10 @@ func (r *productRepository) GetImageByURL(ctx context.Context, productID int64,
 
 func (r *productRepository) GetProductImages(ctx context.Context, productID int64) ([]*domain.ProductImage, error) {
 	query := `
-		SELECT id, product_id, image_url, is_primary, created_at
+		SELECT ` + productImageColumns + `
 		FROM product_images
 		WHERE product_id = $1
-		ORDER BY created_at ASC
+		ORDER BY position, id
 	`
 	rows, err := r.db.QueryContext(ctx, query, productID)
 	if err != nil {
@@ -286,7 +638,8 @@ func (r *productRepository) GetProductImages(ctx context.Context, productID int6
 	var images []*domain.ProductImage
 	for rows.Next() {
 		var image domain.ProductImage
-		err := rows.Scan(&image.ID, &image.ProductID, &image.ImageURL, &image.IsPrimary, &image.CreatedAt)
+		err := rows.Scan(&image.ID, &image.ProductID, &image.ImageURL, &image.ThumbnailURL, &image.MediumURL,
+			&image.AltText, &image.Position, &image.IsPrimary, &image.CreatedAt)
 		if err != nil {
 			log.Printf("failed to parse image details to struct : %v", err)
 			return nil, err
@@ -300,31 +653,6 @@ func (r *productRepository) GetProductImages(ctx context.Context, productID int6
 	return images, nil
 }
 
-func (r *productRepository) SetImageAsPrimary(ctx context.Context, productID int64, imageID int64) error {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		log.Printf("failed to begin transaction : %v", err)
-		return err
-	}
-	defer tx.Rollback()
-
-	// Set all images for this product as non-primary
-	_, err = tx.ExecContext(ctx, "UPDATE product_images SET is_primary = false WHERE product_id = $1", productID)
-	if err != nil {
-		log.Printf("failed to update primary status of rest of the images : %v", err)
-		return err
-	}
-
-	// Set the specified image as primary
-	_, err = tx.ExecContext(ctx, "UPDATE product_images SET is_primary = true WHERE id = $1 AND product_id = $2", imageID, productID)
-	if err != nil {
-		log.Printf("failed to set the specified image as primary : %v", err)
-		return err
-	}
-
-	return tx.Commit()
-}
-
 func (r *productRepository) UpdateProductPrimaryImage(ctx context.Context, productID int64, imageID *int64) error {
 	var err error
 	if imageID == nil {
@@ -337,13 +665,14 @@ func (r *productRepository) UpdateProductPrimaryImage(ctx context.Context, produ
 
 func (r *productRepository) GetPrimaryImage(ctx context.Context, productID int64) (*domain.ProductImage, error) {
 	query := `
-		SELECT id, product_id, image_url, is_primary, created_at
+		SELECT ` + productImageColumns + `
 		FROM product_images
 		WHERE product_id = $1 AND is_primary = true
 	`
 	var img domain.ProductImage
 	err := r.db.QueryRowContext(ctx, query, productID).Scan(
-		&img.ID, &img.ProductID, &img.ImageURL, &img.IsPrimary, &img.CreatedAt,
+		&img.ID, &img.ProductID, &img.ImageURL, &img.ThumbnailURL, &img.MediumURL,
+		&img.AltText, &img.Position, &img.IsPrimary, &img.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil // No primary image found
@@ -362,9 +691,10 @@ func (r *productRepository) UpdateImagePrimary(ctx context.Context, imageID int6
 }
 
 func (r *productRepository) GetImageByID(ctx context.Context, imageID int64) (*domain.ProductImage, error) {
-	query := `SELECT id,product_id,image_url,is_primary FROM product_images WHERE id= $1`
+	query := `SELECT ` + productImageColumns + ` FROM product_images WHERE id= $1`
 	var image domain.ProductImage
-	err := r.db.QueryRowContext(ctx, query, imageID).Scan(&image.ID, &image.ProductID, &image.ImageURL, &image.IsPrimary)
+	err := r.db.QueryRowContext(ctx, query, imageID).Scan(&image.ID, &image.ProductID, &image.ImageURL, &image.ThumbnailURL, &image.MediumURL,
+		&image.AltText, &image.Position, &image.IsPrimary, &image.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, utils.ErrImageNotFound
@@ -435,9 +765,54 @@ func (r *productRepository) DeleteImageByID(ctx context.Context, imageID int64)
 	return nil
 }
 
+/*
+ReorderImages:
+- Images take their position from the order of the list, in a single transaction
+- First image of the list becomes the primary image of the product
+- Alt text is only updated for the entries which have one
+*/
+func (r *productRepository) ReorderImages(ctx context.Context, productID int64, order []domain.ProductImageOrder) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE product_images
+		SET position = $1, is_primary = $2, alt_text = COALESCE($3, alt_text)
+		WHERE id = $4 AND product_id = $5
+	`
+	for i, entry := range order {
+		result, err := tx.ExecContext(ctx, query, i, i == 0, entry.AltText, entry.ID, productID)
+		if err != nil {
+			log.Printf("error while updating the position of product image : %v", err)
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return utils.ErrImageNotFound
+		}
+	}
+
+	if len(order) > 0 {
+		_, err = tx.ExecContext(ctx, "UPDATE products SET primary_image_id = $1 WHERE id = $2", order[0].ID, productID)
+		if err != nil {
+			log.Printf("failed to update the product's primary_image_id : %v", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (r *productRepository) GetAll(ctx context.Context) ([]*domain.Product, error) {
 	query := `
-		SELECT id, name, description, price, stock_quantity, sub_category_id, created_at, updated_at, slug, is_deleted
+		SELECT id, name, description, price, stock_quantity, sub_category_id, brand_id, status, publish_at, created_at, updated_at, slug, is_deleted
 		FROM products
 		WHERE is_deleted = false
 		ORDER BY id
@@ -455,7 +830,7 @@ func (r *productRepository) GetAll(ctx context.Context) ([]*domain.Product, erro
 		var p domain.Product
 		err := rows.Scan(
 			&p.ID, &p.Name, &p.Description, &p.Price, &p.StockQuantity,
-			&p.SubCategoryID, &p.CreatedAt, &p.UpdatedAt, &p.Slug, &p.IsDeleted,
+			&p.SubCategoryID, &p.BrandID, &p.Status, &p.PublishAt, &p.CreatedAt, &p.UpdatedAt, &p.Slug, &p.IsDeleted,
 		)
 		if err != nil {
 			log.Printf("error while getting product data : %v", err)
@@ -472,48 +847,286 @@ func (r *productRepository) GetAll(ctx context.Context) ([]*domain.Product, erro
 	return products, nil
 }
 
-func (r *productRepository) UpdateStockQuantity(ctx context.Context, productID int64, quantity int) error {
-	query := `UPDATE products SET stock_quantity = $1, updated_at = NOW() WHERE id = $2 AND is_deleted = false`
-	result, err := r.db.ExecContext(ctx, query, quantity, productID)
+// PublishScheduledProducts makes the scheduled products whose publish time has come active, returns the number of products published
+func (r *productRepository) PublishScheduledProducts(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE products
+		SET status = 'active', updated_at = NOW()
+		WHERE status = 'scheduled' AND publish_at <= NOW() AND is_deleted = false
+	`
+	result, err := r.db.ExecContext(ctx, query)
 	if err != nil {
-		return err
+		log.Printf("error while publishing scheduled products : %v", err)
+		return 0, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return result.RowsAffected()
+}
+
+/*
+UpdateStockQuantity:
+- Set stock_quantity of the product
+- The change is recorded as a stock adjustment made by the admin
+- The difference is added to or removed from the stock at the primary location
+*/
+func (r *productRepository) UpdateStockQuantity(ctx context.Context, productID int64, quantity int, changedBy int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	if rowsAffected == 0 {
-		return utils.ErrProductNotFound
+	var oldStock int
+	err = tx.QueryRowContext(ctx, `SELECT stock_quantity FROM products WHERE id = $1 AND is_deleted = false FOR UPDATE`, productID).
+		Scan(&oldStock)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrProductNotFound
+		}
+		log.Printf("error while retrieving current product stock : %v", err)
+		return err
 	}
 
-	return nil
-}
+	query := `UPDATE products SET stock_quantity = $1, updated_at = NOW() WHERE id = $2`
+	_, err = tx.ExecContext(ctx, query, quantity, productID)
+	if err != nil {
+		log.Printf("error while updating product stock quantity : %v", err)
+		return err
+	}
 
-func (r *productRepository) GetProducts(ctx context.Context, params domain.ProductQueryParams) ([]*domain.Product, int64, error) {
-	query := `
-        SELECT p.id, p.name, p.slug, p.description, p.price, p.stock_quantity, p.sub_category_id,
-               p.created_at, p.updated_at, p.deleted_at, p.primary_image_id, p.is_deleted,
-               c.name AS category_name, sc.name AS subcategory_name
+	change := adminStockChange(utils.StockMovementReasonAdjustment, changedBy)
+	err = r.adjustLocationStockTx(ctx, tx, productID, nil, quantity-oldStock, &change)
+	if err != nil {
+		return err
+	}
+
+	err = r.addStockMovementTx(ctx, tx, productID, nil, oldStock, quantity, change)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// searchSimilarityThreshold is the minimum trigram word similarity for a search term to match a product,
+// low enough to tolerate a couple of typos in a word ("madird" matches "madrid")
+const searchSimilarityThreshold = 0.4
+
+// bestOfferJoin finds the listed price of the product, the lowest price among its variants when it has them
+// (a variant without its own price is sold at the product price), and the biggest discount amount on the
+// listed price among the running offers of the product and of the categories it is under, at any level of the category tree
+const bestOfferJoin = `
+        LEFT JOIN LATERAL (
+            SELECT COALESCE(MIN(CASE WHEN pv.price > 0 THEN pv.price ELSE p.price END), p.price) AS price
+            FROM product_variants pv
+            WHERE pv.product_id = p.id AND pv.is_deleted = false
+        ) lp ON true
+        LEFT JOIN LATERAL (
+            SELECT MAX(CASE WHEN o.discount_type = 'percentage' THEN lp.price * o.discount_value / 100
+                            ELSE LEAST(o.discount_value, lp.price) END) AS amount
+            FROM offers o
+            WHERE o.is_active = true AND NOW() BETWEEN o.starts_at AND o.ends_at
+              AND (o.product_id = p.id OR sc.path LIKE '%/' || COALESCE(o.sub_category_id, o.category_id) || '/%')
+        ) bo ON true
+`
+
+// offerPriceExpr is the listed price after the best offer
+const offerPriceExpr = "ROUND(lp.price - COALESCE(bo.amount, 0), 2)"
+
+const discountPercentageExpr = "CASE WHEN lp.price > 0 THEN ROUND(COALESCE(bo.amount, 0) * 100 / lp.price, 2) ELSE 0 END"
+
+// thumbnailURLExpr is the thumbnail of the primary image, the images uploaded before the renditions don't have one
+const thumbnailURLExpr = "COALESCE(pi.thumbnail_url, pi.image_url, '')"
+
+// productListFrom is the source of the public product listing, only active products are listed.
+// The filter conditions are appended to it
+const productListFrom = `
         FROM products p
         JOIN sub_categories sc ON p.sub_category_id = sc.id
         JOIN categories c ON sc.parent_category_id = c.id
-        WHERE p.is_deleted = false
+        LEFT JOIN brands b ON p.brand_id = b.id AND b.is_deleted = false
+        LEFT JOIN product_images pi ON p.primary_image_id = pi.id
+    ` + bestOfferJoin + `
+        WHERE p.is_deleted = false AND p.status = 'active'
     `
 
-	countQuery := `
-        SELECT COUNT(*)
-        FROM products p
-        JOIN sub_categories sc ON p.sub_category_id = sc.id
-        JOIN categories c ON sc.parent_category_id = c.id
-        WHERE p.is_deleted = false
+// productListColumns are the columns of a product listing row, read with scanProductListRow
+const productListColumns = `
+        p.id, p.name, p.slug, p.description, p.price, p.stock_quantity, p.sub_category_id, b.id,
+        p.created_at, p.updated_at, p.deleted_at, p.primary_image_id, p.is_deleted,
+        p.average_rating, p.rating_count,
+        ` + offerPriceExpr + ` AS offer_price,
+        ` + discountPercentageExpr + ` AS discount_percentage,
+        ` + thumbnailURLExpr + ` AS thumbnail_url,
+        c.name AS category_name, sc.name AS subcategory_name
     `
 
-	var conditions []string
-	var args []interface{}
+// scanProductListRow reads a row selected with productListColumns
+func scanProductListRow(rows *sql.Rows) (*domain.Product, error) {
+	var p domain.Product
+	var categoryName, subcategoryName string
+	err := rows.Scan(
+		&p.ID, &p.Name, &p.Slug, &p.Description, &p.Price, &p.StockQuantity, &p.SubCategoryID, &p.BrandID,
+		&p.CreatedAt, &p.UpdatedAt, &p.DeletedAt, &p.PrimaryImageID, &p.IsDeleted,
+		&p.AverageRating, &p.RatingCount,
+		&p.OfferPrice, &p.DiscountPercentage, &p.ThumbnailURL,
+		&categoryName, &subcategoryName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// categoryTreeCondition matches the products under the categories whose name matches the given condition
+const categoryTreeCondition = `EXISTS (
+			SELECT 1 FROM categories fc
+			WHERE LOWER(fc.name) %s AND fc.is_deleted = false AND sc.path LIKE fc.path || '%%')`
+
+// buildProductConditions builds the filter conditions of the product listing and their arguments.
+// It also returns the relevance expression of the search term, empty when there is no search term.
+func buildProductConditions(params domain.ProductQueryParams) (conditions []string, args []interface{}, rankExpr string) {
+	if params.Search != "" {
+		args = append(args, params.Search)
+		// full text match, or a close enough trigram match so that misspelled terms still find results.
+		// <% uses the trigram index, its threshold is set for the listing transaction (beginListingTx)
+		conditions = append(conditions, fmt.Sprintf(
+			"(p.search_vector @@ plainto_tsquery('english', $%d) OR $%d <%% p.search_text)",
+			len(args), len(args)))
+		rankExpr = fmt.Sprintf("ts_rank(p.search_vector, plainto_tsquery('english', $%d)) + word_similarity($%d, p.search_text)",
+			len(args), len(args))
+	}
+
+	// a category filter matches the products of the category and of all its descendants
+	if len(params.Categories) > 0 {
+		args = append(args, pq.Array(params.Categories))
+		conditions = append(conditions, fmt.Sprintf(categoryTreeCondition, fmt.Sprintf("= ANY($%d)", len(args))))
+	} else if params.Category != "" {
+		args = append(args, params.Category)
+		conditions = append(conditions, fmt.Sprintf(categoryTreeCondition, fmt.Sprintf("= $%d", len(args))))
+	}
+
+	if params.Subcategory != "" {
+		args = append(args, params.Subcategory)
+		conditions = append(conditions, fmt.Sprintf(categoryTreeCondition, fmt.Sprintf("= $%d AND fc.parent_id IS NOT NULL", len(args))))
+	}
+
+	if params.MinPrice > 0 {
+		args = append(args, params.MinPrice)
+		conditions = append(conditions, fmt.Sprintf("p.price >= $%d", len(args)))
+	}
+
+	if params.MaxPrice > 0 {
+		args = append(args, params.MaxPrice)
+		conditions = append(conditions, fmt.Sprintf("p.price <= $%d", len(args)))
+	}
+
+	if params.InStock {
+		conditions = append(conditions, "p.stock_quantity > 0")
+	}
+
+	if params.CreatedAfter != "" {
+		args = append(args, params.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("p.created_at >= $%d", len(args)))
+	}
+
+	if params.CreatedBefore != "" {
+		args = append(args, params.CreatedBefore)
+		conditions = append(conditions, fmt.Sprintf("p.created_at <= $%d", len(args)))
+	}
+
+	if params.UpdatedAfter != "" {
+		args = append(args, params.UpdatedAfter)
+		conditions = append(conditions, fmt.Sprintf("p.updated_at >= $%d", len(args)))
+	}
+
+	if params.UpdatedBefore != "" {
+		args = append(args, params.UpdatedBefore)
+		conditions = append(conditions, fmt.Sprintf("p.updated_at <= $%d", len(args)))
+	}
+
+	// only the filterable attributes can be used as filters,
+	// keys are sorted to keep the placeholders of the query in a stable order
+	attributeSlugs := make([]string, 0, len(params.Attributes))
+	for slug := range params.Attributes {
+		attributeSlugs = append(attributeSlugs, slug)
+	}
+	slices.Sort(attributeSlugs)
+	for _, slug := range attributeSlugs {
+		args = append(args, slug, params.Attributes[slug])
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM product_attribute_values pav
+			JOIN product_attributes pa ON pa.id = pav.attribute_id
+			WHERE pav.product_id = p.id AND pa.is_filterable = true AND pa.slug = $%d AND LOWER(pav.value) = LOWER($%d))`,
+			len(args)-1, len(args)))
+	}
+
+	if len(params.Brands) > 0 {
+		args = append(args, pq.Array(params.Brands))
+		conditions = append(conditions, fmt.Sprintf("b.slug = ANY($%d)", len(args)))
+	}
+
+	if params.MinRating > 0 {
+		args = append(args, params.MinRating)
+		conditions = append(conditions, fmt.Sprintf("p.average_rating >= $%d", len(args)))
+	}
+
+	if params.MinDiscount > 0 {
+		args = append(args, params.MinDiscount)
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", discountPercentageExpr, len(args)))
+	}
+
+	if params.MaxDiscount > 0 {
+		args = append(args, params.MaxDiscount)
+		conditions = append(conditions, fmt.Sprintf("%s <= $%d", discountPercentageExpr, len(args)))
+	}
+
+	// Size and color filters are applied on the variants of the product,
+	// both have to match the same variant
+	var variantConditions []string
+	if params.Size != "" {
+		args = append(args, params.Size)
+		variantConditions = append(variantConditions, fmt.Sprintf("LOWER(pv.size) = $%d", len(args)))
+	}
+	if params.Color != "" {
+		args = append(args, params.Color)
+		variantConditions = append(variantConditions, fmt.Sprintf("LOWER(pv.color) = $%d", len(args)))
+	}
+	if len(variantConditions) > 0 {
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM product_variants pv
+			WHERE pv.product_id = p.id AND pv.is_deleted = false AND %s)`, strings.Join(variantConditions, " AND ")))
+	}
+
+	return conditions, args, rankExpr
+}
+
+// beginListingTx starts the read only transaction the product listing queries run in.
+// The word similarity threshold of the <% operator is lowered to searchSimilarityThreshold for it
+func (r *productRepository) beginListingTx(ctx context.Context) (*sql.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		log.Printf("error while starting transaction for product listing : %v", err)
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %v", searchSimilarityThreshold))
+	if err != nil {
+		tx.Rollback()
+		log.Printf("error while setting search similarity threshold : %v", err)
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+func (r *productRepository) GetProducts(ctx context.Context, params domain.ProductQueryParams) ([]*domain.Product, int64, error) {
+	query := `SELECT ` + productListColumns + productListFrom
+
+	countQuery := `SELECT COUNT(*) ` + productListFrom
 
-	// ... [Keep the existing condition checks]
+	conditions, args, rankExpr := buildProductConditions(params)
 
 	if len(conditions) > 0 {
 		query += " AND " + strings.Join(conditions, " AND ")
@@ -521,15 +1134,31 @@ func (r *productRepository) GetProducts(ctx context.Context, params domain.Produ
 	}
 
 	// Add sorting
-	if params.Sort != "" {
-		query += fmt.Sprintf(" ORDER BY p.%s %s", params.Sort, params.Order)
+	if params.Sort == "relevance" {
+		if rankExpr != "" {
+			query += fmt.Sprintf(" ORDER BY %s DESC, p.id", rankExpr)
+		} else {
+			query += " ORDER BY p.created_at DESC" // nothing to rank without a search term
+		}
+	} else if params.Sort != "" {
+		sortColumn := params.Sort
+		if sortColumn == "rating" {
+			sortColumn = "average_rating"
+		}
+		query += fmt.Sprintf(" ORDER BY p.%s %s, p.id", sortColumn, params.Order)
 	} else {
 		query += " ORDER BY p.created_at DESC" // Default sorting
 	}
 
+	tx, err := r.beginListingTx(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
 	// Count total before applying pagination
 	var totalCount int64
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
+	err = tx.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -539,7 +1168,7 @@ func (r *productRepository) GetProducts(ctx context.Context, params domain.Produ
 	args = append(args, params.Limit, (params.Page-1)*params.Limit)
 
 	// Execute main query
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -547,41 +1176,380 @@ func (r *productRepository) GetProducts(ctx context.Context, params domain.Produ
 
 	var products []*domain.Product
 	for rows.Next() {
-		var p domain.Product
-		var categoryName, subcategoryName string
-		err := rows.Scan(
-			&p.ID, &p.Name, &p.Slug, &p.Description, &p.Price, &p.StockQuantity, &p.SubCategoryID,
-			&p.CreatedAt, &p.UpdatedAt, &p.DeletedAt, &p.PrimaryImageID, &p.IsDeleted,
-			&categoryName, &subcategoryName,
-		)
+		p, err := scanProductListRow(rows)
 		if err != nil {
 			return nil, 0, err
 		}
-		products = append(products, &p)
+		products = append(products, p)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, 0, err
 	}
 
+	if err = tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+
 	return products, totalCount, nil
 }
 
+/*
+GetProductsByCursor:
+- Same filters and columns as GetProducts, but paged on the product id (newest first) instead of an offset
+- Sorting options don't apply (the usecase refuses them), and the total count is not computed
+*/
+func (r *productRepository) GetProductsByCursor(ctx context.Context, params domain.ProductQueryParams, page pagination.Params) ([]*domain.Product, pagination.Page, error) {
+	query := `SELECT ` + productListColumns + productListFrom
+
+	conditions, args, _ := buildProductConditions(params)
+
+	condition, cursorArgs, orderBy := page.Clause("", "p.id", len(args)+1)
+	if condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+	}
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+	query += orderBy
+
+	tx, err := r.beginListingTx(ctx)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while retrieving products page : %v", err)
+		return nil, pagination.Page{}, err
+	}
+	defer rows.Close()
+
+	var products []*domain.Product
+	for rows.Next() {
+		p, err := scanProductListRow(rows)
+		if err != nil {
+			return nil, pagination.Page{}, err
+		}
+		products = append(products, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	hasMore := len(products) > page.Limit
+	if hasMore {
+		products = products[:page.Limit]
+	}
+	if page.IsPrev() {
+		slices.Reverse(products)
+	}
+
+	var first, last *pagination.Cursor
+	if len(products) > 0 {
+		first = &pagination.Cursor{ID: products[0].ID}
+		last = &pagination.Cursor{ID: products[len(products)-1].ID}
+	}
+
+	return products, pagination.NewPage(page, hasMore, first, last), nil
+}
+
+/*
+BulkUpsert:
+- All the given products are inserted or updated (matched on slug) in a single transaction
+- Stock of products having variants is not overwritten, it is the sum of their variant stocks
+- Soft deleted products are not updated, their ID is left as 0
+- Price changes of the updated products are recorded in the price history
+- Stock changes of the products are recorded as stock movements of the import
+- Returns whether each product was newly inserted
+*/
+func (r *productRepository) BulkUpsert(ctx context.Context, products []*domain.Product, changedBy int64) ([]bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("error while starting transaction for product import : %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// previous holds the price and stock of the existing product, locked till the end of the import
+	query := `
+		WITH previous AS (
+			SELECT price, stock_quantity FROM products WHERE slug = $2 AND is_deleted = false FOR UPDATE
+		)
+		INSERT INTO products (name, slug, description, price, stock_quantity, sub_category_id, brand_id, created_at, updated_at, is_deleted)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, false)
+		ON CONFLICT (slug) DO UPDATE SET
+			name = EXCLUDED.name,
+			description = EXCLUDED.description,
+			price = EXCLUDED.price,
+			stock_quantity = CASE
+				WHEN EXISTS (SELECT 1 FROM product_variants pv WHERE pv.product_id = products.id AND pv.is_deleted = false)
+				THEN products.stock_quantity
+				ELSE EXCLUDED.stock_quantity
+			END,
+			sub_category_id = EXCLUDED.sub_category_id,
+			brand_id = EXCLUDED.brand_id,
+			updated_at = EXCLUDED.updated_at
+		WHERE products.is_deleted = false
+		RETURNING id, (xmax = 0) AS inserted, price, (SELECT price FROM previous),
+			stock_quantity, (SELECT stock_quantity FROM previous)
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("error while preparing product upsert statement : %v", err)
+		return nil, err
+	}
+	defer stmt.Close()
+
+	inserted := make([]bool, len(products))
+	for i, product := range products {
+		var newPrice float64
+		var oldPrice sql.NullFloat64
+		var newStock int
+		var oldStock sql.NullInt64
+		err := stmt.QueryRowContext(ctx,
+			product.Name,
+			product.Slug,
+			product.Description,
+			product.Price,
+			product.StockQuantity,
+			product.SubCategoryID,
+			product.BrandID,
+			product.CreatedAt,
+			product.UpdatedAt,
+		).Scan(&product.ID, &inserted[i], &newPrice, &oldPrice, &newStock, &oldStock)
+		if err == sql.ErrNoRows {
+			continue // slug belongs to a soft deleted product
+		}
+		if err != nil {
+			log.Printf("error while upserting product %s : %v", product.Slug, err)
+			return nil, err
+		}
+
+		if !inserted[i] && oldPrice.Valid && oldPrice.Float64 != newPrice {
+			err = r.addPriceHistoryTx(ctx, tx, product.ID, nil, oldPrice.Float64, newPrice, changedBy)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// oldStock is NULL for a new product, so its whole stock is recorded
+		change := adminStockChange(utils.StockMovementReasonImport, changedBy)
+		err = r.adjustLocationStockTx(ctx, tx, product.ID, nil, newStock-int(oldStock.Int64), &change)
+		if err != nil {
+			return nil, err
+		}
+
+		err = r.addStockMovementTx(ctx, tx, product.ID, nil, int(oldStock.Int64), newStock, change)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("error while committing product import : %v", err)
+		return nil, err
+	}
+
+	return inserted, nil
+}
+
+// GetCatalog retrieves the products that are not soft deleted, in the import file format
+func (r *productRepository) GetCatalog(ctx context.Context) ([]*domain.ProductCatalogRow, error) {
+	query := `
+		SELECT p.name, COALESCE(p.description, ''), p.price, p.stock_quantity, sc.slug, COALESCE(b.slug, '')
+		FROM products p
+		JOIN sub_categories sc ON p.sub_category_id = sc.id
+		LEFT JOIN brands b ON p.brand_id = b.id AND b.is_deleted = false
+		WHERE p.is_deleted = false
+		ORDER BY p.id
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("error while retrieving product catalog : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var catalog []*domain.ProductCatalogRow
+	for rows.Next() {
+		var row domain.ProductCatalogRow
+		err := rows.Scan(&row.Name, &row.Description, &row.Price, &row.StockQuantity, &row.SubCategorySlug, &row.BrandSlug)
+		if err != nil {
+			return nil, err
+		}
+		catalog = append(catalog, &row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return catalog, nil
+}
+
+// productPriceBuckets are the lower bounds of the price bands of the price facet, the last band is open ended
+var productPriceBuckets = []float64{0, 500, 1000, 2500, 5000, 10000}
+
+/*
+GetProductFacets:
+- Each facet is counted with all the filters of the query except its own
+- So the other options of a facet remain visible along with their counts
+- Facets : category, sub category, brand, price band and stock state
+*/
+func (r *productRepository) GetProductFacets(ctx context.Context, params domain.ProductQueryParams) (*domain.ProductFacets, error) {
+	facets := &domain.ProductFacets{}
+
+	tx, err := r.beginListingTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	categoryParams := params
+	categoryParams.Category, categoryParams.Categories = "", nil
+	facets.Categories, err = r.getFacetCounts(ctx, tx, categoryParams, "c.name", "c.name")
+	if err != nil {
+		return nil, err
+	}
+
+	subCategoryParams := params
+	subCategoryParams.Subcategory = ""
+	facets.Subcategories, err = r.getFacetCounts(ctx, tx, subCategoryParams, "sc.name", "sc.name")
+	if err != nil {
+		return nil, err
+	}
+
+	brandParams := params
+	brandParams.Brand, brandParams.Brands = "", nil
+	facets.Brands, err = r.getFacetCounts(ctx, tx, brandParams, "b.slug", "b.name")
+	if err != nil {
+		return nil, err
+	}
+
+	priceParams := params
+	priceParams.MinPrice, priceParams.MaxPrice = 0, 0
+	facets.PriceRanges, err = r.getPriceFacetCounts(ctx, tx, priceParams)
+	if err != nil {
+		return nil, err
+	}
+
+	stockParams := params
+	stockParams.InStock = false
+	conditions, args, _ := buildProductConditions(stockParams)
+	query := `SELECT COUNT(*) FILTER (WHERE p.stock_quantity > 0), COUNT(*) FILTER (WHERE p.stock_quantity <= 0) ` + productListFrom
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&facets.Stock.InStock, &facets.Stock.OutOfStock)
+	if err != nil {
+		log.Printf("error while retrieving stock facet counts : %v", err)
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+// getFacetCounts counts the matching products grouped by the given value column
+func (r *productRepository) getFacetCounts(ctx context.Context, tx *sql.Tx, params domain.ProductQueryParams, valueColumn, labelColumn string) ([]domain.FacetCount, error) {
+	conditions, args, _ := buildProductConditions(params)
+	conditions = append(conditions, valueColumn+" IS NOT NULL")
+
+	query := fmt.Sprintf(`SELECT %s, %s, COUNT(*) %s AND %s GROUP BY %s, %s ORDER BY COUNT(*) DESC, %s`,
+		valueColumn, labelColumn, productListFrom, strings.Join(conditions, " AND "), valueColumn, labelColumn, labelColumn)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while retrieving facet counts of %s : %v", valueColumn, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []domain.FacetCount{}
+	for rows.Next() {
+		var fc domain.FacetCount
+		err := rows.Scan(&fc.Value, &fc.Label, &fc.Count)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, fc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// getPriceFacetCounts counts the matching products in each price band
+func (r *productRepository) getPriceFacetCounts(ctx context.Context, tx *sql.Tx, params domain.ProductQueryParams) ([]domain.PriceRangeCount, error) {
+	conditions, args, _ := buildProductConditions(params)
+
+	var columns []string
+	ranges := make([]domain.PriceRangeCount, len(productPriceBuckets))
+	for i, min := range productPriceBuckets {
+		ranges[i].Min = min
+		if i+1 < len(productPriceBuckets) {
+			max := productPriceBuckets[i+1]
+			ranges[i].Max = &max
+			columns = append(columns, fmt.Sprintf("COUNT(*) FILTER (WHERE p.price >= %v AND p.price < %v)", min, max))
+		} else {
+			columns = append(columns, fmt.Sprintf("COUNT(*) FILTER (WHERE p.price >= %v)", min))
+		}
+	}
+
+	query := "SELECT " + strings.Join(columns, ", ") + productListFrom
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	dest := make([]interface{}, len(ranges))
+	for i := range ranges {
+		dest[i] = &ranges[i].Count
+	}
+	err := tx.QueryRowContext(ctx, query, args...).Scan(dest...)
+	if err != nil {
+		log.Printf("error while retrieving price facet counts : %v", err)
+		return nil, err
+	}
+
+	return ranges, nil
+}
+
 func (r *productRepository) GetPublicProductByID(ctx context.Context, id int64) (*domain.PublicProduct, error) {
 	query := `
-        SELECT p.id, p.name, p.slug, p.description, p.price, p.stock_quantity, 
-               p.created_at, p.updated_at, c.name as category_name, sc.name as subcategory_name
+        SELECT p.id, p.name, p.slug, p.description, p.price,
+               p.stock_quantity - COALESCE((SELECT SUM(r.quantity) FROM stock_reservations r
+                                            WHERE r.product_id = p.id AND ` + activeReservationFilter + `), 0),
+               p.created_at, p.updated_at, c.name as category_name, sc.name as subcategory_name,
+               p.average_rating, p.rating_count, b.id, b.name, b.slug
         FROM products p
         JOIN sub_categories sc ON p.sub_category_id = sc.id
         JOIN categories c ON sc.parent_category_id = c.id
-        WHERE p.id = $1 AND p.is_deleted = false
+        LEFT JOIN brands b ON p.brand_id = b.id AND b.is_deleted = false
+        WHERE p.id = $1 AND p.is_deleted = false AND p.status = 'active'
     `
 
 	var product domain.PublicProduct
+	var brandName, brandSlug sql.NullString
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&product.ID, &product.Name, &product.Slug, &product.Description,
 		&product.Price, &product.StockQuantity, &product.CreatedAt,
 		&product.UpdatedAt, &product.CategoryName, &product.SubcategoryName,
+		&product.AverageRating, &product.RatingCount,
+		&product.BrandID, &brandName, &brandSlug,
 	)
 
 	if err != nil {
@@ -591,12 +1559,15 @@ func (r *productRepository) GetPublicProductByID(ctx context.Context, id int64)
 		return nil, err
 	}
 
+	product.BrandName = brandName.String
+	product.BrandSlug = brandSlug.String
+
 	// Fetch product images
 	imagesQuery := `
-        SELECT image_url
+        SELECT image_url, COALESCE(thumbnail_url, image_url), COALESCE(medium_url, image_url), alt_text, position
         FROM product_images
         WHERE product_id = $1
-        ORDER BY is_primary DESC, created_at ASC
+        ORDER BY position, id
     `
 	rows, err := r.db.QueryContext(ctx, imagesQuery, id)
 	if err != nil {
@@ -604,35 +1575,691 @@ func (r *productRepository) GetPublicProductByID(ctx context.Context, id int64)
 	}
 	defer rows.Close()
 
-	var images []string
+	images := []*domain.PublicProductImage{}
 	for rows.Next() {
-		var imageURL string
-		if err := rows.Scan(&imageURL); err != nil {
+		var image domain.PublicProductImage
+		if err := rows.Scan(&image.URL, &image.ThumbnailURL, &image.MediumURL, &image.AltText, &image.Position); err != nil {
 			return nil, err
 		}
-		images = append(images, imageURL)
+		images = append(images, &image)
 	}
-
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
 	product.Images = images
 
+	product.Attributes, err = getProductAttributeValues(ctx, r.db, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &product, nil
 }
 
 /*
 UpdateStockTx:
+- Every change is recorded as a stock movement with the reason, reference and actor of the change
+- Update stock_quantity in product_variants table, if the stock change is for a variant
 - Update stock_quantity in products table
+- The change is applied to the stock at the location of the change, or at the primary location
+  - For a product with variants, stock_quantity is kept as the sum of its variants' stock
+  - A decrement only happens when there is enough stock, so concurrent orders can't drive the stock negative
+  - Returns an InsufficientStockError with the stock left when the decrement is refused
 */
-func (r *productRepository) UpdateStockTx(ctx context.Context, tx *sql.Tx, productID int64, quantity int) error {
+func (r *productRepository) UpdateStockTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64, quantity int, change domain.StockChange) error {
+	if variantID != nil {
+		err := r.lockProductTx(ctx, tx, productID)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE product_variants
+			SET stock_quantity = stock_quantity + $1,
+				updated_at = NOW()
+			WHERE id = $2 AND product_id = $3 AND stock_quantity + $1 >= 0
+			RETURNING stock_quantity
+		`
+		var newStock int
+		err = tx.QueryRowContext(ctx, query, quantity, *variantID, productID).Scan(&newStock)
+		if err == sql.ErrNoRows {
+			return r.stockShortageTx(ctx, tx, productID, variantID, quantity)
+		}
+		if err != nil {
+			log.Printf("error while updating stock_quantity in product_variants table : %v", err)
+			return err
+		}
+
+		err = r.adjustLocationStockTx(ctx, tx, productID, variantID, quantity, &change)
+		if err != nil {
+			return err
+		}
+
+		err = r.addStockMovementTx(ctx, tx, productID, variantID, newStock-quantity, newStock, change)
+		if err != nil {
+			return err
+		}
+		return r.syncProductStockTx(ctx, tx, productID, change)
+	}
+
 	query := `
         UPDATE products
         SET stock_quantity = stock_quantity + $1,
             updated_at = NOW()
-        WHERE id = $2
+        WHERE id = $2 AND stock_quantity + $1 >= 0
+        RETURNING stock_quantity
     `
-	_, err := tx.ExecContext(ctx, query, quantity, productID)
+	var newStock int
+	err := tx.QueryRowContext(ctx, query, quantity, productID).Scan(&newStock)
+	if err == sql.ErrNoRows {
+		return r.stockShortageTx(ctx, tx, productID, nil, quantity)
+	}
 	if err != nil {
 		log.Printf("error while updating stock_quantity in products table : %v", err)
 		return err
 	}
+
+	err = r.adjustLocationStockTx(ctx, tx, productID, nil, quantity, &change)
+	if err != nil {
+		return err
+	}
+	return r.addStockMovementTx(ctx, tx, productID, nil, newStock-quantity, newStock, change)
+}
+
+// lockProductTx locks the product row till the end of the transaction.
+// Every stock change locks the product before its variant, so that concurrent changes can't deadlock on the two rows
+func (r *productRepository) lockProductTx(ctx context.Context, tx *sql.Tx, productID int64) error {
+	_, err := tx.ExecContext(ctx, `SELECT 1 FROM products WHERE id = $1 FOR UPDATE`, productID)
+	if err != nil {
+		log.Printf("error while locking the product : %v", err)
+	}
+	return err
+}
+
+// lockVariantProductTx locks the row of the product the variant belongs to, see lockProductTx
+func (r *productRepository) lockVariantProductTx(ctx context.Context, tx *sql.Tx, variantID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		SELECT 1 FROM products
+		WHERE id = (SELECT product_id FROM product_variants WHERE id = $1)
+		FOR UPDATE`, variantID)
+	if err != nil {
+		log.Printf("error while locking the product of the variant : %v", err)
+	}
+	return err
+}
+
+/*
+stockShortageTx:
+- Called when the stock update of UpdateStockTx didn't match any row
+- The row doesn't exist anymore, nothing to update, same as before the decrement was made conditional
+- Otherwise the decrement was refused, the shortage is returned with the stock left
+*/
+func (r *productRepository) stockShortageTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64, quantity int) error {
+	var name string
+	var stock int
+	var err error
+	if variantID != nil {
+		err = tx.QueryRowContext(ctx, `
+			SELECT p.name, pv.stock_quantity
+			FROM product_variants pv
+			JOIN products p ON pv.product_id = p.id
+			WHERE pv.id = $1 AND pv.product_id = $2`, *variantID, productID).Scan(&name, &stock)
+	} else {
+		err = tx.QueryRowContext(ctx, `SELECT name, stock_quantity FROM products WHERE id = $1`, productID).Scan(&name, &stock)
+	}
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		log.Printf("error while retrieving the stock left : %v", err)
+		return err
+	}
+
+	return &utils.InsufficientStockError{Items: []utils.StockShortage{{
+		ProductID:   productID,
+		ProductName: name,
+		VariantID:   variantID,
+		Requested:   -quantity,
+		Available:   stock,
+	}}}
+}
+
+/*
+syncProductStockTx:
+- Set stock_quantity of the product as the sum of stock_quantity of its active variants
+- Used after every change in variant stock, so that product level stock stays valid for listing and filtering
+- The change in product stock is recorded as a stock movement of the product, with the same reason as the variant change
+- Stock of the product itself at the locations is removed, the stock of a product with variants is held by its variants
+- Each location the stock of the product is removed from is recorded as a stock movement of the product
+*/
+func (r *productRepository) syncProductStockTx(ctx context.Context, tx *sql.Tx, productID int64, change domain.StockChange) error {
+	var oldStock int
+	err := tx.QueryRowContext(ctx, `SELECT stock_quantity FROM products WHERE id = $1 FOR UPDATE`, productID).Scan(&oldStock)
+	if err != nil {
+		log.Printf("error while retrieving current product stock : %v", err)
+		return err
+	}
+
+	stock, err := r.clearLocationStockTx(ctx, tx, productID, nil, oldStock, change)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE products
+		SET stock_quantity = (
+				SELECT COALESCE(SUM(stock_quantity), 0)
+				FROM product_variants
+				WHERE product_id = $1 AND is_deleted = false
+			),
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING stock_quantity
+	`
+	var newStock int
+	err = tx.QueryRowContext(ctx, query, productID).Scan(&newStock)
+	if err != nil {
+		log.Printf("error while syncing product stock with variant stock : %v", err)
+		return err
+	}
+
+	return r.addStockMovementTx(ctx, tx, productID, nil, stock, newStock, change)
+}
+
+/*
+CreateVariant:
+- Add variant entry in product_variants table
+- Update product stock with the new variant stock
+- Initial stock of the variant is recorded as its first stock movement, the stock is held at the primary location
+*/
+func (r *productRepository) CreateVariant(ctx context.Context, variant *domain.ProductVariant, createdBy int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = r.lockProductTx(ctx, tx, variant.ProductID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO product_variants (product_id, sku, size, color, price, stock_quantity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	err = tx.QueryRowContext(ctx, query,
+		variant.ProductID,
+		variant.SKU,
+		variant.Size,
+		variant.Color,
+		variant.Price,
+		variant.StockQuantity,
+		variant.CreatedAt,
+		variant.UpdatedAt).Scan(&variant.ID)
+	if err != nil {
+		if dupErr := mapVariantUniqueViolation(err); dupErr != nil {
+			return dupErr
+		}
+		log.Printf("error while adding variant entry in product_variants table : %v", err)
+		return err
+	}
+
+	change := adminStockChange(utils.StockMovementReasonInitialStock, createdBy)
+	err = r.adjustLocationStockTx(ctx, tx, variant.ProductID, &variant.ID, variant.StockQuantity, &change)
+	if err != nil {
+		return err
+	}
+
+	err = r.addStockMovementTx(ctx, tx, variant.ProductID, &variant.ID, 0, variant.StockQuantity, change)
+	if err != nil {
+		return err
+	}
+
+	err = r.syncProductStockTx(ctx, tx, variant.ProductID, change)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+/*
+mapVariantUniqueViolation:
+- Converts unique violations on product_variants table to the respective errors
+*/
+func mapVariantUniqueViolation(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code != "23505" {
+		return nil
+	}
+	if pqErr.Constraint == "idx_product_variants_sku" {
+		return utils.ErrDuplicateVariantSKU
+	}
+	return utils.ErrDuplicateVariant
+}
+
+/*
+GetVariantByID:
+- Get variant details from product_variants table, soft deleted variants are excluded
+*/
+func (r *productRepository) GetVariantByID(ctx context.Context, variantID int64) (*domain.ProductVariant, error) {
+	query := `
+		SELECT id, product_id, sku, size, color, price, stock_quantity, created_at, updated_at, deleted_at, is_deleted
+		FROM product_variants
+		WHERE id = $1 AND is_deleted = false
+	`
+	var v domain.ProductVariant
+	err := r.db.QueryRowContext(ctx, query, variantID).Scan(
+		&v.ID, &v.ProductID, &v.SKU, &v.Size, &v.Color, &v.Price, &v.StockQuantity,
+		&v.CreatedAt, &v.UpdatedAt, &v.DeletedAt, &v.IsDeleted,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrVariantNotFound
+		}
+		log.Printf("error while retrieving variant details using variant id : %v", err)
+		return nil, err
+	}
+	return &v, nil
+}
+
+/*
+GetVariantsByProductID:
+- Get all active variants of the given product
+*/
+func (r *productRepository) GetVariantsByProductID(ctx context.Context, productID int64) ([]*domain.ProductVariant, error) {
+	query := `
+		SELECT pv.id, pv.product_id, pv.sku, pv.size, pv.color, pv.price, pv.stock_quantity,
+		       COALESCE((SELECT SUM(r.quantity) FROM stock_reservations r
+		                 WHERE r.variant_id = pv.id AND ` + activeReservationFilter + `), 0),
+		       pv.created_at, pv.updated_at, pv.deleted_at, pv.is_deleted
+		FROM product_variants pv
+		WHERE pv.product_id = $1 AND pv.is_deleted = false
+		ORDER BY pv.id ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		log.Printf("error while retrieving variants of the product : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []*domain.ProductVariant
+	for rows.Next() {
+		var v domain.ProductVariant
+		err := rows.Scan(
+			&v.ID, &v.ProductID, &v.SKU, &v.Size, &v.Color, &v.Price, &v.StockQuantity, &v.ReservedQuantity,
+			&v.CreatedAt, &v.UpdatedAt, &v.DeletedAt, &v.IsDeleted,
+		)
+		if err != nil {
+			log.Printf("error while scanning variant details : %v", err)
+			return nil, err
+		}
+		variants = append(variants, &v)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("database error : %v", err)
+		return nil, err
+	}
+	return variants, nil
+}
+
+/*
+UpdateVariant:
+- Update variant details in product_variants table
+- Update product stock with the updated variant stock
+*/
+func (r *productRepository) UpdateVariant(ctx context.Context, variant *domain.ProductVariant, changedBy int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = r.lockVariantProductTx(ctx, tx, variant.ID)
+	if err != nil {
+		return err
+	}
+
+	var oldPrice float64
+	var oldStock int
+	err = tx.QueryRowContext(ctx, `SELECT price, stock_quantity FROM product_variants WHERE id = $1 AND is_deleted = false FOR UPDATE`, variant.ID).
+		Scan(&oldPrice, &oldStock)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrVariantNotFound
+		}
+		log.Printf("error while retrieving current variant price : %v", err)
+		return err
+	}
+
+	query := `
+		UPDATE product_variants
+		SET sku = $1, size = $2, color = $3, price = $4, stock_quantity = $5, updated_at = NOW()
+		WHERE id = $6 AND is_deleted = false
+		RETURNING price, stock_quantity
+	`
+	var newPrice float64
+	var newStock int
+	err = tx.QueryRowContext(ctx, query,
+		variant.SKU,
+		variant.Size,
+		variant.Color,
+		variant.Price,
+		variant.StockQuantity,
+		variant.ID).Scan(&newPrice, &newStock)
+	if err != nil {
+		if dupErr := mapVariantUniqueViolation(err); dupErr != nil {
+			return dupErr
+		}
+		log.Printf("error while updating variant details : %v", err)
+		return err
+	}
+
+	if newPrice != oldPrice {
+		err = r.addPriceHistoryTx(ctx, tx, variant.ProductID, &variant.ID, oldPrice, newPrice, changedBy)
+		if err != nil {
+			return err
+		}
+	}
+
+	change := adminStockChange(utils.StockMovementReasonAdjustment, changedBy)
+	err = r.adjustLocationStockTx(ctx, tx, variant.ProductID, &variant.ID, newStock-oldStock, &change)
+	if err != nil {
+		return err
+	}
+
+	err = r.addStockMovementTx(ctx, tx, variant.ProductID, &variant.ID, oldStock, newStock, change)
+	if err != nil {
+		return err
+	}
+
+	err = r.syncProductStockTx(ctx, tx, variant.ProductID, change)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+/*
+SoftDeleteVariant:
+- Soft delete the variant, stock of the variant is removed from the product stock
+- Stock of the variant is removed from every location holding it, each location is recorded as a stock movement of the variant
+- The variant is removed from the carts, it can't be checked out anymore
+*/
+func (r *productRepository) SoftDeleteVariant(ctx context.Context, variantID int64, deletedBy int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = r.lockVariantProductTx(ctx, tx, variantID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE product_variants
+		SET is_deleted = true, deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND is_deleted = false
+		RETURNING product_id, stock_quantity
+	`
+	var productID int64
+	var stock int
+	err = tx.QueryRowContext(ctx, query, variantID).Scan(&productID, &stock)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrVariantNotFound
+		}
+		log.Printf("error while soft deleting variant : %v", err)
+		return err
+	}
+
+	change := adminStockChange(utils.StockMovementReasonVariantDeleted, deletedBy)
+	stock, err = r.clearLocationStockTx(ctx, tx, productID, &variantID, stock, change)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE product_variants SET stock_quantity = 0 WHERE id = $1`, variantID)
+	if err != nil {
+		log.Printf("error while removing the stock of the deleted variant : %v", err)
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM cart_items WHERE variant_id = $1`, variantID)
+	if err != nil {
+		log.Printf("error while removing the deleted variant from the carts : %v", err)
+		return err
+	}
+
+	// stock of the variant which wasn't held at any location
+	err = r.addStockMovementTx(ctx, tx, productID, &variantID, stock, 0, change)
+	if err != nil {
+		return err
+	}
+
+	err = r.syncProductStockTx(ctx, tx, productID, change)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *productRepository) HasVariants(ctx context.Context, productID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM product_variants WHERE product_id = $1 AND is_deleted = false)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, productID).Scan(&exists)
+	if err != nil {
+		log.Printf("error while checking if product has variants : %v", err)
+	}
+	return exists, err
+}
+
+/*
+UpdateVariantStockQuantity:
+- Set stock_quantity of the variant
+- Update product stock with the updated variant stock
+- The change is recorded as a stock adjustment made by the admin
+- The difference is added to or removed from the stock at the primary location
+*/
+func (r *productRepository) UpdateVariantStockQuantity(ctx context.Context, variantID int64, quantity int, changedBy int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = r.lockVariantProductTx(ctx, tx, variantID)
+	if err != nil {
+		return err
+	}
+
+	var productID int64
+	var oldStock int
+	err = tx.QueryRowContext(ctx, `SELECT product_id, stock_quantity FROM product_variants WHERE id = $1 AND is_deleted = false FOR UPDATE`, variantID).
+		Scan(&productID, &oldStock)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrVariantNotFound
+		}
+		log.Printf("error while retrieving current variant stock : %v", err)
+		return err
+	}
+
+	query := `
+		UPDATE product_variants
+		SET stock_quantity = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+	_, err = tx.ExecContext(ctx, query, quantity, variantID)
+	if err != nil {
+		log.Printf("error while updating variant stock quantity : %v", err)
+		return err
+	}
+
+	change := adminStockChange(utils.StockMovementReasonAdjustment, changedBy)
+	err = r.adjustLocationStockTx(ctx, tx, productID, &variantID, quantity-oldStock, &change)
+	if err != nil {
+		return err
+	}
+
+	err = r.addStockMovementTx(ctx, tx, productID, &variantID, oldStock, quantity, change)
+	if err != nil {
+		return err
+	}
+
+	err = r.syncProductStockTx(ctx, tx, productID, change)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetDeleted retrieves the soft deleted products, most recently deleted first
+func (r *productRepository) GetDeleted(ctx context.Context) ([]*domain.Product, error) {
+	query := `
+		SELECT id, name, slug, description, price, stock_quantity, sub_category_id, brand_id, status, publish_at,
+		       created_at, updated_at, deleted_at, is_deleted
+		FROM products
+		WHERE is_deleted = true
+		ORDER BY deleted_at DESC, id DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("error while retrieving deleted products : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []*domain.Product{}
+	for rows.Next() {
+		var p domain.Product
+		err := rows.Scan(
+			&p.ID, &p.Name, &p.Slug, &p.Description, &p.Price, &p.StockQuantity, &p.SubCategoryID, &p.BrandID,
+			&p.Status, &p.PublishAt, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt, &p.IsDeleted,
+		)
+		if err != nil {
+			log.Printf("error while scanning deleted product : %v", err)
+			return nil, err
+		}
+		products = append(products, &p)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("database error : %v", err)
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// GetDeletedByID retrieves a soft deleted product, a product which is not deleted is not found
+func (r *productRepository) GetDeletedByID(ctx context.Context, id int64) (*domain.Product, error) {
+	query := `
+		SELECT id, name, slug, description, price, stock_quantity, sub_category_id, brand_id, status, publish_at,
+		       created_at, updated_at, deleted_at, is_deleted
+		FROM products
+		WHERE id = $1 AND is_deleted = true
+	`
+	var p domain.Product
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&p.ID, &p.Name, &p.Slug, &p.Description, &p.Price, &p.StockQuantity, &p.SubCategoryID, &p.BrandID,
+		&p.Status, &p.PublishAt, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt, &p.IsDeleted,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrProductNotFound
+		}
+		log.Printf("error while retrieving deleted product : %v", err)
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Restore brings a soft deleted product back
+func (r *productRepository) Restore(ctx context.Context, id int64) error {
+	query := `UPDATE products SET deleted_at = NULL, is_deleted = false, deleted_by_category_id = NULL, updated_at = NOW() WHERE id = $1 AND is_deleted = true`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("error while restoring product : %v", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return utils.ErrProductNotFound
+	}
 	return nil
 }
+
+/*
+PurgeDeleted:
+- Permanently delete the products soft deleted before the given time
+- Products which were ordered are kept, the order history refers to them
+- Returns the number of products purged and the urls of their images, so that the stored files can be removed
+*/
+func (r *productRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, []string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin transaction : %v", err)
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	purgeable := `
+		SELECT p.id FROM products p
+		WHERE p.is_deleted = true AND p.deleted_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM order_items oi WHERE oi.product_id = p.id)
+	`
+
+	// images are removed by the cascade, their urls are collected before
+	rows, err := tx.QueryContext(ctx, `SELECT image_url FROM product_images WHERE product_id IN (`+purgeable+`)`, before)
+	if err != nil {
+		log.Printf("error while retrieving images of the purged products : %v", err)
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	imageURLs := []string{}
+	for rows.Next() {
+		var imageURL string
+		if err := rows.Scan(&imageURL); err != nil {
+			return 0, nil, err
+		}
+		imageURLs = append(imageURLs, imageURL)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM products WHERE id IN (`+purgeable+`)`, before)
+	if err != nil {
+		log.Printf("error while purging deleted products : %v", err)
+		return 0, nil, err
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("failed to commit transaction : %v", err)
+		return 0, nil, err
+	}
+
+	return purged, imageURLs, nil
+}
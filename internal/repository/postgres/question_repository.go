@@ -0,0 +1,327 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type questionRepository struct {
+	db *sql.DB
+}
+
+func NewQuestionRepository(db *sql.DB) *questionRepository {
+	return &questionRepository{db: db}
+}
+
+const answerColumns = `
+		SELECT pa.id, pa.question_id, pa.user_id, pa.admin_id, COALESCE(u.name, ''), pa.answer,
+		       pa.is_verified_buyer, pa.upvote_count, pa.status, pa.created_at, pa.updated_at
+		FROM product_answers pa
+		LEFT JOIN users u ON pa.user_id = u.id
+	`
+
+func (r *questionRepository) CreateQuestion(ctx context.Context, question *domain.ProductQuestion) error {
+	query := `
+		INSERT INTO product_questions (product_id, user_id, question, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		question.ProductID,
+		question.UserID,
+		question.Question,
+		question.Status,
+		question.CreatedAt,
+		question.UpdatedAt,
+	).Scan(&question.ID)
+	if err != nil {
+		log.Printf("error while creating product question : %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *questionRepository) GetQuestionByID(ctx context.Context, questionID int64) (*domain.ProductQuestion, error) {
+	query := `
+		SELECT pq.id, pq.product_id, pq.user_id, u.name, pq.question, pq.status, pq.created_at, pq.updated_at
+		FROM product_questions pq
+		JOIN users u ON pq.user_id = u.id
+		WHERE pq.id = $1
+	`
+	var question domain.ProductQuestion
+	err := r.db.QueryRowContext(ctx, query, questionID).Scan(
+		&question.ID, &question.ProductID, &question.UserID, &question.UserName,
+		&question.Question, &question.Status, &question.CreatedAt, &question.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrQuestionNotFound
+		}
+		log.Printf("error while retrieving question using ID : %v", err)
+		return nil, err
+	}
+	return &question, nil
+}
+
+/*
+GetQuestions:
+- Filter by product id and status if given
+- Latest questions are listed first
+*/
+func (r *questionRepository) GetQuestions(ctx context.Context, params domain.QuestionQueryParams) ([]*domain.ProductQuestion, int64, error) {
+	var conditions []string
+	var args []interface{}
+
+	if params.ProductID != 0 {
+		args = append(args, params.ProductID)
+		conditions = append(conditions, fmt.Sprintf("pq.product_id = $%d", len(args)))
+	}
+	if params.Status != "" {
+		args = append(args, params.Status)
+		conditions = append(conditions, fmt.Sprintf("pq.status = $%d", len(args)))
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var totalCount int64
+	countQuery := "SELECT COUNT(*) FROM product_questions pq" + whereClause
+	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
+	if err != nil {
+		log.Printf("error while counting questions : %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT pq.id, pq.product_id, pq.user_id, u.name, pq.question, pq.status, pq.created_at, pq.updated_at
+		FROM product_questions pq
+		JOIN users u ON pq.user_id = u.id` + whereClause +
+		fmt.Sprintf(" ORDER BY pq.created_at DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, params.Limit, (params.Page-1)*params.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while retrieving questions : %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	questions := []*domain.ProductQuestion{}
+	for rows.Next() {
+		var question domain.ProductQuestion
+		err := rows.Scan(
+			&question.ID, &question.ProductID, &question.UserID, &question.UserName,
+			&question.Question, &question.Status, &question.CreatedAt, &question.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		questions = append(questions, &question)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return questions, totalCount, nil
+}
+
+func (r *questionRepository) UpdateQuestionStatus(ctx context.Context, questionID int64, status string) error {
+	query := `UPDATE product_questions SET status = $1, updated_at = NOW() WHERE id = $2`
+	result, err := r.db.ExecContext(ctx, query, status, questionID)
+	if err != nil {
+		log.Printf("error while updating question status : %v", err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return utils.ErrQuestionNotFound
+	}
+	return nil
+}
+
+func (r *questionRepository) CreateAnswer(ctx context.Context, answer *domain.ProductAnswer) error {
+	query := `
+		INSERT INTO product_answers (question_id, user_id, admin_id, answer, is_verified_buyer, upvote_count, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		answer.QuestionID,
+		answer.UserID,
+		answer.AdminID,
+		answer.Answer,
+		answer.IsVerifiedBuyer,
+		answer.UpvoteCount,
+		answer.Status,
+		answer.CreatedAt,
+		answer.UpdatedAt,
+	).Scan(&answer.ID)
+	if err != nil {
+		log.Printf("error while creating product answer : %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *questionRepository) GetAnswerByID(ctx context.Context, answerID int64) (*domain.ProductAnswer, error) {
+	query := answerColumns + ` WHERE pa.id = $1`
+	var answer domain.ProductAnswer
+	err := r.db.QueryRowContext(ctx, query, answerID).Scan(
+		&answer.ID, &answer.QuestionID, &answer.UserID, &answer.AdminID, &answer.UserName, &answer.Answer,
+		&answer.IsVerifiedBuyer, &answer.UpvoteCount, &answer.Status, &answer.CreatedAt, &answer.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrAnswerNotFound
+		}
+		log.Printf("error while retrieving answer using ID : %v", err)
+		return nil, err
+	}
+	answer.IsAdminAnswer = answer.AdminID != nil
+	return &answer, nil
+}
+
+/*
+GetAnswersByQuestionIDs:
+- Retrieve the answers of all the given questions in a single query
+- Filter by status if given
+- Admin answers come first, then the most upvoted ones
+*/
+func (r *questionRepository) GetAnswersByQuestionIDs(ctx context.Context, questionIDs []int64, status string) ([]*domain.ProductAnswer, error) {
+	query := answerColumns + ` WHERE pa.question_id = ANY($1)`
+	args := []interface{}{pq.Array(questionIDs)}
+	if status != "" {
+		query += ` AND pa.status = $2`
+		args = append(args, status)
+	}
+	query += ` ORDER BY (pa.admin_id IS NOT NULL) DESC, pa.upvote_count DESC, pa.created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while retrieving answers of questions : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var answers []*domain.ProductAnswer
+	for rows.Next() {
+		var answer domain.ProductAnswer
+		err := rows.Scan(
+			&answer.ID, &answer.QuestionID, &answer.UserID, &answer.AdminID, &answer.UserName, &answer.Answer,
+			&answer.IsVerifiedBuyer, &answer.UpvoteCount, &answer.Status, &answer.CreatedAt, &answer.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		answer.IsAdminAnswer = answer.AdminID != nil
+		answers = append(answers, &answer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return answers, nil
+}
+
+func (r *questionRepository) UpdateAnswerStatus(ctx context.Context, answerID int64, status string) error {
+	query := `UPDATE product_answers SET status = $1, updated_at = NOW() WHERE id = $2`
+	result, err := r.db.ExecContext(ctx, query, status, answerID)
+	if err != nil {
+		log.Printf("error while updating answer status : %v", err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return utils.ErrAnswerNotFound
+	}
+	return nil
+}
+
+/*
+AddAnswerUpvote:
+- Record the upvote of the user, a user can upvote an answer only once
+- Increment the upvote count of the answer in the same transaction
+*/
+func (r *questionRepository) AddAnswerUpvote(ctx context.Context, answerID, userID int64) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO product_answer_upvotes (answer_id, user_id) VALUES ($1, $2)`, answerID, userID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return 0, utils.ErrAlreadyUpvoted
+		}
+		log.Printf("error while adding answer upvote : %v", err)
+		return 0, err
+	}
+
+	var upvoteCount int
+	query := `UPDATE product_answers SET upvote_count = upvote_count + 1 WHERE id = $1 RETURNING upvote_count`
+	err = tx.QueryRowContext(ctx, query, answerID).Scan(&upvoteCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, utils.ErrAnswerNotFound
+		}
+		log.Printf("error while updating answer upvote count : %v", err)
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return upvoteCount, nil
+}
+
+func (r *questionRepository) RemoveAnswerUpvote(ctx context.Context, answerID, userID int64) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM product_answer_upvotes WHERE answer_id = $1 AND user_id = $2`, answerID, userID)
+	if err != nil {
+		log.Printf("error while removing answer upvote : %v", err)
+		return 0, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if rowsAffected == 0 {
+		return 0, utils.ErrUpvoteNotFound
+	}
+
+	var upvoteCount int
+	query := `UPDATE product_answers SET upvote_count = GREATEST(upvote_count - 1, 0) WHERE id = $1 RETURNING upvote_count`
+	err = tx.QueryRowContext(ctx, query, answerID).Scan(&upvoteCount)
+	if err != nil {
+		log.Printf("error while updating answer upvote count : %v", err)
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return upvoteCount, nil
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type recommendationRepository struct {
+	db *sql.DB
+}
+
+func NewRecommendationRepository(db *sql.DB) *recommendationRepository {
+	return &recommendationRepository{db: db}
+}
+
+const recommendedProductColumns = `
+        SELECT p.id, p.name, p.slug, p.description, p.price, p.stock_quantity, p.sub_category_id, b.id,
+               p.created_at, p.updated_at, p.primary_image_id, p.average_rating, p.rating_count,
+               ` + offerPriceExpr + ` AS offer_price,
+               ` + discountPercentageExpr + ` AS discount_percentage,
+               ` + thumbnailURLExpr + ` AS thumbnail_url
+    `
+
+/*
+RefreshProductAffinities:
+- Rebuild the affinity table from the completed orders in a single transaction
+- Two products are related when they were bought in the same order
+- Score of a pair is the number of completed orders that contain both of them
+*/
+func (r *recommendationRepository) RefreshProductAffinities(ctx context.Context) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("error while starting transaction : %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM product_affinities`)
+	if err != nil {
+		log.Printf("error while clearing product affinities : %v", err)
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO product_affinities (product_id, related_product_id, score, computed_at)
+		SELECT a.product_id, b.product_id, COUNT(DISTINCT a.order_id), NOW()
+		FROM order_items a
+		JOIN order_items b ON a.order_id = b.order_id AND a.product_id <> b.product_id
+		JOIN orders o ON a.order_id = o.id
+		WHERE o.order_status = 'completed'
+		GROUP BY a.product_id, b.product_id
+	`
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		log.Printf("error while computing product affinities : %v", err)
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("error while committing transaction : %v", err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+/*
+GetRelatedProducts:
+- Products bought together with the given product come first, ordered by the affinity score
+- Products of the same sub category fill the rest, so new products still get recommendations
+*/
+func (r *recommendationRepository) GetRelatedProducts(ctx context.Context, productID int64, limit int) ([]*domain.Product, error) {
+	query := recommendedProductColumns + productListFrom + `
+		AND p.id <> $1
+		AND (
+			EXISTS (SELECT 1 FROM product_affinities pa WHERE pa.product_id = $1 AND pa.related_product_id = p.id)
+			OR p.sub_category_id = (SELECT sub_category_id FROM products WHERE id = $1)
+		)
+		ORDER BY COALESCE((SELECT pa.score FROM product_affinities pa
+			WHERE pa.product_id = $1 AND pa.related_product_id = p.id), 0) DESC,
+			p.average_rating DESC, p.id DESC
+		LIMIT $2
+	`
+	products, err := r.queryProducts(ctx, query, productID, limit)
+	if err != nil {
+		log.Printf("error while retrieving related products : %v", err)
+		return nil, err
+	}
+	return products, nil
+}
+
+/*
+GetCartRecommendations:
+- Score of a product is the sum of its affinities with the products in the user's cart
+- Products already in the cart are not recommended
+- Products of the sub categories in the cart fill the rest
+*/
+func (r *recommendationRepository) GetCartRecommendations(ctx context.Context, userID int64, limit int) ([]*domain.Product, error) {
+	query := `
+		WITH cart AS (
+			SELECT DISTINCT ci.product_id, cp.sub_category_id
+			FROM cart_items ci
+			JOIN products cp ON ci.product_id = cp.id
+			WHERE ci.user_id = $1
+		)
+	` + recommendedProductColumns + productListFrom + `
+		AND p.id NOT IN (SELECT product_id FROM cart)
+		AND (
+			EXISTS (SELECT 1 FROM product_affinities pa
+				WHERE pa.product_id IN (SELECT product_id FROM cart) AND pa.related_product_id = p.id)
+			OR p.sub_category_id IN (SELECT sub_category_id FROM cart)
+		)
+		ORDER BY COALESCE((SELECT SUM(pa.score) FROM product_affinities pa
+			WHERE pa.product_id IN (SELECT product_id FROM cart) AND pa.related_product_id = p.id), 0) DESC,
+			p.average_rating DESC, p.id DESC
+		LIMIT $2
+	`
+	products, err := r.queryProducts(ctx, query, userID, limit)
+	if err != nil {
+		log.Printf("error while retrieving cart recommendations : %v", err)
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *recommendationRepository) queryProducts(ctx context.Context, query string, args ...interface{}) ([]*domain.Product, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []*domain.Product{}
+	for rows.Next() {
+		var p domain.Product
+		err := rows.Scan(
+			&p.ID, &p.Name, &p.Slug, &p.Description, &p.Price, &p.StockQuantity, &p.SubCategoryID, &p.BrandID,
+			&p.CreatedAt, &p.UpdatedAt, &p.PrimaryImageID, &p.AverageRating, &p.RatingCount,
+			&p.OfferPrice, &p.DiscountPercentage, &p.ThumbnailURL,
+		)
+		if err != nil {
+			return nil, err
+		}
+		p.Status = utils.ProductStatusActive
+		products = append(products, &p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type reviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *reviewRepository {
+	return &reviewRepository{db: db}
+}
+
+func (r *reviewRepository) Create(ctx context.Context, review *domain.ProductReview) error {
+	query := `
+		INSERT INTO product_reviews (product_id, user_id, rating, comment, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		review.ProductID,
+		review.UserID,
+		review.Rating,
+		review.Comment,
+		review.Status,
+		review.CreatedAt,
+		review.UpdatedAt,
+	).Scan(&review.ID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return utils.ErrDuplicateReview
+		}
+		log.Printf("error while creating product review : %v", err)
+		return err
+	}
+	return nil
+}
+
+func (r *reviewRepository) GetByID(ctx context.Context, reviewID int64) (*domain.ProductReview, error) {
+	query := `
+		SELECT pr.id, pr.product_id, pr.user_id, u.name, pr.rating, pr.comment, pr.status, pr.created_at, pr.updated_at
+		FROM product_reviews pr
+		JOIN users u ON pr.user_id = u.id
+		WHERE pr.id = $1
+	`
+	var review domain.ProductReview
+	err := r.db.QueryRowContext(ctx, query, reviewID).Scan(
+		&review.ID, &review.ProductID, &review.UserID, &review.UserName,
+		&review.Rating, &review.Comment, &review.Status, &review.CreatedAt, &review.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrReviewNotFound
+		}
+		log.Printf("error while retrieving review using ID : %v", err)
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewRepository) ExistsForUserProduct(ctx context.Context, userID, productID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM product_reviews WHERE user_id = $1 AND product_id = $2)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, userID, productID).Scan(&exists)
+	if err != nil {
+		log.Printf("error while checking if the user already reviewed the product : %v", err)
+		return false, err
+	}
+	return exists, nil
+}
+
+/*
+GetReviews:
+- Filter by product id and status if given
+- Latest reviews are listed first
+*/
+func (r *reviewRepository) GetReviews(ctx context.Context, params domain.ReviewQueryParams) ([]*domain.ProductReview, int64, error) {
+	var conditions []string
+	var args []interface{}
+
+	if params.ProductID != 0 {
+		args = append(args, params.ProductID)
+		conditions = append(conditions, fmt.Sprintf("pr.product_id = $%d", len(args)))
+	}
+	if params.Status != "" {
+		args = append(args, params.Status)
+		conditions = append(conditions, fmt.Sprintf("pr.status = $%d", len(args)))
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var totalCount int64
+	countQuery := "SELECT COUNT(*) FROM product_reviews pr" + whereClause
+	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
+	if err != nil {
+		log.Printf("error while counting reviews : %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT pr.id, pr.product_id, pr.user_id, u.name, pr.rating, pr.comment, pr.status, pr.created_at, pr.updated_at
+		FROM product_reviews pr
+		JOIN users u ON pr.user_id = u.id` + whereClause +
+		fmt.Sprintf(" ORDER BY pr.created_at DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, params.Limit, (params.Page-1)*params.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("error while retrieving reviews : %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var reviews []*domain.ProductReview
+	for rows.Next() {
+		var review domain.ProductReview
+		err := rows.Scan(
+			&review.ID, &review.ProductID, &review.UserID, &review.UserName,
+			&review.Rating, &review.Comment, &review.Status, &review.CreatedAt, &review.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		reviews = append(reviews, &review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, totalCount, nil
+}
+
+/*
+UpdateStatus:
+- Update the status of the review
+- Recalculate the average rating and rating count of the product using approved reviews
+*/
+func (r *reviewRepository) UpdateStatus(ctx context.Context, reviewID int64, status string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var productID int64
+	query := `
+		UPDATE product_reviews
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING product_id
+	`
+	err = tx.QueryRowContext(ctx, query, status, reviewID).Scan(&productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrReviewNotFound
+		}
+		log.Printf("error while updating review status : %v", err)
+		return err
+	}
+
+	query = `
+		UPDATE products
+		SET average_rating = COALESCE(s.average_rating, 0), rating_count = s.rating_count
+		FROM (
+			SELECT ROUND(AVG(rating)::numeric, 2) AS average_rating, COUNT(*) AS rating_count
+			FROM product_reviews
+			WHERE product_id = $1 AND status = $2
+		) s
+		WHERE products.id = $1
+	`
+	_, err = tx.ExecContext(ctx, query, productID, utils.ReviewStatusApproved)
+	if err != nil {
+		log.Printf("error while updating product rating : %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,306 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type stockReservationRepository struct {
+	db *sql.DB
+}
+
+func NewStockReservationRepository(db *sql.DB) *stockReservationRepository {
+	return &stockReservationRepository{db: db}
+}
+
+// activeReservationFilter matches the reservations of stock_reservations r which still hold the stock
+const activeReservationFilter = `r.status = 'active' AND r.expires_at > NOW()`
+
+// Reserve holds the stock of the cart items for the checkout, replacing the earlier reservations of the checkout
+func (r *stockReservationRepository) Reserve(ctx context.Context, checkoutID, userID int64, items []*domain.CartItem, expiresAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("error while starting transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = r.reserveTx(ctx, tx, checkoutID, nil, userID, items, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetReservedQuantity gets the quantity of the product or the variant held by the active reservations of the other checkouts
+func (r *stockReservationRepository) GetReservedQuantity(ctx context.Context, productID int64, variantID *int64, excludeCheckoutID int64) (int, error) {
+	query := `SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r WHERE r.product_id = $1 AND r.checkout_id <> $2 AND ` + activeReservationFilter
+	args := []interface{}{productID, excludeCheckoutID}
+	if variantID != nil {
+		query += ` AND r.variant_id = $3`
+		args = append(args, *variantID)
+	}
+
+	var reserved int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&reserved)
+	if err != nil {
+		log.Printf("error while retrieving reserved stock : %v", err)
+		return 0, err
+	}
+	return reserved, nil
+}
+
+// ReserveForOrderTx holds the stock of the cart items for the order being placed from the checkout
+func (r *stockReservationRepository) ReserveForOrderTx(ctx context.Context, tx *sql.Tx, checkoutID, orderID, userID int64, items []*domain.CartItem, expiresAt time.Time) error {
+	return r.reserveTx(ctx, tx, checkoutID, &orderID, userID, items, expiresAt)
+}
+
+/*
+reserveTx:
+- Release the active reservations of the checkout, so that its own reservations don't count against it
+- Lock the stock row of every item, items are locked in the same order to avoid deadlocks between checkouts
+- Available stock is the stock minus the active reservations of the other checkouts
+- Returns an InsufficientStockError listing every item which is not available
+*/
+func (r *stockReservationRepository) reserveTx(ctx context.Context, tx *sql.Tx, checkoutID int64, orderID *int64, userID int64, items []*domain.CartItem, expiresAt time.Time) error {
+	query := `UPDATE stock_reservations SET status = $1, updated_at = NOW() WHERE checkout_id = $2 AND status = $3`
+	_, err := tx.ExecContext(ctx, query, utils.ReservationStatusReleased, checkoutID, utils.ReservationStatusActive)
+	if err != nil {
+		log.Printf("error while releasing reservations of the checkout : %v", err)
+		return err
+	}
+
+	sorted := make([]*domain.CartItem, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].ProductID != sorted[j].ProductID {
+			return sorted[i].ProductID < sorted[j].ProductID
+		}
+		return variantKey(sorted[i].VariantID) < variantKey(sorted[j].VariantID)
+	})
+
+	var shortages []utils.StockShortage
+	for _, item := range sorted {
+		name, available, err := r.lockAvailableStockTx(ctx, tx, item.ProductID, item.VariantID)
+		if err != nil {
+			return err
+		}
+		if available < item.Quantity {
+			shortages = append(shortages, utils.StockShortage{
+				ProductID:   item.ProductID,
+				ProductName: name,
+				VariantID:   item.VariantID,
+				Requested:   item.Quantity,
+				Available:   max(available, 0),
+			})
+			continue
+		}
+
+		query := `
+			INSERT INTO stock_reservations (checkout_id, order_id, user_id, product_id, variant_id, quantity, status, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+		_, err = tx.ExecContext(ctx, query, checkoutID, orderID, userID, item.ProductID, item.VariantID,
+			item.Quantity, utils.ReservationStatusActive, expiresAt)
+		if err != nil {
+			log.Printf("error while creating stock reservation : %v", err)
+			return err
+		}
+	}
+
+	if len(shortages) > 0 {
+		return &utils.InsufficientStockError{Items: shortages}
+	}
+
+	return nil
+}
+
+// lockAvailableStockTx locks the stock row of the product, and then of the variant when it is given,
+// and returns the product name and the stock which is not reserved
+func (r *stockReservationRepository) lockAvailableStockTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64) (string, int, error) {
+	var name string
+	var stock int
+	err := tx.QueryRowContext(ctx, `SELECT name, stock_quantity FROM products WHERE id = $1 FOR UPDATE`, productID).Scan(&name, &stock)
+	if err == sql.ErrNoRows {
+		return "", 0, utils.ErrProductNotFound
+	}
+	if err != nil {
+		log.Printf("error while locking the stock : %v", err)
+		return "", 0, err
+	}
+
+	if variantID != nil {
+		err = tx.QueryRowContext(ctx, `SELECT stock_quantity FROM product_variants WHERE id = $1 AND product_id = $2 FOR UPDATE`,
+			*variantID, productID).Scan(&stock)
+		if err == sql.ErrNoRows {
+			return "", 0, utils.ErrVariantNotFound
+		}
+		if err != nil {
+			log.Printf("error while locking the stock : %v", err)
+			return "", 0, err
+		}
+	}
+
+	var reserved int
+	query := `SELECT COALESCE(SUM(r.quantity), 0) FROM stock_reservations r WHERE r.product_id = $1 AND ` + activeReservationFilter
+	args := []interface{}{productID}
+	if variantID != nil {
+		query += ` AND r.variant_id = $2`
+		args = append(args, *variantID)
+	}
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&reserved)
+	if err != nil {
+		log.Printf("error while retrieving reserved stock : %v", err)
+		return "", 0, err
+	}
+
+	return name, stock - reserved, nil
+}
+
+/*
+LockForOrderTx:
+- Lock the reservations of the order which are not committed yet and return them
+- Returned in product and variant order, so that the stock rows are locked in the same order as reserveTx
+- Reservations released by a failed payment or expired before the payment no longer hold the stock
+- The stock row of such an item is locked and the stock not held by other reservations should still cover it
+- Returns an InsufficientStockError listing every lapsed item which is not available
+- Orders placed before the reservations have no rows, their stock was decremented when they were placed
+*/
+func (r *stockReservationRepository) LockForOrderTx(ctx context.Context, tx *sql.Tx, orderID int64) ([]*domain.StockReservation, error) {
+	query := `
+		SELECT r.id, r.checkout_id, r.order_id, r.user_id, r.product_id, r.variant_id, r.quantity, r.status,
+		       r.expires_at, r.created_at, r.updated_at, (` + activeReservationFilter + `) AS is_active
+		FROM stock_reservations r
+		WHERE r.order_id = $1 AND r.status <> $2
+		ORDER BY r.product_id, COALESCE(r.variant_id, 0)
+		FOR UPDATE
+	`
+	rows, err := tx.QueryContext(ctx, query, orderID, utils.ReservationStatusCommitted)
+	if err != nil {
+		log.Printf("error while locking reservations of the order : %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations, lapsed []*domain.StockReservation
+	for rows.Next() {
+		var res domain.StockReservation
+		var isActive bool
+		err := rows.Scan(&res.ID, &res.CheckoutID, &res.OrderID, &res.UserID, &res.ProductID, &res.VariantID,
+			&res.Quantity, &res.Status, &res.ExpiresAt, &res.CreatedAt, &res.UpdatedAt, &isActive)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, &res)
+		if !isActive {
+			lapsed = append(lapsed, &res)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var shortages []utils.StockShortage
+	for _, res := range lapsed {
+		name, available, err := r.lockAvailableStockTx(ctx, tx, res.ProductID, res.VariantID)
+		if err == utils.ErrProductNotFound || err == utils.ErrVariantNotFound {
+			continue // nothing to decrement for a missing item
+		}
+		if err != nil {
+			return nil, err
+		}
+		if available < res.Quantity {
+			shortages = append(shortages, utils.StockShortage{
+				ProductID:   res.ProductID,
+				ProductName: name,
+				VariantID:   res.VariantID,
+				Requested:   res.Quantity,
+				Available:   max(available, 0),
+			})
+		}
+	}
+
+	if len(shortages) > 0 {
+		return nil, &utils.InsufficientStockError{Items: shortages}
+	}
+
+	return reservations, nil
+}
+
+/*
+RenewForOrderTx:
+- Hold the stock of the order again till the given expiry, when its payment is retried
+- Reservations which lapsed are checked the same way as LockForOrderTx, an InsufficientStockError is returned when the stock is gone
+*/
+func (r *stockReservationRepository) RenewForOrderTx(ctx context.Context, tx *sql.Tx, orderID int64, expiresAt time.Time) error {
+	_, err := r.LockForOrderTx(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE stock_reservations SET status = $1, expires_at = $2, updated_at = NOW() WHERE order_id = $3 AND status <> $4`
+	_, err = tx.ExecContext(ctx, query, utils.ReservationStatusActive, expiresAt, orderID, utils.ReservationStatusCommitted)
+	if err != nil {
+		log.Printf("error while renewing reservations of the order : %v", err)
+		return err
+	}
+	return nil
+}
+
+// CommitForOrderTx marks the reservations locked by LockForOrderTx as committed, once their stock is decremented in the same transaction
+func (r *stockReservationRepository) CommitForOrderTx(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	query := `UPDATE stock_reservations SET status = $1, updated_at = NOW() WHERE order_id = $2 AND status <> $1`
+	_, err := tx.ExecContext(ctx, query, utils.ReservationStatusCommitted, orderID)
+	if err != nil {
+		log.Printf("error while committing reservations of the order : %v", err)
+		return err
+	}
+	return nil
+}
+
+// IsStockCommittedTx reports whether the stock of the order was decremented, it's false while the order only holds reservations
+func (r *stockReservationRepository) IsStockCommittedTx(ctx context.Context, tx *sql.Tx, orderID int64) (bool, error) {
+	query := `SELECT NOT EXISTS(SELECT 1 FROM stock_reservations WHERE order_id = $1 AND status <> $2)`
+	var committed bool
+	err := tx.QueryRowContext(ctx, query, orderID, utils.ReservationStatusCommitted).Scan(&committed)
+	if err != nil {
+		log.Printf("error while checking if the stock of the order is committed : %v", err)
+		return false, err
+	}
+	return committed, nil
+}
+
+func (r *stockReservationRepository) ReleaseForOrderTx(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	query := `UPDATE stock_reservations SET status = $1, updated_at = NOW() WHERE order_id = $2 AND status = $3`
+	_, err := tx.ExecContext(ctx, query, utils.ReservationStatusReleased, orderID, utils.ReservationStatusActive)
+	if err != nil {
+		log.Printf("error while releasing reservations of the order : %v", err)
+		return err
+	}
+	return nil
+}
+
+// ExpireReservations marks the active reservations past their expiry as expired, returns the number of reservations expired
+func (r *stockReservationRepository) ExpireReservations(ctx context.Context) (int64, error) {
+	query := `UPDATE stock_reservations SET status = $1, updated_at = NOW() WHERE status = $2 AND expires_at <= NOW()`
+	result, err := r.db.ExecContext(ctx, query, utils.ReservationStatusExpired, utils.ReservationStatusActive)
+	if err != nil {
+		log.Printf("error while expiring stock reservations : %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func variantKey(variantID *int64) int64 {
+	if variantID == nil {
+		return 0
+	}
+	return *variantID
+}
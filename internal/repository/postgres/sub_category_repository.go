@@ -11,6 +11,8 @@ import (
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 )
 
+// subCategoryRepository works on the categories below the top level of the category tree,
+// ParentCategoryID of a sub category is its parent in the tree
 type subCategoryRepository struct {
 	db *sql.DB
 }
@@ -20,19 +22,15 @@ func NewSubCategoryRepository(db *sql.DB) *subCategoryRepository {
 }
 
 func (r *subCategoryRepository) Create(ctx context.Context, subCategory *domain.SubCategory) error {
-	// Insert the new sub-category
-	query := `INSERT INTO sub_categories (parent_category_id, name, slug, created_at,updated_at, is_deleted) 
-              VALUES ($1, $2, $3, $4,$5, $6) 
-              RETURNING id`
-
-	err := r.db.QueryRowContext(ctx, query,
-		subCategory.ParentCategoryID,
-		subCategory.Name,
-		subCategory.Slug,
-		subCategory.CreatedAt,
-		subCategory.UpdatedAt,
-		false).Scan(&subCategory.ID)
-
+	// Insert the new sub-category as a child of its parent category
+	category := &domain.Category{
+		ParentID:  &subCategory.ParentCategoryID,
+		Name:      subCategory.Name,
+		Slug:      subCategory.Slug,
+		CreatedAt: subCategory.CreatedAt,
+		UpdatedAt: subCategory.UpdatedAt,
+	}
+	err := insertCategory(ctx, r.db, category)
 	if err != nil {
 		log.Printf("Error inserting subcategory: %v", err)
 		pqErr, ok := err.(*pq.Error)
@@ -44,14 +42,16 @@ func (r *subCategoryRepository) Create(ctx context.Context, subCategory *domain.
 		log.Printf("db error : failed to create sub category : %v", err)
 		return err
 	}
+
+	subCategory.ID = category.ID
 	return nil
 }
 
 func (r *subCategoryRepository) GetByCategoryID(ctx context.Context, categoryID int) ([]*domain.SubCategory, error) {
 	query := `
-		SELECT id, parent_category_id, name, slug, created_at, deleted_at, is_deleted
-		FROM sub_categories
-		WHERE parent_category_id = $1 AND is_deleted = FALSE
+		SELECT id, parent_id, name, slug, created_at, deleted_at, is_deleted
+		FROM categories
+		WHERE parent_id = $1 AND is_deleted = FALSE
 		ORDER BY id
 	`
 
@@ -91,9 +91,9 @@ func (r *subCategoryRepository) GetByCategoryID(ctx context.Context, categoryID
 
 func (r *subCategoryRepository) GetByID(ctx context.Context, id int) (*domain.SubCategory, error) {
 	query := `
-		SELECT id, parent_category_id, name, slug, created_at, updated_at, deleted_at, is_deleted
-		FROM sub_categories
-		WHERE id = $1 AND is_deleted = FALSE
+		SELECT id, parent_id, name, slug, created_at, updated_at, deleted_at, is_deleted
+		FROM categories
+		WHERE id = $1 AND parent_id IS NOT NULL AND is_deleted = FALSE
 	`
 
 	var subCategory domain.SubCategory
@@ -118,11 +118,40 @@ func (r *subCategoryRepository) GetByID(ctx context.Context, id int) (*domain.Su
 	return &subCategory, nil
 }
 
+func (r *subCategoryRepository) GetBySlug(ctx context.Context, slug string) (*domain.SubCategory, error) {
+	query := `
+		SELECT id, parent_id, name, slug, created_at, updated_at, deleted_at, is_deleted
+		FROM categories
+		WHERE slug = $1 AND parent_id IS NOT NULL AND is_deleted = FALSE
+	`
+
+	var subCategory domain.SubCategory
+	err := r.db.QueryRowContext(ctx, query, slug).Scan(
+		&subCategory.ID,
+		&subCategory.ParentCategoryID,
+		&subCategory.Name,
+		&subCategory.Slug,
+		&subCategory.CreatedAt,
+		&subCategory.UpdatedAt,
+		&subCategory.DeletedAt,
+		&subCategory.IsDeleted,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrSubCategoryNotFound
+		}
+		log.Printf("Failed to get SubCategory by slug=%s: error=%v", slug, err)
+		return nil, err
+	}
+	return &subCategory, nil
+}
+
 func (r *subCategoryRepository) Update(ctx context.Context, subCategory *domain.SubCategory) error {
 	query := `
-		UPDATE sub_categories
+		UPDATE categories
 		SET name = $1, slug = $2, updated_at = $3
-		WHERE id = $4 AND parent_category_id = $5 AND is_deleted = FALSE
+		WHERE id = $4 AND parent_id = $5 AND is_deleted = FALSE
 		RETURNING updated_at
 	`
 
@@ -144,27 +173,40 @@ func (r *subCategoryRepository) Update(ctx context.Context, subCategory *domain.
 	return nil
 }
 
-func (r *subCategoryRepository) SoftDelete(ctx context.Context, id int) error {
+// SoftDelete deletes the sub category along with the categories under it and their products
+func (r *subCategoryRepository) SoftDelete(ctx context.Context, id int) (*domain.CategoryCascadeResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("error while starting transaction : %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	deletedAt := time.Now().UTC()
+	var path string
 	query := `
-		UPDATE sub_categories
-		SET deleted_at = NOW(), is_deleted = TRUE
-		WHERE id = $1 AND is_deleted = FALSE
+		UPDATE categories
+		SET deleted_at = $1, is_deleted = TRUE, deleted_by_category_id = NULL
+		WHERE id = $2 AND parent_id IS NOT NULL AND is_deleted = FALSE
+		RETURNING path
 	`
-
-	result, err := r.db.ExecContext(ctx, query, id)
+	err = tx.QueryRowContext(ctx, query, deletedAt, id).Scan(&path)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrSubCategoryNotFound
+		}
 		log.Printf("Error soft deleting sub-category: %v", err)
-		return err
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	result, err := cascadeCategorySoftDeleteTx(ctx, tx, id, path, deletedAt)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if rowsAffected == 0 {
-		return utils.ErrSubCategoryNotFound
+	if err = tx.Commit(); err != nil {
+		log.Printf("error while committing transaction : %v", err)
+		return nil, err
 	}
-
-	return nil
+	return result, nil
 }
@@ -5,10 +5,12 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"slices"
 	"time"
 
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/pagination"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 )
 
@@ -158,6 +160,69 @@ func (r *walletRepository) GetTransactions(ctx context.Context, userID int64, pa
 	return transactions, totalCount, nil
 }
 
+/*
+GetTransactionsByCursor:
+- Transactions are paged on (created_at, id) newest first, without counting the total
+- One more transaction than the limit is fetched to know if there is a following page
+*/
+func (r *walletRepository) GetTransactionsByCursor(ctx context.Context, userID int64, transactionType string, params pagination.Params) ([]*domain.WalletTransaction, pagination.Page, error) {
+	query := `
+        SELECT id, user_id, amount, transaction_type, reference_id, reference_type, balance_after, created_at
+        FROM wallet_transactions
+        WHERE user_id = $1
+    `
+	args := []interface{}{userID}
+
+	// Add type filter if provided
+	if transactionType != "" {
+		args = append(args, transactionType)
+		query += fmt.Sprintf(` AND transaction_type = $%d`, len(args))
+	}
+
+	condition, cursorArgs, orderBy := params.Clause("created_at", "id", len(args)+1)
+	if condition != "" {
+		query += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+	query += orderBy
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	defer rows.Close()
+
+	var transactions []*domain.WalletTransaction
+	for rows.Next() {
+		var t domain.WalletTransaction
+		err := rows.Scan(&t.ID, &t.UserID, &t.Amount, &t.TransactionType, &t.ReferenceID, &t.ReferenceType, &t.BalanceAfter, &t.CreatedAt)
+		if err != nil {
+			return nil, pagination.Page{}, err
+		}
+		transactions = append(transactions, &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	hasMore := len(transactions) > params.Limit
+	if hasMore {
+		transactions = transactions[:params.Limit]
+	}
+	if params.IsPrev() {
+		slices.Reverse(transactions)
+	}
+
+	var first, last *pagination.Cursor
+	if len(transactions) > 0 {
+		first = &pagination.Cursor{CreatedAt: &transactions[0].CreatedAt, ID: transactions[0].ID}
+		last = &pagination.Cursor{CreatedAt: &transactions[len(transactions)-1].CreatedAt, ID: transactions[len(transactions)-1].ID}
+	}
+
+	return transactions, pagination.NewPage(params, hasMore, first, last), nil
+}
+
 func (r *walletRepository) CreateTransactionTx(ctx context.Context, tx *sql.Tx, transaction *domain.WalletTransaction) error {
 	query := `
         INSERT INTO wallet_transactions (user_id, amount, transaction_type, reference_id, reference_type, balance_after, created_at)
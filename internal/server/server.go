@@ -13,9 +13,9 @@ import (
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository/postgres"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/usecase"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/auth"
-	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/cloudinary"
 	email "github.com/mohamedfawas/rmshop-clean-architecture/pkg/emailVerify"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/payment/razorpay"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/storage"
 )
 
 // Server struct holds the router which will be used to handle HTTP requests
@@ -25,7 +25,7 @@ type Server struct {
 }
 
 // NewServer creates and returns a new Server instance
-func NewServer(db *sql.DB, emailSender *email.Sender, cloudinaryService *cloudinary.CloudinaryService, tokenBlacklist *auth.TokenBlacklist, cfg *config.Config) *Server {
+func NewServer(db *sql.DB, emailSender *email.Sender, imageStorage storage.ImageStorage, tokenBlacklist *auth.TokenBlacklist, cfg *config.Config) *Server {
 	log.Println("Initializing server components...")
 
 	// User components initialization
@@ -52,15 +52,45 @@ func NewServer(db *sql.DB, emailSender *email.Sender, cloudinaryService *cloudin
 	subCategoryHandler := handlers.NewSubCategoryHandler(subCategoryUseCase)
 	log.Println("Sub-category components initialized")
 
+	// Brand components
+	brandRepo := postgres.NewBrandRepository(db)
+	brandUseCase := usecase.NewBrandUseCase(brandRepo)
+	brandHandler := handlers.NewBrandHandler(brandUseCase)
+	log.Println("Brand components initialized")
+
 	// Product components
 	productRepo := postgres.NewProductRepository(db)
-	productUseCase := usecase.NewProductUseCase(productRepo, subCategoryRepo, cloudinaryService)
+	offerRepo := postgres.NewOfferRepository(db)
+	questionRepo := postgres.NewQuestionRepository(db)
+	productUseCase := usecase.NewProductUseCase(productRepo, subCategoryRepo, brandRepo, offerRepo, questionRepo, imageStorage)
 	productHandler := handlers.NewProductHandler(productUseCase)
 	log.Println("Product components initialized")
 
+	// Product attribute components
+	attributeRepo := postgres.NewProductAttributeRepository(db)
+	attributeUseCase := usecase.NewProductAttributeUseCase(attributeRepo, subCategoryRepo, productRepo)
+	attributeHandler := handlers.NewProductAttributeHandler(attributeUseCase)
+	log.Println("Product attribute components initialized")
+
+	// Recommendation components
+	recommendationRepo := postgres.NewRecommendationRepository(db)
+	recommendationUseCase := usecase.NewRecommendationUseCase(recommendationRepo, productRepo)
+	recommendationHandler := handlers.NewRecommendationHandler(recommendationUseCase)
+	log.Println("Recommendation components initialized")
+
+	// Trash bin components
+	trashUseCase := usecase.NewTrashUseCase(productRepo, categoryRepo, imageStorage)
+	trashHandler := handlers.NewTrashHandler(trashUseCase)
+	log.Println("Trash bin components initialized")
+
+	// Offer components
+	offerUseCase := usecase.NewOfferUseCase(offerRepo, productRepo, subCategoryRepo, categoryRepo)
+	offerHandler := handlers.NewOfferHandler(offerUseCase)
+	log.Println("Offer components initialized")
+
 	// cart components
 	cartRepo := postgres.NewCartRepository(db)
-	cartUseCase := usecase.NewCartUseCase(cartRepo, productRepo, userRepo)
+	cartUseCase := usecase.NewCartUseCase(cartRepo, productRepo, userRepo, offerRepo)
 	cartHandler := handlers.NewCartHandler(cartUseCase)
 	log.Println("Cart components initialized")
 
@@ -76,6 +106,8 @@ func NewServer(db *sql.DB, emailSender *email.Sender, cloudinaryService *cloudin
 	orderRepo := postgres.NewOrderRepository(db)
 	log.Println("Order repository initialized")
 
+	reservationRepo := postgres.NewStockReservationRepository(db)
+
 	// coupon components
 	couponRepo := postgres.NewCouponRepository(db)
 	couponUseCase := usecase.NewCouponUseCase(couponRepo, checkoutRepo)
@@ -84,7 +116,7 @@ func NewServer(db *sql.DB, emailSender *email.Sender, cloudinaryService *cloudin
 
 	razorpayService := razorpay.NewService(cfg.Razorpay.KeyID, cfg.Razorpay.KeySecret)
 
-	checkoutUseCase := usecase.NewCheckoutUseCase(checkoutRepo, productRepo, cartRepo, couponRepo, userRepo, orderRepo, razorpayService)
+	checkoutUseCase := usecase.NewCheckoutUseCase(checkoutRepo, productRepo, cartRepo, couponRepo, userRepo, orderRepo, offerRepo, reservationRepo, razorpayService)
 	checkoutHandler := handlers.NewCheckoutHandler(checkoutUseCase, couponUseCase)
 	log.Println("Checkout components initialized")
 
@@ -96,10 +128,15 @@ func NewServer(db *sql.DB, emailSender *email.Sender, cloudinaryService *cloudin
 	paymentRepo := postgres.NewPaymentRepository(db)
 
 	inventoryRepo := postgres.NewInventoryRepository(db)
-	inventoryUseCase := usecase.NewInventoryUseCase(inventoryRepo, productRepo)
+	inventoryUseCase := usecase.NewInventoryUseCase(inventoryRepo, productRepo, emailSender, cfg.Alerts.Email)
 	inventoryHandler := handlers.NewInventoryHandler(inventoryUseCase)
 	log.Println("Inventory components initialized")
 
+	locationRepo := postgres.NewLocationRepository(db)
+	locationUseCase := usecase.NewLocationUseCase(locationRepo, productRepo)
+	locationHandler := handlers.NewLocationHandler(locationUseCase)
+	log.Println("Location components initialized")
+
 	salesRepo := postgres.NewSalesRepository(db)
 	salesUseCase := usecase.NewSalesUseCase(salesRepo)
 	salesHandler := handlers.NewSalesHandler(salesUseCase)
@@ -107,7 +144,7 @@ func NewServer(db *sql.DB, emailSender *email.Sender, cloudinaryService *cloudin
 
 	// Initialize return components
 	returnRepo := postgres.NewReturnRepository(db)
-	returnUseCase := usecase.NewReturnUseCase(returnRepo, orderRepo, walletRepo, productRepo, paymentRepo)
+	returnUseCase := usecase.NewReturnUseCase(returnRepo, orderRepo, walletRepo, productRepo, paymentRepo, locationRepo)
 	returnHandler := handlers.NewReturnHandler(returnUseCase)
 	log.Println("Return components initialized")
 
@@ -115,10 +152,19 @@ func NewServer(db *sql.DB, emailSender *email.Sender, cloudinaryService *cloudin
 	analyticsUseCase := usecase.NewAnalyticsUseCase(analyticsRepo)
 	analyticsHandler := handlers.NewAnalyticsHandler(analyticsUseCase)
 
-	orderUseCase := usecase.NewOrderUseCase(orderRepo, checkoutRepo, productRepo, cartRepo, walletRepo, paymentRepo, cfg.Razorpay.KeySecret, cfg.Razorpay.KeySecret)
+	orderUseCase := usecase.NewOrderUseCase(orderRepo, checkoutRepo, productRepo, cartRepo, walletRepo, paymentRepo, offerRepo, reservationRepo, locationRepo, cfg.Inventory.AllocationRule, cfg.Razorpay.KeyID, cfg.Razorpay.KeySecret)
 	orderHandler := handlers.NewOrderHandler(orderUseCase)
 	log.Println("Order components initialized")
 
+	reviewRepo := postgres.NewReviewRepository(db)
+	reviewUseCase := usecase.NewReviewUseCase(reviewRepo, productRepo, orderRepo)
+	reviewHandler := handlers.NewReviewHandler(reviewUseCase)
+	log.Println("Review components initialized")
+
+	questionUseCase := usecase.NewQuestionUseCase(questionRepo, productRepo, orderRepo)
+	questionHandler := handlers.NewQuestionHandler(questionUseCase)
+	log.Println("Question components initialized")
+
 	templates := setupTemplates()
 	paymentHandler := handlers.NewPaymentHandler(orderUseCase, cfg.Razorpay.KeyID, cfg.Razorpay.KeySecret, templates)
 
@@ -141,6 +187,15 @@ func NewServer(db *sql.DB, emailSender *email.Sender, cloudinaryService *cloudin
 		salesHandler,
 		analyticsHandler,
 		returnHandler,
+		reviewHandler,
+		brandHandler,
+		offerHandler,
+		attributeHandler,
+		recommendationHandler,
+		trashHandler,
+		questionHandler,
+		locationHandler,
+		imageStorage,
 		templates,
 	)
 	log.Println("Router initialized")
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/validator"
+)
+
+type BrandUseCase interface {
+	CreateBrand(ctx context.Context, brand *domain.Brand) error
+	GetAllBrands(ctx context.Context) ([]*domain.Brand, error)
+	GetBrandByID(ctx context.Context, id int) (*domain.Brand, error)
+	UpdateBrand(ctx context.Context, brand *domain.Brand) error
+	SoftDeleteBrand(ctx context.Context, id int) error
+}
+
+type brandUseCase struct {
+	brandRepo repository.BrandRepository
+}
+
+func NewBrandUseCase(brandRepo repository.BrandRepository) BrandUseCase {
+	return &brandUseCase{brandRepo: brandRepo}
+}
+
+func (u *brandUseCase) CreateBrand(ctx context.Context, brand *domain.Brand) error {
+	brand.Name = strings.ToLower(strings.TrimSpace(brand.Name))
+	if err := validator.ValidateBrandName(brand.Name); err != nil {
+		return err
+	}
+
+	brand.Slug = utils.GenerateSlug(brand.Name)
+	now := time.Now().UTC()
+	brand.CreatedAt = now
+	brand.UpdatedAt = now
+
+	err := u.brandRepo.Create(ctx, brand)
+	if err != nil {
+		if err == utils.ErrDuplicateBrand {
+			return utils.ErrDuplicateBrand
+		}
+		log.Printf("failed to create brand : %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *brandUseCase) GetAllBrands(ctx context.Context) ([]*domain.Brand, error) {
+	return u.brandRepo.GetAll(ctx)
+}
+
+func (u *brandUseCase) GetBrandByID(ctx context.Context, id int) (*domain.Brand, error) {
+	return u.brandRepo.GetByID(ctx, id)
+}
+
+func (u *brandUseCase) UpdateBrand(ctx context.Context, brand *domain.Brand) error {
+	brand.Name = strings.ToLower(strings.TrimSpace(brand.Name))
+	if err := validator.ValidateBrandName(brand.Name); err != nil {
+		return err
+	}
+
+	brand.Slug = utils.GenerateSlug(brand.Name)
+	brand.UpdatedAt = time.Now().UTC()
+
+	err := u.brandRepo.Update(ctx, brand)
+	if err != nil {
+		if err == utils.ErrDuplicateBrand || err == utils.ErrBrandNotFound {
+			return err
+		}
+		log.Printf("failed to update brand : %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *brandUseCase) SoftDeleteBrand(ctx context.Context, id int) error {
+	err := u.brandRepo.SoftDelete(ctx, id)
+	if err != nil {
+		if err == utils.ErrBrandNotFound {
+			return utils.ErrBrandNotFound
+		}
+		log.Printf("failed to soft delete brand : %v", err)
+		return err
+	}
+	return nil
+}
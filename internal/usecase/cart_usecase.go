@@ -11,7 +11,7 @@ import (
 )
 
 type CartUseCase interface {
-	AddToCart(ctx context.Context, userID, productID int64, quantity int) (*domain.CartItem, error)
+	AddToCart(ctx context.Context, userID, productID int64, variantID *int64, quantity int) (*domain.CartItem, error)
 	GetUserCart(ctx context.Context, userID int64) (*domain.CartResponse, error)
 	UpdateCartItemQuantity(ctx context.Context, userID, itemID int64, quantity int) (*domain.CartItem, error)
 	DeleteCartItem(ctx context.Context, userID, itemID int64) error
@@ -22,13 +22,15 @@ type cartUseCase struct {
 	cartRepo    repository.CartRepository
 	productRepo repository.ProductRepository
 	userRepo    repository.UserRepository
+	offerRepo   repository.OfferRepository
 }
 
-func NewCartUseCase(cartRepo repository.CartRepository, productRepo repository.ProductRepository, userRepo repository.UserRepository) CartUseCase {
+func NewCartUseCase(cartRepo repository.CartRepository, productRepo repository.ProductRepository, userRepo repository.UserRepository, offerRepo repository.OfferRepository) CartUseCase {
 	return &cartUseCase{
 		cartRepo:    cartRepo,
 		productRepo: productRepo,
 		userRepo:    userRepo,
+		offerRepo:   offerRepo,
 	}
 }
 
@@ -36,11 +38,12 @@ func NewCartUseCase(cartRepo repository.CartRepository, productRepo repository.P
 AddToCart:
 - Validate the given quantity
 - Get product details , verify it's not soft deleted, stock is available
+- If the product has variants, a variant must be chosen and its price and stock are used
 - Check if the given product is already added in the cart
 - If already exists in cart, then update quantity (make sure it's not exceeding max limit)
 - If product added is new to cart, then make new cart item entry
 */
-func (u *cartUseCase) AddToCart(ctx context.Context, userID, productID int64, quantity int) (*domain.CartItem, error) {
+func (u *cartUseCase) AddToCart(ctx context.Context, userID, productID int64, variantID *int64, quantity int) (*domain.CartItem, error) {
 	// Validate quantity
 	if quantity <= 0 {
 		return nil, utils.ErrInvalidQuantity
@@ -51,26 +54,26 @@ func (u *cartUseCase) AddToCart(ctx context.Context, userID, productID int64, qu
 		return nil, utils.ErrExceedsMaxQuantity
 	}
 
-	// Check if product exists and is active (not soft deleted)
-	product, err := u.productRepo.GetByID(ctx, productID)
+	// Check if product (and variant) exists and is active (not soft deleted)
+	price, stock, err := getItemPriceAndStock(ctx, u.productRepo, u.offerRepo, productID, variantID)
 	if err != nil {
-		if err == utils.ErrProductNotFound {
-			return nil, utils.ErrProductNotFound
+		if err == utils.ErrProductNotFound || err == utils.ErrVariantNotFound || err == utils.ErrVariantRequired {
+			return nil, err
 		}
 		log.Printf("error while getting product details using ID : %v", err)
 		return nil, err
 	}
 
 	// Check stock availability
-	if product.StockQuantity < quantity {
+	if stock < quantity {
 		return nil, utils.ErrInsufficientStock
 	}
 
 	// Calculate subtotal
-	subtotal := product.Price * float64(quantity)
+	subtotal := price * float64(quantity)
 
 	// Check if item already exists in cart
-	existingItem, err := u.cartRepo.GetCartItemByProductID(ctx, userID, productID)
+	existingItem, err := u.cartRepo.GetCartItemByProductID(ctx, userID, productID, variantID)
 	// If any error other than cart item not found happens
 	if err != nil && err != utils.ErrCartItemNotFound {
 		log.Printf("error while checking if the product already exists in the cart : %v", err)
@@ -86,8 +89,8 @@ func (u *cartUseCase) AddToCart(ctx context.Context, userID, productID int64, qu
 
 		// If not violates max limit , Update quantity of existing item
 		existingItem.Quantity += quantity
-		existingItem.Price = product.Price // Update price in case it has changed
-		existingItem.Subtotal = float64(existingItem.Quantity) * product.Price
+		existingItem.Price = price // Update price in case it has changed
+		existingItem.Subtotal = float64(existingItem.Quantity) * price
 		existingItem.UpdatedAt = time.Now().UTC()
 
 		// Update cart item details
@@ -104,8 +107,9 @@ func (u *cartUseCase) AddToCart(ctx context.Context, userID, productID int64, qu
 	newItem := &domain.CartItem{
 		UserID:    userID,
 		ProductID: productID,
+		VariantID: variantID,
 		Quantity:  quantity,
-		Price:     product.Price,
+		Price:     price,
 		Subtotal:  subtotal,
 		CreatedAt: now,
 		UpdatedAt: now,
@@ -174,13 +178,13 @@ func (u *cartUseCase) UpdateCartItemQuantity(ctx context.Context, userID, itemID
 		return nil, utils.ErrUnauthorized
 	}
 
-	// Check product availability
-	product, err := u.productRepo.GetByID(ctx, existingItem.ProductID)
+	// Check product (or variant) availability
+	_, stock, err := getItemPriceAndStock(ctx, u.productRepo, u.offerRepo, existingItem.ProductID, existingItem.VariantID)
 	if err != nil {
 		return nil, err
 	}
 
-	if product.StockQuantity < quantity {
+	if stock < quantity {
 		return nil, utils.ErrInsufficientStock
 	}
 
@@ -230,3 +234,65 @@ func (u *cartUseCase) DeleteCartItem(ctx context.Context, userID, itemID int64)
 func (u *cartUseCase) ClearCart(ctx context.Context, userID int64) error {
 	return u.cartRepo.ClearCart(ctx, userID)
 }
+
+/*
+getItemPriceAndStock:
+- Get the product, and the variant if one is given
+- Only an active product can be bought
+- Make sure the variant belongs to the product
+- A product having variants can't be bought without choosing a variant
+- Variant price 0 means the variant is sold at the product price
+- The best running offer on the product, its sub category or category is applied on the price
+*/
+func getItemPriceAndStock(ctx context.Context, productRepo repository.ProductRepository, offerRepo repository.OfferRepository, productID int64, variantID *int64) (float64, int, error) {
+	product, err := productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if product.Status != utils.ProductStatusActive {
+		return 0, 0, utils.ErrProductNotFound
+	}
+
+	if variantID == nil {
+		hasVariants, err := productRepo.HasVariants(ctx, productID)
+		if err != nil {
+			return 0, 0, err
+		}
+		if hasVariants {
+			return 0, 0, utils.ErrVariantRequired
+		}
+		price, err := getOfferPrice(ctx, offerRepo, productID, product.Price)
+		if err != nil {
+			return 0, 0, err
+		}
+		return price, product.StockQuantity, nil
+	}
+
+	variant, err := productRepo.GetVariantByID(ctx, *variantID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if variant.ProductID != productID {
+		return 0, 0, utils.ErrVariantNotFound
+	}
+
+	price := variant.Price
+	if price == 0 {
+		price = product.Price
+	}
+	price, err = getOfferPrice(ctx, offerRepo, productID, price)
+	if err != nil {
+		return 0, 0, err
+	}
+	return price, variant.StockQuantity, nil
+}
+
+// getOfferPrice returns the given price of the product after applying its best running offer
+func getOfferPrice(ctx context.Context, offerRepo repository.OfferRepository, productID int64, price float64) (float64, error) {
+	offers, err := offerRepo.GetActiveOffersForProduct(ctx, productID)
+	if err != nil {
+		log.Printf("error while retrieving active offers for product : %v", err)
+		return 0, err
+	}
+	return applyBestOffer(price, offers), nil
+}
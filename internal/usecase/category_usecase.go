@@ -16,7 +16,10 @@ type CategoryUseCase interface {
 	GetAllCategories(ctx context.Context) ([]*domain.Category, error)    // fz
 	GetActiveCategoryByID(ctx context.Context, id int) (*domain.Category, error)
 	UpdateCategory(ctx context.Context, category *domain.Category) error
-	SoftDeleteCategory(ctx context.Context, id int) error
+	SoftDeleteCategory(ctx context.Context, id int) (*domain.CategoryCascadeResult, error)
+	GetCategoryTree(ctx context.Context) ([]*domain.Category, error)
+	GetPublicCategoryTree(ctx context.Context) ([]*domain.PublicCategory, error)
+	GetPublicCategory(ctx context.Context, id int) (*domain.PublicCategory, error)
 }
 
 type categoryUseCase struct {
@@ -29,8 +32,19 @@ func NewCategoryUseCase(categoryRepo repository.CategoryRepository) CategoryUseC
 
 func (u *categoryUseCase) CreateCategory(ctx context.Context, category *domain.Category) error {
 
-	// Generate slug
-	category.Slug = utils.GenerateSlug(category.Name)
+	// Generate slug, slug of a child category is prefixed with the slug of its parent
+	if category.ParentID != nil {
+		parent, err := u.categoryRepo.GetByID(ctx, *category.ParentID)
+		if err != nil {
+			if err == utils.ErrCategoryNotFound {
+				return utils.ErrInvalidParentCategory
+			}
+			return err
+		}
+		category.Slug = utils.GenerateSubCategorySlug(parent.Slug, category.Name)
+	} else {
+		category.Slug = utils.GenerateSlug(category.Name)
+	}
 
 	// Set creation time
 	category.CreatedAt = time.Now().UTC()
@@ -84,11 +98,34 @@ func (u *categoryUseCase) UpdateCategory(ctx context.Context, category *domain.C
 		return utils.ErrCategoryNameTooLong
 	}
 
+	existingCategory, err := u.categoryRepo.GetByID(ctx, category.ID)
+	if err != nil {
+		return err
+	}
+
 	// Generate slug
 	category.Slug = utils.GenerateSlug(category.Name)
+	if existingCategory.ParentID != nil {
+		parent, err := u.categoryRepo.GetByID(ctx, *existingCategory.ParentID)
+		if err != nil {
+			return err
+		}
+		category.Slug = utils.GenerateSubCategorySlug(parent.Slug, category.Name)
+	}
+	// images are kept when the request doesn't have them
+	if category.ImageURL == "" {
+		category.ImageURL = existingCategory.ImageURL
+	}
+	if category.BannerURL == "" {
+		category.BannerURL = existingCategory.BannerURL
+	}
+	category.ParentID = existingCategory.ParentID
+	category.Path = existingCategory.Path
+	category.Depth = existingCategory.Depth
+	category.CreatedAt = existingCategory.CreatedAt
 
 	// Attempt to update the category
-	err := u.categoryRepo.Update(ctx, category)
+	err = u.categoryRepo.Update(ctx, category)
 	if err != nil {
 		if err == utils.ErrDuplicateCategory {
 			return utils.ErrDuplicateCategory
@@ -102,27 +139,121 @@ func (u *categoryUseCase) UpdateCategory(ctx context.Context, category *domain.C
 	return nil
 }
 
-func (u *categoryUseCase) SoftDeleteCategory(ctx context.Context, id int) error {
+// SoftDeleteCategory deletes the category along with the categories under it and their products,
+// the returned counts include the cart and wishlist items removed for the deleted products
+func (u *categoryUseCase) SoftDeleteCategory(ctx context.Context, id int) (*domain.CategoryCascadeResult, error) {
 	category, err := u.categoryRepo.GetByID(ctx, id)
 	if err != nil {
 		if err == utils.ErrCategoryNotFound {
-			return utils.ErrCategoryNotFound
+			return nil, utils.ErrCategoryNotFound
 		}
 		log.Printf("Failed to retrieve category: %v", err)
-		return err
+		return nil, err
 	}
 
 	if category.IsDeleted {
-		return utils.ErrCategoryAlreadyDeleted
+		return nil, utils.ErrCategoryAlreadyDeleted
 	}
 
-	err = u.categoryRepo.SoftDelete(ctx, id)
+	result, err := u.categoryRepo.SoftDelete(ctx, id)
 	if err != nil {
 		if err == utils.ErrCategoryNotFound {
-			return utils.ErrCategoryNotFound
+			return nil, utils.ErrCategoryNotFound
 		}
 		log.Printf("Failed to soft delete category: %v", err)
-		return err
+		return nil, err
+	}
+	return result, nil
+}
+
+/*
+GetCategoryTree:
+- Get all the categories, parents come before their children
+- Attach each category to its parent, top level categories are the roots of the tree
+- Children of a soft deleted category are left out along with it
+*/
+func (u *categoryUseCase) GetCategoryTree(ctx context.Context) ([]*domain.Category, error) {
+	categories, err := u.categoryRepo.GetTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*domain.Category, len(categories))
+	roots := []*domain.Category{}
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			byID[category.ID] = category
+			continue
+		}
+		parent, ok := byID[*category.ParentID]
+		if !ok {
+			continue
+		}
+		parent.Children = append(parent.Children, category)
+		byID[category.ID] = category
+	}
+
+	return roots, nil
+}
+
+/*
+GetPublicCategoryTree:
+- Same tree as GetCategoryTree, with only the fields the storefront needs
+- Each category has the count of the listed products under it
+*/
+func (u *categoryUseCase) GetPublicCategoryTree(ctx context.Context) ([]*domain.PublicCategory, error) {
+	tree, err := u.GetCategoryTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := u.categoryRepo.GetProductCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPublicCategories(tree, counts), nil
+}
+
+// GetPublicCategory returns a category of the public tree along with its sub categories
+func (u *categoryUseCase) GetPublicCategory(ctx context.Context, id int) (*domain.PublicCategory, error) {
+	tree, err := u.GetPublicCategoryTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	category := findPublicCategory(tree, id)
+	if category == nil {
+		return nil, utils.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func toPublicCategories(categories []*domain.Category, counts map[int]int) []*domain.PublicCategory {
+	publicCategories := make([]*domain.PublicCategory, 0, len(categories))
+	for _, category := range categories {
+		publicCategories = append(publicCategories, &domain.PublicCategory{
+			ID:           category.ID,
+			Name:         category.Name,
+			Slug:         category.Slug,
+			ImageURL:     category.ImageURL,
+			BannerURL:    category.BannerURL,
+			ProductCount: counts[category.ID],
+			Children:     toPublicCategories(category.Children, counts),
+		})
+	}
+	return publicCategories
+}
+
+func findPublicCategory(categories []*domain.PublicCategory, id int) *domain.PublicCategory {
+	for _, category := range categories {
+		if category.ID == id {
+			return category
+		}
+		if found := findPublicCategory(category.Children, id); found != nil {
+			return found
+		}
 	}
 	return nil
 }
@@ -27,6 +27,8 @@ type checkoutUseCase struct {
 	cartRepo        repository.CartRepository
 	userRepo        repository.UserRepository
 	orderRepo       repository.OrderRepository
+	offerRepo       repository.OfferRepository
+	reservationRepo repository.StockReservationRepository
 	razorpayService *razorpay.Service
 }
 
@@ -36,6 +38,8 @@ func NewCheckoutUseCase(checkoutRepo repository.CheckoutRepository,
 	couponRepo repository.CouponRepository,
 	userRepo repository.UserRepository,
 	orderRepo repository.OrderRepository,
+	offerRepo repository.OfferRepository,
+	reservationRepo repository.StockReservationRepository,
 	razorpayService *razorpay.Service) CheckoutUseCase {
 	return &checkoutUseCase{
 		checkoutRepo:    checkoutRepo,
@@ -44,6 +48,8 @@ func NewCheckoutUseCase(checkoutRepo repository.CheckoutRepository,
 		cartRepo:        cartRepo,
 		userRepo:        userRepo,
 		orderRepo:       orderRepo,
+		offerRepo:       offerRepo,
+		reservationRepo: reservationRepo,
 		razorpayService: razorpayService,
 	}
 }
@@ -60,28 +66,47 @@ func (u *checkoutUseCase) CreateOrUpdateCheckout(ctx context.Context, userID int
 		return nil, utils.ErrEmptyCart
 	}
 
+	// Get or create checkout session
+	session, err := u.checkoutRepo.GetOrCreateCheckoutSession(ctx, userID)
+	if err != nil {
+		log.Printf("error while getting or creating checkout session: %v", err)
+		return nil, err
+	}
+
 	// Calculate total amount and validate stock
 	var totalAmount float64
 	// Iterate through each cart item
 	for _, item := range cartItems {
-		product, err := u.productRepo.GetByID(ctx, item.ProductID)
+		price, stock, err := getItemPriceAndStock(ctx, u.productRepo, u.offerRepo, item.ProductID, item.VariantID)
 		if err != nil {
 			log.Printf("error while retrieving product details: %v", err)
 			return nil, err
 		}
 
-		if product.StockQuantity < item.Quantity {
+		// Stock held by the other checkouts is not available, the reservations of this checkout are replaced
+		reserved, err := u.reservationRepo.GetReservedQuantity(ctx, item.ProductID, item.VariantID, session.ID)
+		if err != nil {
+			log.Printf("error while retrieving reserved stock: %v", err)
+			return nil, err
+		}
+
+		if stock-reserved < item.Quantity {
 			return nil, utils.ErrInsufficientStock
 		}
 
-		totalAmount += item.Subtotal
-	}
+		// Refresh the cart item if an offer started or ended after it was added
+		if price != item.Price {
+			item.Price = price
+			item.Subtotal = price * float64(item.Quantity)
+			item.UpdatedAt = time.Now().UTC()
+			err = u.cartRepo.UpdateCartItem(ctx, item)
+			if err != nil {
+				log.Printf("error while updating cart item price: %v", err)
+				return nil, err
+			}
+		}
 
-	// Get or create checkout session
-	session, err := u.checkoutRepo.GetOrCreateCheckoutSession(ctx, userID)
-	if err != nil {
-		log.Printf("error while getting or creating checkout session: %v", err)
-		return nil, err
+		totalAmount += item.Subtotal
 	}
 
 	// If the session is updated after applying the coupon, then the applied coupon will be removed
@@ -98,6 +123,17 @@ func (u *checkoutUseCase) CreateOrUpdateCheckout(ctx context.Context, userID int
 	session.UpdatedAt = time.Now().UTC()
 	session.DiscountAmount = 0
 
+	// Cart may have changed after the address was given, hold the stock of the current cart items
+	if session.ShippingAddressID != 0 {
+		reservedUntil := time.Now().UTC().Add(utils.CheckoutReservationTTL)
+		err = u.reservationRepo.Reserve(ctx, session.ID, userID, cartItems, reservedUntil)
+		if err != nil {
+			log.Printf("error while reserving stock for the checkout: %v", err)
+			return nil, err
+		}
+		session.ReservedUntil = &reservedUntil
+	}
+
 	// Update the checkout session in the database
 	err = u.checkoutRepo.UpdateCheckoutDetails(ctx, session)
 	if err != nil {
@@ -246,6 +282,23 @@ func (u *checkoutUseCase) UpdateCheckoutAddress(ctx context.Context, userID, add
 		return nil, utils.ErrAddressNotBelongToUser
 	}
 
+	// Hold the stock of the cart items for the checkout, so that it can't be sold to another user meanwhile
+	cartItems, err := u.cartRepo.GetCartByUserID(ctx, userID)
+	if err != nil {
+		log.Printf("error while retrieving cart items: %v", err)
+		return nil, err
+	}
+	if len(cartItems) == 0 {
+		return nil, utils.ErrEmptyCart
+	}
+
+	reservedUntil := time.Now().UTC().Add(utils.CheckoutReservationTTL)
+	err = u.reservationRepo.Reserve(ctx, checkout.ID, userID, cartItems, reservedUntil)
+	if err != nil {
+		log.Printf("error while reserving stock for the checkout: %v", err)
+		return nil, err
+	}
+
 	// Create or get existing shipping address
 	shippingAddressID, err := u.checkoutRepo.CreateOrGetShippingAddress(ctx, userID, addressID)
 	if err != nil {
@@ -264,6 +317,7 @@ func (u *checkoutUseCase) UpdateCheckoutAddress(ctx context.Context, userID, add
 		log.Printf("error while fetching checkout session details from checkout_sessions table: %v", err)
 		return nil, err
 	}
+	updatedCheckout.ReservedUntil = &reservedUntil
 
 	return updatedCheckout, nil
 }
@@ -344,6 +398,7 @@ func (u *checkoutUseCase) GetCheckoutSummary(ctx context.Context, userID int64)
 		items = append(items, &domain.CheckoutItemDetail{
 			ID:        cartItem.ID,
 			ProductID: cartItem.ProductID,
+			VariantID: cartItem.VariantID,
 			Name:      product.Name,
 			Quantity:  cartItem.Quantity,
 			Price:     cartItem.Price,
@@ -2,26 +2,38 @@ package usecase
 
 import (
 	"context"
+	"log"
 
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	email "github.com/mohamedfawas/rmshop-clean-architecture/pkg/emailVerify"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 )
 
 type InventoryUseCase interface {
 	GetInventory(ctx context.Context, params domain.InventoryQueryParams) ([]*domain.InventoryItem, int64, error)
-	UpdateProductStock(ctx context.Context, productID int64, quantity int) error
+	UpdateProductStock(ctx context.Context, adminID, productID int64, quantity int) error
+	UpdateVariantStock(ctx context.Context, adminID, productID, variantID int64, quantity int) error
+	GetStockMovements(ctx context.Context, params domain.StockMovementQueryParams) ([]*domain.StockMovement, int64, error)
+	CheckStockConsistency(ctx context.Context, productID int64) ([]*domain.StockDiscrepancy, error)
+	SetReorderThreshold(ctx context.Context, productID int64, threshold *int) error
+	GetStockAlerts(ctx context.Context, params domain.StockAlertQueryParams) ([]*domain.StockAlert, int64, error)
+	DetectStockAlerts(ctx context.Context) (int64, error)
 }
 
 type inventoryUseCase struct {
 	inventoryRepo repository.InventoryRepository
 	productRepo   repository.ProductRepository
+	emailSender   email.EmailSender
+	alertEmail    string
 }
 
-func NewInventoryUseCase(inventoryRepo repository.InventoryRepository, productRepo repository.ProductRepository) InventoryUseCase {
+func NewInventoryUseCase(inventoryRepo repository.InventoryRepository, productRepo repository.ProductRepository, emailSender email.EmailSender, alertEmail string) InventoryUseCase {
 	return &inventoryUseCase{
 		inventoryRepo: inventoryRepo,
-		productRepo:   productRepo}
+		productRepo:   productRepo,
+		emailSender:   emailSender,
+		alertEmail:    alertEmail}
 }
 
 func (u *inventoryUseCase) GetInventory(ctx context.Context, params domain.InventoryQueryParams) ([]*domain.InventoryItem, int64, error) {
@@ -36,18 +48,21 @@ func (u *inventoryUseCase) GetInventory(ctx context.Context, params domain.Inven
 	}
 
 	// Validate sorting parameters
-	validSortFields := map[string]bool{"product_name": true, "category_name": true, "stock_quantity": true, "price": true}
+	validSortFields := map[string]bool{"product_name": true, "category_name": true, "stock_quantity": true, "reserved_quantity": true, "price": true}
 	if params.SortBy != "" && !validSortFields[params.SortBy] {
 		params.SortBy = "product_name"
 	}
 	if params.SortOrder != "asc" && params.SortOrder != "desc" {
 		params.SortOrder = "asc"
 	}
+	if params.Breakdown != utils.InventoryBreakdownLocation {
+		params.Breakdown = ""
+	}
 
 	return u.inventoryRepo.GetInventory(ctx, params)
 }
 
-func (u *inventoryUseCase) UpdateProductStock(ctx context.Context, productID int64, quantity int) error {
+func (u *inventoryUseCase) UpdateProductStock(ctx context.Context, adminID, productID int64, quantity int) error {
 	if quantity < 0 {
 		return utils.ErrInvalidStockQuantity
 	}
@@ -57,5 +72,143 @@ func (u *inventoryUseCase) UpdateProductStock(ctx context.Context, productID int
 		return utils.ErrStockQuantityTooLarge
 	}
 
-	return u.productRepo.UpdateStockQuantity(ctx, productID, quantity)
+	// Stock of a product with variants is the sum of its variant stocks,
+	// so it must be updated per variant
+	hasVariants, err := u.productRepo.HasVariants(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if hasVariants {
+		return utils.ErrProductHasVariants
+	}
+
+	return u.productRepo.UpdateStockQuantity(ctx, productID, quantity, adminID)
+}
+
+func (u *inventoryUseCase) UpdateVariantStock(ctx context.Context, adminID, productID, variantID int64, quantity int) error {
+	if quantity < 0 {
+		return utils.ErrInvalidStockQuantity
+	}
+
+	if quantity > 1000000 {
+		return utils.ErrStockQuantityTooLarge
+	}
+
+	// Make sure the variant belongs to the given product
+	variant, err := u.productRepo.GetVariantByID(ctx, variantID)
+	if err != nil {
+		return err
+	}
+	if variant.ProductID != productID {
+		return utils.ErrVariantNotFound
+	}
+
+	return u.productRepo.UpdateVariantStockQuantity(ctx, variantID, quantity, adminID)
+}
+
+// GetStockMovements returns the stock ledger of the product, latest movement first
+func (u *inventoryUseCase) GetStockMovements(ctx context.Context, params domain.StockMovementQueryParams) ([]*domain.StockMovement, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	} else if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	_, err := u.productRepo.GetByID(ctx, params.ProductID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return u.inventoryRepo.GetStockMovements(ctx, params)
+}
+
+/*
+CheckStockConsistency:
+- Recompute the stock from the ledger and compare it with the current stock
+- Checks a single product when productID is given, otherwise the whole catalogue
+- An empty result means every stock matches its ledger
+*/
+func (u *inventoryUseCase) CheckStockConsistency(ctx context.Context, productID int64) ([]*domain.StockDiscrepancy, error) {
+	if productID != 0 {
+		_, err := u.productRepo.GetByID(ctx, productID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return u.inventoryRepo.GetStockDiscrepancies(ctx, productID)
+}
+
+func (u *inventoryUseCase) SetReorderThreshold(ctx context.Context, productID int64, threshold *int) error {
+	if threshold != nil && (*threshold < 0 || *threshold > utils.MaxReorderThreshold) {
+		return utils.ErrInvalidReorderThreshold
+	}
+
+	return u.inventoryRepo.SetReorderThreshold(ctx, productID, threshold)
+}
+
+func (u *inventoryUseCase) GetStockAlerts(ctx context.Context, params domain.StockAlertQueryParams) ([]*domain.StockAlert, int64, error) {
+	if params.Status != "" && params.Status != utils.StockAlertStatusOpen && params.Status != utils.StockAlertStatusResolved {
+		return nil, 0, utils.ErrInvalidStockAlertStatus
+	}
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	} else if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	return u.inventoryRepo.GetStockAlerts(ctx, params)
+}
+
+/*
+DetectStockAlerts:
+- Resolve the alerts of the products which are restocked
+- Open alerts for the products which crossed their reorder threshold
+- Email the open alerts which are not emailed yet in a single email, alerts whose email failed are sent on the next run
+- Returns the number of alerts opened
+*/
+func (u *inventoryUseCase) DetectStockAlerts(ctx context.Context) (int64, error) {
+	resolved, err := u.inventoryRepo.ResolveStockAlerts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if resolved > 0 {
+		log.Printf("Resolved %d stock alerts of restocked products", resolved)
+	}
+
+	created, err := u.inventoryRepo.CreateStockAlerts(ctx, utils.SalesVelocityWindowDays, utils.ReorderCoverDays)
+	if err != nil {
+		return 0, err
+	}
+
+	// without a recipient the alerts are only listed for the admin
+	if u.alertEmail == "" {
+		return created, nil
+	}
+
+	alerts, err := u.inventoryRepo.GetUnsentStockAlerts(ctx)
+	if err != nil {
+		return created, err
+	}
+	if len(alerts) == 0 {
+		return created, nil
+	}
+
+	err = u.emailSender.SendLowStockAlert(u.alertEmail, alerts)
+	if err != nil {
+		log.Printf("error while emailing stock alerts : %v", err)
+		return created, err
+	}
+
+	alertIDs := make([]int64, len(alerts))
+	for i, alert := range alerts {
+		alertIDs[i] = alert.ID
+	}
+	return created, u.inventoryRepo.MarkStockAlertsEmailed(ctx, alertIDs)
 }
@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/validator"
+)
+
+type LocationUseCase interface {
+	CreateLocation(ctx context.Context, location *domain.InventoryLocation) error
+	GetLocations(ctx context.Context) ([]*domain.InventoryLocation, error)
+	UpdateLocation(ctx context.Context, locationID int64, update domain.InventoryLocationUpdate) (*domain.InventoryLocation, error)
+	GetLocationStock(ctx context.Context, params domain.LocationStockQueryParams) ([]*domain.LocationStock, int64, error)
+	SetLocationStock(ctx context.Context, adminID, locationID, productID int64, variantID *int64, quantity int) error
+	TransferStock(ctx context.Context, adminID int64, transfer *domain.StockTransfer) error
+	GetTransfers(ctx context.Context, params domain.StockTransferQueryParams) ([]*domain.StockTransfer, int64, error)
+}
+
+type locationUseCase struct {
+	locationRepo repository.LocationRepository
+	productRepo  repository.ProductRepository
+}
+
+func NewLocationUseCase(locationRepo repository.LocationRepository, productRepo repository.ProductRepository) LocationUseCase {
+	return &locationUseCase{
+		locationRepo: locationRepo,
+		productRepo:  productRepo,
+	}
+}
+
+func (u *locationUseCase) CreateLocation(ctx context.Context, location *domain.InventoryLocation) error {
+	location.Name = strings.TrimSpace(location.Name)
+	location.Code = strings.ToUpper(strings.TrimSpace(location.Code))
+	location.Pincode = strings.TrimSpace(location.Pincode)
+	if err := validator.ValidateInventoryLocation(location); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	location.IsActive = true
+	location.CreatedAt = now
+	location.UpdatedAt = now
+
+	return u.locationRepo.Create(ctx, location)
+}
+
+// GetLocations returns every location in its priority order, with the stock held at the location
+func (u *locationUseCase) GetLocations(ctx context.Context) ([]*domain.InventoryLocation, error) {
+	return u.locationRepo.GetAll(ctx)
+}
+
+/*
+UpdateLocation:
+- Update the given details of the location
+- A location can only be deactivated once its stock is transferred out, stock of an inactive location can't be sold
+- At least one location stays active, new stock is added to the primary location
+*/
+func (u *locationUseCase) UpdateLocation(ctx context.Context, locationID int64, update domain.InventoryLocationUpdate) (*domain.InventoryLocation, error) {
+	location, err := u.locationRepo.GetByID(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		location.Name = strings.TrimSpace(*update.Name)
+		if err := validator.ValidateLocationName(location.Name); err != nil {
+			return nil, err
+		}
+	}
+	if update.Pincode != nil {
+		location.Pincode = strings.TrimSpace(*update.Pincode)
+		if err := validator.ValidateLocationPincode(location.Pincode); err != nil {
+			return nil, err
+		}
+	}
+	if update.Priority != nil {
+		if err := validator.ValidateLocationPriority(*update.Priority); err != nil {
+			return nil, err
+		}
+		location.Priority = *update.Priority
+	}
+
+	if update.IsActive != nil && location.IsActive && !*update.IsActive {
+		hasStock, err := u.locationRepo.HasStock(ctx, locationID)
+		if err != nil {
+			return nil, err
+		}
+		if hasStock {
+			return nil, utils.ErrLocationHasStock
+		}
+
+		activeCount, err := u.locationRepo.CountActive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if activeCount <= 1 {
+			return nil, utils.ErrNoActiveLocation
+		}
+	}
+	if update.IsActive != nil {
+		location.IsActive = *update.IsActive
+	}
+
+	location.UpdatedAt = time.Now().UTC()
+	err = u.locationRepo.Update(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
+
+func (u *locationUseCase) GetLocationStock(ctx context.Context, params domain.LocationStockQueryParams) ([]*domain.LocationStock, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	} else if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	_, err := u.locationRepo.GetByID(ctx, params.LocationID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return u.locationRepo.GetLocationStock(ctx, params)
+}
+
+/*
+SetLocationStock:
+- Set the stock of the product, or of its variant, at the location
+- The difference is applied to the total stock as well, the total stock is the sum of the stock at every location
+- The change is recorded as a stock adjustment at the location made by the admin
+*/
+func (u *locationUseCase) SetLocationStock(ctx context.Context, adminID, locationID, productID int64, variantID *int64, quantity int) error {
+	if quantity < 0 {
+		return utils.ErrInvalidStockQuantity
+	}
+	if quantity > 1000000 {
+		return utils.ErrStockQuantityTooLarge
+	}
+
+	location, err := u.locationRepo.GetByID(ctx, locationID)
+	if err != nil {
+		return err
+	}
+	if !location.IsActive && quantity > 0 {
+		return utils.ErrLocationInactive
+	}
+
+	err = u.validateStockItem(ctx, productID, variantID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := u.locationRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("failed to start transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = u.locationRepo.LockItemStockTx(ctx, tx, productID, variantID)
+	if err != nil {
+		return err
+	}
+
+	current, err := u.locationRepo.GetItemStockTx(ctx, tx, locationID, productID, variantID)
+	if err != nil {
+		return err
+	}
+
+	if quantity != current {
+		change := domain.StockChange{
+			Reason:        utils.StockMovementReasonAdjustment,
+			ReferenceType: utils.StockReferenceAdmin,
+			ActorType:     utils.StockActorAdmin,
+			ActorID:       &adminID,
+			LocationID:    &locationID,
+		}
+		err = u.productRepo.UpdateStockTx(ctx, tx, productID, variantID, quantity-current, change)
+		if err != nil {
+			log.Printf("failed to update the stock at the location : %v", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+/*
+TransferStock:
+- Move stock of the product, or of its variant, from one location to another
+- The stock is recorded as a movement out of the source location and a movement into the destination location
+- Both movements refer to the transfer, the total stock is the same after the transfer
+- The stock at the source location is locked, the transfer is refused when it doesn't cover the quantity
+*/
+func (u *locationUseCase) TransferStock(ctx context.Context, adminID int64, transfer *domain.StockTransfer) error {
+	if transfer.Quantity <= 0 || transfer.Quantity > 1000000 {
+		return utils.ErrInvalidTransferQuantity
+	}
+	if transfer.FromLocationID == transfer.ToLocationID {
+		return utils.ErrSameTransferLocation
+	}
+
+	_, err := u.locationRepo.GetByID(ctx, transfer.FromLocationID)
+	if err != nil {
+		return err
+	}
+	to, err := u.locationRepo.GetByID(ctx, transfer.ToLocationID)
+	if err != nil {
+		return err
+	}
+	if !to.IsActive {
+		return utils.ErrLocationInactive
+	}
+
+	err = u.validateStockItem(ctx, transfer.ProductID, transfer.VariantID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := u.locationRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("failed to start transaction : %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = u.locationRepo.LockItemStockTx(ctx, tx, transfer.ProductID, transfer.VariantID)
+	if err != nil {
+		return err
+	}
+
+	available, err := u.locationRepo.GetItemStockTx(ctx, tx, transfer.FromLocationID, transfer.ProductID, transfer.VariantID)
+	if err != nil {
+		return err
+	}
+	if available < transfer.Quantity {
+		return utils.ErrInsufficientLocationStock
+	}
+
+	transfer.Note = strings.TrimSpace(transfer.Note)
+	transfer.CreatedBy = adminID
+	transfer.CreatedAt = time.Now().UTC()
+	err = u.locationRepo.CreateTransferTx(ctx, tx, transfer)
+	if err != nil {
+		return err
+	}
+
+	change := domain.StockChange{
+		Reason:        utils.StockMovementReasonTransfer,
+		ReferenceType: utils.StockReferenceTransfer,
+		ReferenceID:   &transfer.ID,
+		ActorType:     utils.StockActorAdmin,
+		ActorID:       &adminID,
+		LocationID:    &transfer.FromLocationID,
+	}
+	err = u.productRepo.UpdateStockTx(ctx, tx, transfer.ProductID, transfer.VariantID, -transfer.Quantity, change)
+	if err != nil {
+		log.Printf("failed to move the stock out of location %d : %v", transfer.FromLocationID, err)
+		return err
+	}
+
+	change.LocationID = &transfer.ToLocationID
+	err = u.productRepo.UpdateStockTx(ctx, tx, transfer.ProductID, transfer.VariantID, transfer.Quantity, change)
+	if err != nil {
+		log.Printf("failed to move the stock into location %d : %v", transfer.ToLocationID, err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (u *locationUseCase) GetTransfers(ctx context.Context, params domain.StockTransferQueryParams) ([]*domain.StockTransfer, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 20
+	} else if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	return u.locationRepo.GetTransfers(ctx, params)
+}
+
+/*
+validateStockItem:
+- The product should exist, stock of a product with variants is held by its variants
+- The variant, when given, should belong to the product
+*/
+func (u *locationUseCase) validateStockItem(ctx context.Context, productID int64, variantID *int64) error {
+	if variantID != nil {
+		variant, err := u.productRepo.GetVariantByID(ctx, *variantID)
+		if err != nil {
+			return err
+		}
+		if variant.ProductID != productID {
+			return utils.ErrVariantNotFound
+		}
+		return nil
+	}
+
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	hasVariants, err := u.productRepo.HasVariants(ctx, productID)
+	if err != nil {
+		return err
+	}
+	if hasVariants {
+		return utils.ErrProductHasVariants
+	}
+	return nil
+}
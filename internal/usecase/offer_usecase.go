@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/validator"
+)
+
+type OfferUseCase interface {
+	CreateOffer(ctx context.Context, input domain.CreateOfferInput) (*domain.Offer, error)
+	GetOffers(ctx context.Context, params domain.OfferQueryParams) ([]*domain.Offer, int64, error)
+	DeactivateOffer(ctx context.Context, offerID int64) error
+}
+
+type offerUseCase struct {
+	offerRepo       repository.OfferRepository
+	productRepo     repository.ProductRepository
+	subCategoryRepo repository.SubCategoryRepository
+	categoryRepo    repository.CategoryRepository
+}
+
+func NewOfferUseCase(offerRepo repository.OfferRepository, productRepo repository.ProductRepository, subCategoryRepo repository.SubCategoryRepository, categoryRepo repository.CategoryRepository) OfferUseCase {
+	return &offerUseCase{
+		offerRepo:       offerRepo,
+		productRepo:     productRepo,
+		subCategoryRepo: subCategoryRepo,
+		categoryRepo:    categoryRepo,
+	}
+}
+
+/*
+CreateOffer:
+- Validate offer details
+- Parse the offer period, offer should end in the future
+- Make sure the targeted product, sub category or category exists
+- Create the offer
+*/
+func (u *offerUseCase) CreateOffer(ctx context.Context, input domain.CreateOfferInput) (*domain.Offer, error) {
+	input.DiscountType = strings.ToLower(strings.TrimSpace(input.DiscountType))
+	if err := validator.ValidateOfferInput(input); err != nil {
+		return nil, err
+	}
+
+	startsAt, err := parseOfferTime(input.StartsAt)
+	if err != nil {
+		return nil, utils.ErrInvalidOfferPeriod
+	}
+	endsAt, err := parseOfferTime(input.EndsAt)
+	if err != nil {
+		return nil, utils.ErrInvalidOfferPeriod
+	}
+	if !endsAt.After(startsAt) || !endsAt.After(time.Now().UTC()) {
+		return nil, utils.ErrInvalidOfferPeriod
+	}
+
+	switch {
+	case input.ProductID != nil:
+		_, err = u.productRepo.GetByID(ctx, *input.ProductID)
+	case input.SubCategoryID != nil:
+		_, err = u.subCategoryRepo.GetByID(ctx, *input.SubCategoryID)
+	case input.CategoryID != nil:
+		_, err = u.categoryRepo.GetByID(ctx, *input.CategoryID)
+	}
+	if err != nil {
+		if err == utils.ErrProductNotFound || err == utils.ErrSubCategoryNotFound || err == utils.ErrCategoryNotFound {
+			return nil, err
+		}
+		log.Printf("error while retrieving offer target : %v", err)
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	offer := &domain.Offer{
+		Name:          strings.TrimSpace(input.Name),
+		DiscountType:  input.DiscountType,
+		DiscountValue: input.DiscountValue,
+		ProductID:     input.ProductID,
+		SubCategoryID: input.SubCategoryID,
+		CategoryID:    input.CategoryID,
+		StartsAt:      startsAt,
+		EndsAt:        endsAt,
+		IsActive:      true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	err = u.offerRepo.Create(ctx, offer)
+	if err != nil {
+		log.Printf("error while creating offer : %v", err)
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+func (u *offerUseCase) GetOffers(ctx context.Context, params domain.OfferQueryParams) ([]*domain.Offer, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 10
+	} else if params.Limit > 100 {
+		params.Limit = 100
+	}
+
+	return u.offerRepo.GetAll(ctx, params)
+}
+
+func (u *offerUseCase) DeactivateOffer(ctx context.Context, offerID int64) error {
+	offer, err := u.offerRepo.GetByID(ctx, offerID)
+	if err != nil {
+		return err
+	}
+	if !offer.IsActive {
+		return utils.ErrOfferAlreadyInactive
+	}
+
+	return u.offerRepo.Deactivate(ctx, offerID)
+}
+
+// parseOfferTime accepts either a full RFC3339 timestamp or a date
+func parseOfferTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// applyBestOffer returns the lowest price reachable with any one of the given offers
+func applyBestOffer(price float64, offers []*domain.Offer) float64 {
+	bestPrice := price
+	for _, offer := range offers {
+		var discount float64
+		switch offer.DiscountType {
+		case utils.OfferTypePercentage:
+			discount = price * offer.DiscountValue / 100
+		case utils.OfferTypeFlat:
+			discount = math.Min(offer.DiscountValue, price)
+		}
+		if price-discount < bestPrice {
+			bestPrice = price - discount
+		}
+	}
+	return math.Round(bestPrice*100) / 100
+}
+
+// discountPercentage returns how much the offer price is reduced from the original price, in percent
+func discountPercentage(originalPrice, offerPrice float64) float64 {
+	if originalPrice <= 0 {
+		return 0
+	}
+	return math.Round((originalPrice-offerPrice)/originalPrice*10000) / 100
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestApplyBestOffer(t *testing.T) {
+	percentage := func(value float64) *domain.Offer {
+		return &domain.Offer{DiscountType: utils.OfferTypePercentage, DiscountValue: value}
+	}
+	flat := func(value float64) *domain.Offer {
+		return &domain.Offer{DiscountType: utils.OfferTypeFlat, DiscountValue: value}
+	}
+
+	tests := []struct {
+		name   string
+		price  float64
+		offers []*domain.Offer
+		want   float64
+	}{
+		{name: "no offers", price: 999.99, want: 999.99},
+		{name: "percentage offer", price: 1000, offers: []*domain.Offer{percentage(10)}, want: 900},
+		{name: "flat offer", price: 1000, offers: []*domain.Offer{flat(150)}, want: 850},
+		{name: "flat offer above the price", price: 100, offers: []*domain.Offer{flat(150)}, want: 0},
+		{name: "percentage offer is better", price: 1000, offers: []*domain.Offer{flat(100), percentage(20)}, want: 800},
+		{name: "flat offer is better", price: 400, offers: []*domain.Offer{percentage(20), flat(100)}, want: 300},
+		{name: "offers don't stack", price: 1000, offers: []*domain.Offer{percentage(10), percentage(10)}, want: 900},
+		{name: "rounded to paise", price: 333.33, offers: []*domain.Offer{percentage(15)}, want: 283.33},
+		{name: "unknown discount type is ignored", price: 500, offers: []*domain.Offer{{DiscountType: "bogus", DiscountValue: 50}}, want: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyBestOffer(tt.price, tt.offers)
+			if got != tt.want {
+				t.Errorf("applyBestOffer(%v) = %v, want %v", tt.price, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscountPercentage(t *testing.T) {
+	tests := []struct {
+		name          string
+		originalPrice float64
+		offerPrice    float64
+		want          float64
+	}{
+		{name: "no discount", originalPrice: 500, offerPrice: 500, want: 0},
+		{name: "quarter off", originalPrice: 400, offerPrice: 300, want: 25},
+		{name: "rounded to two decimals", originalPrice: 300, offerPrice: 200, want: 33.33},
+		{name: "free product", originalPrice: 0, offerPrice: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := discountPercentage(tt.originalPrice, tt.offerPrice)
+			if got != tt.want {
+				t.Errorf("discountPercentage(%v, %v) = %v, want %v", tt.originalPrice, tt.offerPrice, got, tt.want)
+			}
+		})
+	}
+}
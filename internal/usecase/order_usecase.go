@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
 	invoicegenerator "github.com/mohamedfawas/rmshop-clean-architecture/pkg/invoice_generator"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/pagination"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/payment/razorpay"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 )
@@ -18,11 +20,15 @@ type OrderUseCase interface {
 	GetOrderByID(ctx context.Context, userID, orderID int64) (*domain.Order, error)
 	GetUserOrders(ctx context.Context, userID int64, page int) ([]*domain.Order, int64, error)
 	GetOrders(ctx context.Context, page int) ([]*domain.Order, int64, error)
+	GetUserOrdersByCursor(ctx context.Context, userID int64, params pagination.Params) ([]*domain.Order, pagination.Page, error)
+	GetOrdersByCursor(ctx context.Context, params pagination.Params) ([]*domain.Order, pagination.Page, error)
 	GetPaymentByOrderID(ctx context.Context, orderID int64) (*domain.Payment, error)
 	CreatePayment(ctx context.Context, tx *sql.Tx, payment *domain.Payment) error
 	UpdatePayment(ctx context.Context, payment *domain.Payment) error
 	ProcessPayment(ctx context.Context, tx *sql.Tx, orderID int64, paymentMethod string, amount float64) (*domain.Payment, error)
-	VerifyAndUpdateRazorpayPayment(ctx context.Context, input domain.RazorpayPaymentInput) error
+	VerifyAndUpdateRazorpayPayment(ctx context.Context, input domain.RazorpayPaymentInput) (*domain.OrderStatusUpdateResult, error)
+	HandleRazorpayPaymentFailure(ctx context.Context, razorpayOrderID, razorpayPaymentID string) error
+	HoldStockForPayment(ctx context.Context, orderID int64) error
 	PlaceOrderRazorpay(ctx context.Context, userID int64) (*domain.Order, error)
 	UpdateOrderRazorpayID(ctx context.Context, orderID int64, razorpayOrderID string) error
 	InitiateReturn(ctx context.Context, userID, orderID int64, reason string) (*domain.ReturnRequest, error)
@@ -33,8 +39,8 @@ type OrderUseCase interface {
 	GetRazorpayKeyID() string
 	GetPaymentByRazorpayOrderID(ctx context.Context, razorpayOrderID string) (*domain.Payment, error)
 	CancelOrder(ctx context.Context, userID, orderID int64) (*domain.OrderCancellationResult, error)
-	ApproveCancellation(ctx context.Context, orderID int64) (*domain.OrderStatusUpdateResult, error)
-	AdminCancelOrder(ctx context.Context, orderID int64) (*domain.AdminOrderCancellationResult, error)
+	ApproveCancellation(ctx context.Context, adminID, orderID int64) (*domain.OrderStatusUpdateResult, error)
+	AdminCancelOrder(ctx context.Context, adminID, orderID int64) (*domain.AdminOrderCancellationResult, error)
 	GetCancellationRequests(ctx context.Context, params domain.CancellationRequestParams) ([]*domain.CancellationRequest, int64, error)
 }
 
@@ -45,6 +51,10 @@ type orderUseCase struct {
 	cartRepo        repository.CartRepository
 	walletRepo      repository.WalletRepository
 	paymentRepo     repository.PaymentRepository
+	offerRepo       repository.OfferRepository
+	reservationRepo repository.StockReservationRepository
+	locationRepo    repository.LocationRepository
+	allocationRule  string
 	razorpayService *razorpay.Service
 }
 
@@ -54,6 +64,10 @@ func NewOrderUseCase(orderRepo repository.OrderRepository,
 	cartRepo repository.CartRepository,
 	walletRepo repository.WalletRepository,
 	paymentRepo repository.PaymentRepository,
+	offerRepo repository.OfferRepository,
+	reservationRepo repository.StockReservationRepository,
+	locationRepo repository.LocationRepository,
+	allocationRule string,
 	razorpayKeyID, razorpaySecret string) OrderUseCase {
 	return &orderUseCase{
 		orderRepo:       orderRepo,
@@ -62,6 +76,10 @@ func NewOrderUseCase(orderRepo repository.OrderRepository,
 		cartRepo:        cartRepo,
 		walletRepo:      walletRepo,
 		paymentRepo:     paymentRepo,
+		offerRepo:       offerRepo,
+		reservationRepo: reservationRepo,
+		locationRepo:    locationRepo,
+		allocationRule:  allocationRule,
 		razorpayService: razorpay.NewService(razorpayKeyID, razorpaySecret),
 	}
 }
@@ -142,6 +160,14 @@ func (u *orderUseCase) GetOrders(ctx context.Context, page int) ([]*domain.Order
 	return u.orderRepo.GetOrders(ctx, ordersPerPage, offset)
 }
 
+func (u *orderUseCase) GetUserOrdersByCursor(ctx context.Context, userID int64, params pagination.Params) ([]*domain.Order, pagination.Page, error) {
+	return u.orderRepo.GetUserOrdersByCursor(ctx, userID, params)
+}
+
+func (u *orderUseCase) GetOrdersByCursor(ctx context.Context, params pagination.Params) ([]*domain.Order, pagination.Page, error) {
+	return u.orderRepo.GetOrdersByCursor(ctx, params)
+}
+
 func (u *orderUseCase) GetPaymentByOrderID(ctx context.Context, orderID int64) (*domain.Payment, error) {
 	return u.orderRepo.GetPaymentByOrderID(ctx, orderID)
 }
@@ -199,9 +225,10 @@ PlaceOrderRazorpay:
 - Get checkout items
 - make sure the checkout is not empty
 - Iterate through checkout items, create order items entry respectively
-- make sure stock for each product is available
 - make sure shipping address is provided
 - create order entry
+- hold the stock of the items for the order, stock is decremented only when the payment succeeds
+- stock rows are locked while reserving, every item which is short of stock is returned in InsufficientStockError
 - create payment entry
 - create order item entry
 - update checkout status
@@ -237,18 +264,19 @@ func (u *orderUseCase) PlaceOrderRazorpay(ctx context.Context, userID int64) (*d
 		return nil, utils.ErrEmptyCart
 	}
 
-	// Validate stock and calculate total
+	// Validate prices and calculate total, stock is checked with the rows locked while reserving it for the order
 	var totalAmount float64
 	for _, item := range cartItems {
-		product, err := u.productRepo.GetByID(ctx, item.ProductID)
+		price, _, err := getItemPriceAndStock(ctx, u.productRepo, u.offerRepo, item.ProductID, item.VariantID)
 		if err != nil {
 			log.Printf("error while retrieving product details: %v", err)
 			return nil, err
 		}
-		if product.StockQuantity < item.Quantity {
-			return nil, utils.ErrInsufficientStock
+		// An offer started or ended after checkout, the checkout amount is no longer valid
+		if price != item.Price {
+			return nil, utils.ErrPriceChanged
 		}
-		totalAmount += float64(item.Quantity) * product.Price
+		totalAmount += float64(item.Quantity) * price
 	}
 
 	// make sure proper shipping address is provided
@@ -279,6 +307,13 @@ func (u *orderUseCase) PlaceOrderRazorpay(ctx context.Context, userID int64) (*d
 	}
 	order.ID = orderID
 
+	// Hold the stock till the payment is completed, it's released if the payment fails or doesn't happen in time
+	err = u.reservationRepo.ReserveForOrderTx(ctx, tx, checkout.ID, order.ID, userID, cartItems, now.Add(utils.PaymentReservationTTL))
+	if err != nil {
+		log.Printf("failed to reserve stock for the order : %v", err)
+		return nil, err
+	}
+
 	// Create payment entry
 	payment := &domain.Payment{
 		OrderID:       order.ID,
@@ -296,11 +331,12 @@ func (u *orderUseCase) PlaceOrderRazorpay(ctx context.Context, userID int64) (*d
 		return nil, err
 	}
 
-	// Create order items and update stock
+	// Create order items
 	for _, item := range cartItems {
 		orderItem := &domain.OrderItem{
 			OrderID:   order.ID,
 			ProductID: item.ProductID,
+			VariantID: item.VariantID,
 			Quantity:  item.Quantity,
 			Price:     item.Price,
 		}
@@ -309,12 +345,6 @@ func (u *orderUseCase) PlaceOrderRazorpay(ctx context.Context, userID int64) (*d
 			log.Printf("failed to add order item: %v", err)
 			return nil, err
 		}
-
-		err = u.productRepo.UpdateStockTx(ctx, tx, item.ProductID, -item.Quantity)
-		if err != nil {
-			log.Printf("failed to update product stock: %v", err)
-			return nil, err
-		}
 	}
 
 	// Mark the checkout as deleted
@@ -422,8 +452,11 @@ PlaceOrderCOD:
 - Validate the final amount (should not exceed max cod limit)
 - Get checkout items
 - If there are no checkout items then you can't place the order
-- Verify the stock is available for all the checkout items
+- Verify the prices of the checkout items
 - Create order entry in orders table
+- Reserve the stock for the order, the reservation is committed right away as the order needs no payment
+- Stock rows are locked in product order and decremented only when enough stock is left
+- Every item which is short of stock is returned in InsufficientStockError
 - Create order_items entry (make sure the stock is getting updated for each order item)
 - Create payment entry in payments table
 - Update checkout status to completed
@@ -470,16 +503,15 @@ func (u *orderUseCase) PlaceOrderCOD(ctx context.Context, userID int64) (*domain
 		return nil, utils.ErrEmptyCart
 	}
 
-	// Verify that all items have sufficient stock
+	// Verify the prices, stock is checked with the rows locked while reserving it for the order
 	for _, item := range cartItems {
-		product, err := u.productRepo.GetByID(ctx, item.ProductID)
+		price, _, err := getItemPriceAndStock(ctx, u.productRepo, u.offerRepo, item.ProductID, item.VariantID)
 		if err != nil {
-			log.Printf("error while retrieving product details to check stock availability : %v", err)
+			log.Printf("error while retrieving product details : %v", err)
 			return nil, err
 		}
-		if product.StockQuantity < item.Quantity {
-			log.Printf("insufficient stock for the product id : %v", product.ID)
-			return nil, utils.ErrInsufficientStock
+		if price != item.Price {
+			return nil, utils.ErrPriceChanged
 		}
 	}
 
@@ -511,6 +543,13 @@ func (u *orderUseCase) PlaceOrderCOD(ctx context.Context, userID int64) (*domain
 	}
 	order.ID = orderID
 
+	// Reserve the stock, so that the stock held by the other checkouts is not sold
+	err = u.reservationRepo.ReserveForOrderTx(ctx, tx, checkout.ID, order.ID, userID, cartItems, now.Add(utils.CheckoutReservationTTL))
+	if err != nil {
+		log.Printf("error while reserving stock for the order : %v", err)
+		return nil, err
+	}
+
 	// Create a payment record
 	payment := &domain.Payment{
 		OrderID:       order.ID,
@@ -532,6 +571,7 @@ func (u *orderUseCase) PlaceOrderCOD(ctx context.Context, userID int64) (*domain
 		orderItem := &domain.OrderItem{
 			OrderID:   order.ID,
 			ProductID: item.ProductID,
+			VariantID: item.VariantID,
 			Quantity:  item.Quantity,
 			Price:     item.Price,
 		}
@@ -540,12 +580,13 @@ func (u *orderUseCase) PlaceOrderCOD(ctx context.Context, userID int64) (*domain
 			log.Printf("error while adding order item entry in order_items table : %v", err)
 			return nil, err
 		}
+	}
 
-		err = u.productRepo.UpdateStockTx(ctx, tx, item.ProductID, -item.Quantity)
-		if err != nil {
-			log.Printf("failed to update product stock: %v", err)
-			return nil, err
-		}
+	// Decrement the stock of the order items by committing the reservations
+	err = u.commitReservedStockTx(ctx, tx, order.ID)
+	if err != nil {
+		log.Printf("failed to update product stock: %v", err)
+		return nil, err
 	}
 
 	// Mark the checkout as deleted
@@ -603,7 +644,7 @@ func (u *orderUseCase) getOrderWithItems(ctx context.Context, userID, orderID in
 	for i, item := range items {
 		product, err := u.productRepo.GetByID(ctx, item.ProductID)
 		if err != nil {
-			log.Printf("failed to get product details for item %d: %w", item.ID, err)
+			log.Printf("failed to get product details for item %d: %v", item.ID, err)
 			return nil, err
 		}
 		items[i].ProductName = product.Name
@@ -642,11 +683,6 @@ func (u *orderUseCase) GenerateInvoice(ctx context.Context, userID, orderID int6
 		return nil, utils.ErrUnpaidOrder
 	}
 
-	// If order status is cancelled , no need to generate invoice
-	if order.OrderStatus == utils.OrderStatusCancelled && order.OrderStatus == utils.OrderStatusPendingCancellation {
-		return nil, utils.ErrOrderCancelled
-	}
-
 	// No items in orders
 	if len(order.Items) == 0 {
 		return nil, utils.ErrEmptyOrder
@@ -822,7 +858,14 @@ func (u *orderUseCase) GetPaymentByRazorpayOrderID(ctx context.Context, razorpay
 	return payment, nil
 }
 
-func (u *orderUseCase) VerifyAndUpdateRazorpayPayment(ctx context.Context, input domain.RazorpayPaymentInput) error {
+/*
+VerifyAndUpdateRazorpayPayment:
+- Verify the payment signature
+- Mark the payment as paid, confirm the order and decrement the stock held for it in one transaction
+- The payment is already captured, so a shortage of stock doesn't fail the request
+- When the stock left can't cover the order, or the order was cancelled before the payment, the order is cancelled and the amount is refunded to the wallet
+*/
+func (u *orderUseCase) VerifyAndUpdateRazorpayPayment(ctx context.Context, input domain.RazorpayPaymentInput) (*domain.OrderStatusUpdateResult, error) {
 
 	// Verify the payment signature
 	attributes := map[string]interface{}{
@@ -832,35 +875,361 @@ func (u *orderUseCase) VerifyAndUpdateRazorpayPayment(ctx context.Context, input
 	}
 	if err := u.razorpayService.VerifyPaymentSignature(attributes); err != nil {
 		log.Printf("error while verifying payment signature : %v", err)
-		return err
+		return nil, err
 	}
 
 	// Get the payment by Razorpay order ID
 	payment, err := u.paymentRepo.GetByRazorpayOrderID(ctx, input.OrderID)
 	if err != nil {
 		log.Printf("error while retrieving payment using order id  : %v", err)
-		return err
+		return nil, err
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("failed to start transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The payment is read again with its row locked, a concurrent verification of it waits for this one to finish
+	payment, err = u.paymentRepo.GetByIDForUpdateTx(ctx, tx, payment.ID)
+	if err != nil {
+		log.Printf("error while locking the payment : %v", err)
+		return nil, err
+	}
+
+	order, err := u.orderRepo.GetByIDTx(ctx, tx, payment.OrderID)
+	if err != nil {
+		log.Printf("error while retrieving the order of the payment : %v", err)
+		return nil, err
+	}
+
+	result := &domain.OrderStatusUpdateResult{
+		OrderID:      order.ID,
+		RefundStatus: utils.RefundStatusNotApplicable,
+	}
+
+	// The payment was verified before, nothing left to update
+	if payment.Status == utils.PaymentStatusPaid || payment.Status == utils.PaymentStatusRefunded {
+		result.UpdatedOrderStatus = order.OrderStatus
+		return result, nil
 	}
 
 	// Update the payment status
 	payment.Status = utils.PaymentStatusPaid
 	payment.RazorpayPaymentID = input.PaymentID
 	payment.RazorpaySignature = input.Signature
+	payment.UpdatedAt = time.Now().UTC()
 
-	if err := u.paymentRepo.UpdatePayment(ctx, payment); err != nil {
+	if err := u.paymentRepo.UpdatePaymentTx(ctx, tx, payment); err != nil {
 		log.Printf("error while updating payment : %v", err)
-		return err
+		return nil, err
 	}
 
-	// Update the order status
-	if err := u.orderRepo.UpdateOrderStatus(ctx, payment.OrderID, utils.OrderStatusConfirmed); err != nil {
-		log.Printf("error while update order status : %v", err)
+	// Payment is done, the stock held for the order is decremented now
+	if !order.IsCancelled {
+		err = u.commitReservedStockTx(ctx, tx, order.ID)
+	}
+	if order.IsCancelled || errors.Is(err, utils.ErrInsufficientStock) {
+		log.Printf("order %d can't be fulfilled after the payment, refunding the payment : %v", order.ID, err)
+		err = u.cancelPaidOrderTx(ctx, tx, order, payment)
+		if err != nil {
+			return nil, err
+		}
+		result.UpdatedOrderStatus = utils.OrderStatusCancelled
+		result.RefundStatus = utils.RefundStatusInitiated
+	} else if err != nil {
+		log.Printf("error while committing the stock of the order : %v", err)
+		return nil, err
+	} else {
+		// Update the order status
+		if err := u.orderRepo.UpdateOrderStatusTx(ctx, tx, order.ID, utils.OrderStatusConfirmed); err != nil {
+			log.Printf("error while update order status : %v", err)
+			return nil, err
+		}
+		result.UpdatedOrderStatus = utils.OrderStatusConfirmed
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+/*
+cancelPaidOrderTx:
+- Cancel the order which can't be fulfilled after its payment, the stock held for it is released
+- Refund the paid amount to the wallet of the user
+*/
+func (u *orderUseCase) cancelPaidOrderTx(ctx context.Context, tx *sql.Tx, order *domain.Order, payment *domain.Payment) error {
+	err := u.reservationRepo.ReleaseForOrderTx(ctx, tx, order.ID)
+	if err != nil {
+		log.Printf("error while releasing the stock of the order : %v", err)
 		return err
 	}
 
+	if !order.IsCancelled {
+		err = u.orderRepo.UpdateOrderStatusAndSetCancelledTx(ctx, tx, order.ID, utils.OrderStatusCancelled, utils.DeliveryStatusReturnedToSender, true)
+		if err != nil {
+			log.Printf("failed to update order status and is_cancelled in orders table : %v", err)
+			return err
+		}
+	}
+
+	err = u.processRefund(ctx, tx, order, payment)
+	if err != nil {
+		log.Printf("failed to process refund: %v", err)
+		return err
+	}
 	return nil
 }
 
+/*
+HandleRazorpayPaymentFailure:
+- The failure reported by the client is confirmed with Razorpay, the payment should belong to the razorpay order and should have failed
+- Mark the pending payment as failed
+- Release the stock held for the order, the order stays pending so that the payment can be retried
+- A paid order is left as it is
+*/
+func (u *orderUseCase) HandleRazorpayPaymentFailure(ctx context.Context, razorpayOrderID, razorpayPaymentID string) error {
+	payment, err := u.paymentRepo.GetByRazorpayOrderID(ctx, razorpayOrderID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrPaymentNotFound
+		}
+		log.Printf("error while retrieving payment using razorpay order id : %v", err)
+		return err
+	}
+	if payment.Status == utils.PaymentStatusPaid {
+		return nil
+	}
+
+	razorpayPayment, err := u.razorpayService.FetchPayment(razorpayPaymentID)
+	if err != nil {
+		log.Printf("error while fetching the payment from razorpay : %v", err)
+		return utils.ErrRazorpayServiceUnavailable
+	}
+	if razorpayPayment.OrderID != razorpayOrderID {
+		return utils.ErrPaymentNotFound
+	}
+	if razorpayPayment.Status != utils.RazorpayPaymentStatusFailed {
+		return utils.ErrPaymentNotFailed
+	}
+
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("failed to start transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// The payment may have been verified meanwhile, it is read again with its row locked
+	payment, err = u.paymentRepo.GetByIDForUpdateTx(ctx, tx, payment.ID)
+	if err != nil {
+		log.Printf("error while locking the payment : %v", err)
+		return err
+	}
+	if payment.Status == utils.PaymentStatusPaid || payment.Status == utils.PaymentStatusRefunded {
+		return nil
+	}
+
+	err = u.paymentRepo.UpdateStatusTx(ctx, tx, payment.ID, utils.PaymentStatusFailed)
+	if err != nil {
+		log.Printf("error while updating payment status : %v", err)
+		return err
+	}
+
+	err = u.reservationRepo.ReleaseForOrderTx(ctx, tx, payment.OrderID)
+	if err != nil {
+		log.Printf("error while releasing the stock of the order : %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+/*
+HoldStockForPayment:
+- The stock of a pending order is held again before its payment is retried
+- Reservations released by a failed payment or expired are renewed only when the stock is still available
+- Returns an InsufficientStockError listing every item which is gone, the payment isn't retried then
+*/
+func (u *orderUseCase) HoldStockForPayment(ctx context.Context, orderID int64) error {
+	tx, err := u.orderRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("failed to start transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	order, err := u.orderRepo.GetByIDTx(ctx, tx, orderID)
+	if err != nil {
+		log.Printf("error while retrieving the order : %v", err)
+		return err
+	}
+	if order.IsCancelled {
+		return utils.ErrCancelledOrder
+	}
+	if order.OrderStatus != utils.OrderStatusPending {
+		return nil
+	}
+
+	err = u.reservationRepo.RenewForOrderTx(ctx, tx, orderID, time.Now().UTC().Add(utils.PaymentReservationTTL))
+	if err != nil {
+		log.Printf("failed to hold the stock of the order again : %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+/*
+commitReservedStockTx:
+- Lock the reservations of the order which are not committed yet
+- The stock of every reserved item is allocated from the locations by the allocation rule before anything is decremented
+- When the stock left doesn't cover an item, nothing is decremented and every item which is short is returned
+- Otherwise the stock of the allocations is decremented and the reservations are marked as committed
+*/
+func (u *orderUseCase) commitReservedStockTx(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	reservations, err := u.reservationRepo.LockForOrderTx(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	if len(reservations) == 0 {
+		return nil
+	}
+
+	pincode, err := u.shippingPincodeTx(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	var allocations []*domain.OrderAllocation
+	var shortages []utils.StockShortage
+	for _, res := range reservations {
+		itemAllocations, err := u.allocateStockTx(ctx, tx, res.ProductID, res.VariantID, res.Quantity, pincode)
+		var stockErr *utils.InsufficientStockError
+		if errors.As(err, &stockErr) {
+			shortages = append(shortages, stockErr.Items...)
+			continue
+		}
+		if err != nil {
+			log.Printf("failed to allocate stock for product %d: %v", res.ProductID, err)
+			return err
+		}
+		allocations = append(allocations, itemAllocations...)
+	}
+
+	if len(shortages) > 0 {
+		log.Printf("insufficient stock to commit the reservations of order %d", orderID)
+		return &utils.InsufficientStockError{Items: shortages}
+	}
+
+	// every reservation of the order is made by the user placing it
+	userID := reservations[0].UserID
+	now := time.Now().UTC()
+	for _, allocation := range allocations {
+		change := orderStockChange(utils.StockMovementReasonOrder, orderID, utils.StockActorUser, userID)
+		change.LocationID = &allocation.LocationID
+		err = u.productRepo.UpdateStockTx(ctx, tx, allocation.ProductID, allocation.VariantID, -allocation.Quantity, change)
+		if err != nil {
+			log.Printf("failed to update stock for product %d: %v", allocation.ProductID, err)
+			return err
+		}
+
+		allocation.OrderID = orderID
+		allocation.Status = utils.OrderAllocationStatusAllocated
+		allocation.CreatedAt = now
+		err = u.locationRepo.CreateAllocationTx(ctx, tx, allocation)
+		if err != nil {
+			return err
+		}
+	}
+
+	return u.reservationRepo.CommitForOrderTx(ctx, tx, orderID)
+}
+
+/*
+allocateStockTx:
+- Lock the item, then its stock at the active locations
+- Rank the locations by the allocation rule and return the quantity to take from each location
+- The allocations are recorded when the stock is decremented, so that cancelling or returning the order restocks the same locations
+- When the active locations can't cover the quantity, the stock they hold is reported as available
+*/
+func (u *orderUseCase) allocateStockTx(ctx context.Context, tx *sql.Tx, productID int64, variantID *int64, quantity int, pincode string) ([]*domain.OrderAllocation, error) {
+	name, err := u.locationRepo.LockItemStockTx(ctx, tx, productID, variantID)
+	if err == utils.ErrProductNotFound || err == utils.ErrVariantNotFound {
+		return nil, nil // nothing to decrement, same as the stock update of a missing item
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stocks, err := u.locationRepo.GetAllocatableStockTx(ctx, tx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	rankLocations(stocks, u.allocationRule, pincode)
+
+	allocations, remaining := allocateStock(stocks, quantity)
+	if remaining > 0 {
+		return nil, &utils.InsufficientStockError{Items: []utils.StockShortage{{
+			ProductID:   productID,
+			ProductName: name,
+			VariantID:   variantID,
+			Requested:   quantity,
+			Available:   quantity - remaining,
+		}}}
+	}
+
+	for _, allocation := range allocations {
+		allocation.ProductID = productID
+		allocation.VariantID = variantID
+	}
+	return allocations, nil
+}
+
+/*
+shippingPincodeTx:
+- Get the pincode the order is shipped to, it's only needed by the nearest allocation rule
+- Without a shipping address the stock is allocated by the priority of the locations
+*/
+func (u *orderUseCase) shippingPincodeTx(ctx context.Context, tx *sql.Tx, orderID int64) (string, error) {
+	if u.allocationRule != utils.AllocationRuleNearest {
+		return "", nil
+	}
+
+	order, err := u.orderRepo.GetByIDTx(ctx, tx, orderID)
+	if err != nil {
+		log.Printf("failed to get the order to allocate its stock: %v", err)
+		return "", err
+	}
+
+	address, err := u.orderRepo.GetShippingAddress(ctx, order.ShippingAddressID)
+	if err == utils.ErrAddressNotFound {
+		return "", nil
+	}
+	if err != nil {
+		log.Printf("failed to get the shipping address to allocate the stock: %v", err)
+		return "", err
+	}
+	return address.PinCode, nil
+}
+
+// orderStockChange is the stock change made for the order by the given actor
+func orderStockChange(reason string, orderID int64, actorType string, actorID int64) domain.StockChange {
+	return domain.StockChange{
+		Reason:        reason,
+		ReferenceType: utils.StockReferenceOrder,
+		ReferenceID:   &orderID,
+		ActorType:     actorType,
+		ActorID:       &actorID,
+	}
+}
+
 /*
 CancelOrder:
 - Begins transaction
@@ -941,7 +1310,7 @@ func (u *orderUseCase) CancelOrder(ctx context.Context, userID, orderID int64) (
 		}
 
 		// Immediate cancellation means we need to update stock quantity respective to cancelled order
-		err = u.updateStockForCancelledOrder(ctx, tx, orderID)
+		err = u.updateStockForCancelledOrder(ctx, tx, orderID, utils.StockActorUser, userID)
 		if err != nil {
 			log.Printf("failed to update stock: %v", err)
 			return nil, err
@@ -984,10 +1353,27 @@ func (u *orderUseCase) CancelOrder(ctx context.Context, userID, orderID int64) (
 
 /*
 updateStockForCancelledOrder:
-- Get order items
-- Update stock quantity in the products table for respective order items
+- If the order only holds reservations (unpaid razorpay order), releasing them is enough, stock was never decremented
+- Restock the locations the stock of the order was allocated from
+- Orders without allocations restock their order items at the primary location
+- The cancelling user or admin is recorded as the actor
 */
-func (u *orderUseCase) updateStockForCancelledOrder(ctx context.Context, tx *sql.Tx, orderID int64) error {
+func (u *orderUseCase) updateStockForCancelledOrder(ctx context.Context, tx *sql.Tx, orderID int64, actorType string, actorID int64) error {
+	committed, err := u.reservationRepo.IsStockCommittedTx(ctx, tx, orderID)
+	if err != nil {
+		log.Printf("failed to check if the stock of the order is committed: %v", err)
+		return err
+	}
+	if !committed {
+		return u.reservationRepo.ReleaseForOrderTx(ctx, tx, orderID)
+	}
+
+	change := orderStockChange(utils.StockMovementReasonCancellation, orderID, actorType, actorID)
+	restocked, err := restockAllocationsTx(ctx, tx, u.locationRepo, u.productRepo, orderID, change)
+	if err != nil || restocked {
+		return err
+	}
+
 	// Get order items from order_items table
 	orderItems, err := u.orderRepo.GetOrderItemsTx(ctx, tx, orderID)
 	if err != nil {
@@ -996,7 +1382,7 @@ func (u *orderUseCase) updateStockForCancelledOrder(ctx context.Context, tx *sql
 	}
 
 	for _, item := range orderItems {
-		err = u.productRepo.UpdateStockTx(ctx, tx, item.ProductID, item.Quantity)
+		err = u.productRepo.UpdateStockTx(ctx, tx, item.ProductID, item.VariantID, item.Quantity, change)
 		if err != nil {
 			log.Printf("failed to update stock for product %d: %v", item.ProductID, err)
 			return err
@@ -1020,7 +1406,7 @@ ApproveCancellation:
 - Update stock_quantity of the products which are part of the order items in this cancelled order
 - Update cancellation related details in cancellation_requests table
 */
-func (u *orderUseCase) ApproveCancellation(ctx context.Context, orderID int64) (*domain.OrderStatusUpdateResult, error) {
+func (u *orderUseCase) ApproveCancellation(ctx context.Context, adminID, orderID int64) (*domain.OrderStatusUpdateResult, error) {
 	// Start a database transaction
 	tx, err := u.orderRepo.BeginTx(ctx)
 	if err != nil {
@@ -1087,7 +1473,7 @@ func (u *orderUseCase) ApproveCancellation(ctx context.Context, orderID int64) (
 	}
 
 	// Update stock quantity of products which are part of the order items in cancelled order
-	err = u.updateStockForCancelledOrder(ctx, tx, orderID)
+	err = u.updateStockForCancelledOrder(ctx, tx, orderID, utils.StockActorAdmin, adminID)
 	if err != nil {
 		log.Printf("failed to update stock quantity : %v", err)
 		return nil, err
@@ -1201,7 +1587,7 @@ AdminCancelOrder:
 - Update cancellation request table (is_stock_updated)
 - Update order status, delivery status, is_cancelled
 */
-func (u *orderUseCase) AdminCancelOrder(ctx context.Context, orderID int64) (*domain.AdminOrderCancellationResult, error) {
+func (u *orderUseCase) AdminCancelOrder(ctx context.Context, adminID, orderID int64) (*domain.AdminOrderCancellationResult, error) {
 	// Start a database transaction
 	tx, err := u.orderRepo.BeginTx(ctx)
 	if err != nil {
@@ -1276,7 +1662,7 @@ func (u *orderUseCase) AdminCancelOrder(ctx context.Context, orderID int64) (*do
 	}
 
 	// Update stock quantities of the products which are part of the order items
-	err = u.updateStockForCancelledOrder(ctx, tx, orderID)
+	err = u.updateStockForCancelledOrder(ctx, tx, orderID, utils.StockActorAdmin, adminID)
 	if err != nil {
 		log.Printf("failed to update stock quantity for products which are part of cancelled order items: %v", err)
 		return nil, err
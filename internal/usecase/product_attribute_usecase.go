@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/validator"
+)
+
+type ProductAttributeUseCase interface {
+	CreateAttribute(ctx context.Context, subCategoryID int, attribute *domain.ProductAttribute) error
+	GetAttributes(ctx context.Context, subCategoryID int) ([]*domain.ProductAttribute, error)
+	UpdateAttribute(ctx context.Context, attribute *domain.ProductAttribute) (*domain.ProductAttribute, error)
+	DeleteAttribute(ctx context.Context, attributeID int) error
+	SetProductAttributes(ctx context.Context, productID int64, values map[string]interface{}) ([]*domain.ProductAttributeValue, error)
+	GetProductAttributes(ctx context.Context, productID int64) ([]*domain.ProductAttributeValue, error)
+}
+
+type productAttributeUseCase struct {
+	attributeRepo   repository.ProductAttributeRepository
+	subCategoryRepo repository.SubCategoryRepository
+	productRepo     repository.ProductRepository
+}
+
+func NewProductAttributeUseCase(attributeRepo repository.ProductAttributeRepository, subCategoryRepo repository.SubCategoryRepository, productRepo repository.ProductRepository) ProductAttributeUseCase {
+	return &productAttributeUseCase{
+		attributeRepo:   attributeRepo,
+		subCategoryRepo: subCategoryRepo,
+		productRepo:     productRepo,
+	}
+}
+
+/*
+CreateAttribute:
+- Make sure the sub category exists
+- Validate the attribute, select attributes need the list of allowed values
+- Slug of the attribute is used as the filter key of the product listing
+- Slug should be unique among the attributes of the sub categories above and below it, they apply to the same products
+*/
+func (u *productAttributeUseCase) CreateAttribute(ctx context.Context, subCategoryID int, attribute *domain.ProductAttribute) error {
+	_, err := u.subCategoryRepo.GetByID(ctx, subCategoryID)
+	if err != nil {
+		return err
+	}
+
+	normalizeProductAttribute(attribute)
+	err = validator.ValidateProductAttribute(attribute)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	attribute.SubCategoryID = subCategoryID
+	attribute.Slug = utils.GenerateSlug(attribute.Name)
+	attribute.CreatedAt = now
+	attribute.UpdatedAt = now
+
+	exists, err := u.attributeRepo.SlugExistsInTree(ctx, subCategoryID, attribute.Slug, 0)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.ErrAttributeSlugInTree
+	}
+
+	return u.attributeRepo.Create(ctx, attribute)
+}
+
+// GetAttributes returns the attributes of the products in the sub category, including the ones inherited from the sub categories above it
+func (u *productAttributeUseCase) GetAttributes(ctx context.Context, subCategoryID int) ([]*domain.ProductAttribute, error) {
+	_, err := u.subCategoryRepo.GetByID(ctx, subCategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.attributeRepo.GetForSubCategory(ctx, subCategoryID)
+}
+
+/*
+UpdateAttribute:
+- Update the name, allowed values and filterability of the attribute
+- Slug of the renamed attribute should stay unique among the attributes of the sub categories above and below it
+- Allowed values set on products can't be removed
+*/
+func (u *productAttributeUseCase) UpdateAttribute(ctx context.Context, attribute *domain.ProductAttribute) (*domain.ProductAttribute, error) {
+	existing, err := u.attributeRepo.GetByID(ctx, attribute.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// type of an attribute is kept as it is, the values already set on products depend on it
+	existing.Name = attribute.Name
+	existing.AllowedValues = attribute.AllowedValues
+	existing.IsFilterable = attribute.IsFilterable
+	normalizeProductAttribute(existing)
+	err = validator.ValidateProductAttribute(existing)
+	if err != nil {
+		return nil, err
+	}
+	existing.Slug = utils.GenerateSlug(existing.Name)
+
+	exists, err := u.attributeRepo.SlugExistsInTree(ctx, existing.SubCategoryID, existing.Slug, existing.ID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, utils.ErrAttributeSlugInTree
+	}
+
+	err = u.attributeRepo.Update(ctx, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.UpdatedAt = time.Now().UTC()
+	return existing, nil
+}
+
+func (u *productAttributeUseCase) DeleteAttribute(ctx context.Context, attributeID int) error {
+	return u.attributeRepo.Delete(ctx, attributeID)
+}
+
+/*
+SetProductAttributes:
+- Get the product and the attributes which apply to its sub category
+- Each value is validated against the type of its attribute, null value removes the attribute from the product
+- Values of the product are replaced with the given values
+*/
+func (u *productAttributeUseCase) SetProductAttributes(ctx context.Context, productID int64, values map[string]interface{}) ([]*domain.ProductAttributeValue, error) {
+	product, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := u.attributeRepo.GetForSubCategory(ctx, product.SubCategoryID)
+	if err != nil {
+		return nil, err
+	}
+	attributesBySlug := make(map[string]*domain.ProductAttribute, len(attributes))
+	for _, attribute := range attributes {
+		attributesBySlug[attribute.Slug] = attribute
+	}
+
+	productValues := make(map[int]string, len(values))
+	for slug, value := range values {
+		attribute, ok := attributesBySlug[slug]
+		if !ok {
+			return nil, utils.ErrAttributeNotApplicable
+		}
+		if value == nil {
+			continue
+		}
+		productValues[attribute.ID], err = validator.ValidateAttributeValue(attribute, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = u.attributeRepo.SetProductValues(ctx, productID, productValues)
+	if err != nil {
+		log.Printf("error while setting product attribute values : %v", err)
+		return nil, err
+	}
+
+	return u.attributeRepo.GetProductValues(ctx, productID)
+}
+
+func (u *productAttributeUseCase) GetProductAttributes(ctx context.Context, productID int64) ([]*domain.ProductAttributeValue, error) {
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.attributeRepo.GetProductValues(ctx, productID)
+}
+
+func normalizeProductAttribute(attribute *domain.ProductAttribute) {
+	attribute.Name = strings.ToLower(strings.TrimSpace(attribute.Name))
+	attribute.Type = strings.ToLower(strings.TrimSpace(attribute.Type))
+	for i, value := range attribute.AllowedValues {
+		attribute.AllowedValues[i] = strings.TrimSpace(value)
+	}
+	if attribute.AllowedValues == nil {
+		attribute.AllowedValues = []string{}
+	}
+}
@@ -3,47 +3,71 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"mime/multipart"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
-	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/cloudinary"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/pagination"
+	productcatalog "github.com/mohamedfawas/rmshop-clean-architecture/pkg/product_catalog"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/storage"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/validator"
 )
 
 type ProductUseCase interface {
-	CreateProduct(ctx context.Context, product *domain.Product) error
-	UpdateProduct(ctx context.Context, productID int64, updateFields map[string]interface{}) (*domain.Product, error)
+	CreateProduct(ctx context.Context, adminID int64, product *domain.Product) error
+	UpdateProduct(ctx context.Context, adminID, productID int64, updateFields map[string]interface{}) (*domain.Product, error)
 	GetProductByID(ctx context.Context, id int64) (*domain.Product, error)
 	SoftDeleteProduct(ctx context.Context, id int64) error
 	AddImage(ctx context.Context, productID int64, files []multipart.File, fileKeys []string, fileHeaders []multipart.FileHeader, isPrimary bool) error
 	DeleteImage(ctx context.Context, productID int64, imageURL string) error
 	AddImages(ctx context.Context, productID int64, files []multipart.File, headers []*multipart.FileHeader, isPrimaryFlags []bool) error
 	DeleteProductImage(ctx context.Context, productID, imageID int64) error
+	ReorderImages(ctx context.Context, productID int64, order []domain.ProductImageOrder) ([]*domain.ProductImage, error)
 	GetAllProducts(ctx context.Context) ([]*domain.Product, error)
 	GetProducts(ctx context.Context, params domain.ProductQueryParams) ([]*domain.Product, int64, error)
+	GetProductFacets(ctx context.Context, params domain.ProductQueryParams) (*domain.ProductFacets, error)
+	GetProductsByCursor(ctx context.Context, params domain.ProductQueryParams, page pagination.Params) ([]*domain.Product, pagination.Page, error)
+	ImportProducts(ctx context.Context, adminID int64, file io.Reader, format string) (*domain.ProductImportResult, error)
+	ExportProducts(ctx context.Context, format string) ([]byte, error)
 	GetPublicProductByID(ctx context.Context, id int64) (*domain.PublicProduct, error)
+	GetPublicProductBySlug(ctx context.Context, slug string) (*domain.PublicProduct, string, error)
+	CreateVariant(ctx context.Context, adminID, productID int64, variant *domain.ProductVariant) error
+	GetVariants(ctx context.Context, productID int64) ([]*domain.ProductVariant, error)
+	UpdateVariant(ctx context.Context, adminID, productID, variantID int64, updateFields map[string]interface{}) (*domain.ProductVariant, error)
+	GetPriceHistory(ctx context.Context, productID int64) ([]*domain.ProductPriceHistory, error)
+	DeleteVariant(ctx context.Context, adminID, productID, variantID int64) error
 }
 
 type productUseCase struct {
 	productRepo     repository.ProductRepository
 	subCategoryRepo repository.SubCategoryRepository
-	cloudinary      *cloudinary.CloudinaryService
+	brandRepo       repository.BrandRepository
+	offerRepo       repository.OfferRepository
+	questionRepo    repository.QuestionRepository
+	imageStorage    storage.ImageStorage
 }
 
-func NewProductUseCase(productRepo repository.ProductRepository, subCategoryRepo repository.SubCategoryRepository, cloudinary *cloudinary.CloudinaryService) ProductUseCase {
+// productQuestionsPreviewLimit is the number of questions shown with the product details, rest are paginated separately
+const productQuestionsPreviewLimit = 5
+
+func NewProductUseCase(productRepo repository.ProductRepository, subCategoryRepo repository.SubCategoryRepository, brandRepo repository.BrandRepository, offerRepo repository.OfferRepository, questionRepo repository.QuestionRepository, imageStorage storage.ImageStorage) ProductUseCase {
 	return &productUseCase{
 		productRepo:     productRepo,
 		subCategoryRepo: subCategoryRepo,
-		cloudinary:      cloudinary,
+		brandRepo:       brandRepo,
+		offerRepo:       offerRepo,
+		questionRepo:    questionRepo,
+		imageStorage:    imageStorage,
 	}
 }
 
-func (u *productUseCase) CreateProduct(ctx context.Context, product *domain.Product) error {
+func (u *productUseCase) CreateProduct(ctx context.Context, adminID int64, product *domain.Product) error {
 	// Check if product name already exists
 	exists, err := u.productRepo.NameExists(ctx, product.Name)
 	if err != nil {
@@ -69,6 +93,29 @@ func (u *productUseCase) CreateProduct(ctx context.Context, product *domain.Prod
 		return utils.ErrInvalidSubCategory
 	}
 
+	// brand is optional, if given make sure it exists
+	if product.BrandID != nil {
+		_, err = u.brandRepo.GetByID(ctx, *product.BrandID)
+		if err != nil {
+			if err == utils.ErrBrandNotFound {
+				return utils.ErrInvalidBrand
+			}
+			return err
+		}
+	}
+
+	// product goes live right away unless a status is given
+	if product.Status == "" {
+		product.Status = utils.ProductStatusActive
+	}
+	err = validator.ValidateProductStatus(product.Status, product.PublishAt)
+	if err != nil {
+		return err
+	}
+	if product.Status != utils.ProductStatusScheduled {
+		product.PublishAt = nil
+	}
+
 	// Generate slug
 	slug := fmt.Sprintf("%s/%s", subCategory.Slug,
 		utils.GenerateSlug(product.Name))
@@ -87,8 +134,7 @@ func (u *productUseCase) CreateProduct(ctx context.Context, product *domain.Prod
 	product.UpdatedAt = now
 
 	// Create the product
-	err = u.productRepo.Create(ctx, product)
-	return nil
+	return u.productRepo.Create(ctx, product, adminID)
 }
 
 func (u *productUseCase) ensureUniqueSlug(ctx context.Context, slug string) (string, error) {
@@ -122,6 +168,19 @@ func (u *productUseCase) GetProductByID(ctx context.Context, id int64) (*domain.
 	return product, nil
 }
 
+// hasSlugBase reports whether the slug is the base slug, or the base slug made unique with a number
+func hasSlugBase(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
+
 func (u *productUseCase) SoftDeleteProduct(ctx context.Context, id int64) error {
 	// check if the product exists
 	_, err := u.productRepo.GetByID(ctx, id)
@@ -142,7 +201,7 @@ func (u *productUseCase) SoftDeleteProduct(ctx context.Context, id int64) error
 	return nil
 }
 
-func (u *productUseCase) UpdateProduct(ctx context.Context, productID int64, updateFields map[string]interface{}) (*domain.Product, error) {
+func (u *productUseCase) UpdateProduct(ctx context.Context, adminID, productID int64, updateFields map[string]interface{}) (*domain.Product, error) {
 	// Get the existing product details
 	existingProduct, err := u.productRepo.GetByID(ctx, productID)
 	if err != nil {
@@ -160,7 +219,6 @@ func (u *productUseCase) UpdateProduct(ctx context.Context, productID int64, upd
 				if err != nil {
 					return nil, err
 				}
-				existingProduct.Slug = utils.GenerateSlug(existingProduct.Name)
 			}
 		case "description":
 			if description, ok := value.(string); ok {
@@ -180,6 +238,14 @@ func (u *productUseCase) UpdateProduct(ctx context.Context, productID int64, upd
 			}
 		case "stock_quantity":
 			if quantity, ok := value.(float64); ok {
+				// stock of a product with variants is derived from its variants
+				hasVariants, err := u.productRepo.HasVariants(ctx, productID)
+				if err != nil {
+					return nil, err
+				}
+				if hasVariants {
+					return nil, utils.ErrProductHasVariants
+				}
 				existingProduct.StockQuantity = int(quantity)
 				err = validator.ValidateProductStockQuantity(existingProduct.StockQuantity)
 				if err != nil {
@@ -203,6 +269,48 @@ func (u *productUseCase) UpdateProduct(ctx context.Context, productID int64, upd
 					existingProduct.SubCategoryID = newSubCategoryID
 				}
 			}
+		case "brand_id":
+			// null removes the brand of the product
+			if value == nil {
+				existingProduct.BrandID = nil
+			} else if brandID, ok := value.(float64); ok {
+				newBrandID := int(brandID)
+				_, err := u.brandRepo.GetByID(ctx, newBrandID)
+				if err != nil {
+					if err == utils.ErrBrandNotFound {
+						return nil, utils.ErrInvalidBrand
+					}
+					return nil, err
+				}
+				existingProduct.BrandID = &newBrandID
+			}
+		case "status":
+			if status, ok := value.(string); ok {
+				existingProduct.Status = strings.ToLower(strings.TrimSpace(status))
+			}
+		case "publish_at":
+			if value == nil {
+				existingProduct.PublishAt = nil
+			} else if publishAt, ok := value.(string); ok {
+				t, err := time.Parse(time.RFC3339, publishAt)
+				if err != nil {
+					return nil, utils.ErrInvalidPublishAt
+				}
+				existingProduct.PublishAt = &t
+			}
+		}
+	}
+
+	_, statusUpdated := updateFields["status"]
+	_, publishAtUpdated := updateFields["publish_at"]
+	if statusUpdated || publishAtUpdated {
+		err = validator.ValidateProductStatus(existingProduct.Status, existingProduct.PublishAt)
+		if err != nil {
+			return nil, err
+		}
+		// publish time only matters for a scheduled product
+		if existingProduct.Status != utils.ProductStatusScheduled {
+			existingProduct.PublishAt = nil
 		}
 	}
 
@@ -216,7 +324,26 @@ func (u *productUseCase) UpdateProduct(ctx context.Context, productID int64, upd
 		}
 	}
 
-	err = u.productRepo.Update(ctx, existingProduct)
+	// slug follows the name and the sub category, the repository keeps the old slug for the redirects
+	_, nameUpdated := updateFields["name"]
+	_, subCategoryUpdated := updateFields["sub_category_id"]
+	if nameUpdated || subCategoryUpdated {
+		subCategory, err := u.subCategoryRepo.GetByID(ctx, existingProduct.SubCategoryID)
+		if err != nil {
+			return nil, err
+		}
+		slug := fmt.Sprintf("%s/%s", subCategory.Slug, utils.GenerateSlug(existingProduct.Name))
+		if !hasSlugBase(existingProduct.Slug, slug) {
+			existingProduct.Slug, err = u.ensureUniqueSlug(ctx, slug)
+			if err != nil {
+				log.Printf("Failed to ensure slug uniqueness: %v", err)
+				return nil, err
+			}
+		}
+	}
+
+	// price changes are recorded against the admin making the update
+	err = u.productRepo.Update(ctx, existingProduct, adminID)
 	if err != nil {
 		return nil, err
 	}
@@ -264,8 +391,8 @@ func (u *productUseCase) AddImage(ctx context.Context, productID int64, files []
 			return utils.ErrEmptyFile
 		}
 
-		// Upload image to Cloudinary
-		imageURL, err := u.cloudinary.UploadImage(ctx, file, product.Slug)
+		// Upload image to the image storage
+		uploaded, err := u.imageStorage.UploadImage(ctx, file, product.Slug)
 		if err != nil {
 			return err
 		}
@@ -286,10 +413,10 @@ func (u *productUseCase) AddImage(ctx context.Context, productID int64, files []
 		}
 
 		// Add image to database
-		err = u.productRepo.AddImage(ctx, productID, imageURL, isPrimary && fileKeys[i] == "image_primary")
+		err = u.productRepo.AddImage(ctx, newProductImage(productID, uploaded, isPrimary && fileKeys[i] == "image_primary"))
 		if err != nil {
-			// If there's an error adding to the database, we should delete the image from Cloudinary
-			_ = u.cloudinary.DeleteImage(ctx, imageURL)
+			// If there's an error adding to the database, we should delete the image from the storage
+			_ = u.imageStorage.DeleteImage(ctx, uploaded.URL)
 			return err
 		}
 	}
@@ -297,6 +424,16 @@ func (u *productUseCase) AddImage(ctx context.Context, productID int64, files []
 	return nil
 }
 
+func newProductImage(productID int64, uploaded *storage.UploadedImage, isPrimary bool) *domain.ProductImage {
+	return &domain.ProductImage{
+		ProductID:    productID,
+		ImageURL:     uploaded.URL,
+		ThumbnailURL: uploaded.ThumbnailURL,
+		MediumURL:    uploaded.MediumURL,
+		IsPrimary:    isPrimary,
+	}
+}
+
 func (u *productUseCase) DeleteImage(ctx context.Context, productID int64, imageURL string) error {
 	// Check if the image exists and if it's primary
 	image, err := u.productRepo.GetImageByURL(ctx, productID, imageURL)
@@ -307,10 +444,10 @@ func (u *productUseCase) DeleteImage(ctx context.Context, productID int64, image
 		return err
 	}
 
-	// Delete the image from Cloudinary
-	err = u.cloudinary.DeleteImage(ctx, imageURL)
+	// Delete the image from the image storage
+	err = u.imageStorage.DeleteImage(ctx, imageURL)
 	if err != nil {
-		log.Printf("Failed to delete image from Cloudinary: %v", err)
+		log.Printf("Failed to delete image from the image storage: %v", err)
 		return err
 	}
 
@@ -348,13 +485,8 @@ func (u *productUseCase) updateProductAfterImageDeletion(ctx context.Context, pr
 		// No images left, set primary_image_id to NULL
 		return u.productRepo.UpdateProductPrimaryImage(ctx, productID, nil)
 	} else {
-		// Set the first remaining image as primary
-		newPrimaryImage := images[0]
-		err = u.productRepo.SetImageAsPrimary(ctx, productID, newPrimaryImage.ID)
-		if err != nil {
-			return err
-		}
-		return u.productRepo.UpdateProductPrimaryImage(ctx, productID, &newPrimaryImage.ID)
+		// Close the gap left in the gallery, the first remaining image becomes primary
+		return u.productRepo.ReorderImages(ctx, productID, imageOrder(images))
 	}
 }
 
@@ -382,18 +514,18 @@ func (u *productUseCase) AddImages(ctx context.Context, productID int64, files [
 			return err
 		}
 
-		// Upload image to Cloudinary
-		imageURL, err := u.cloudinary.UploadImage(ctx, file, product.Slug)
+		// Upload image to the image storage
+		uploaded, err := u.imageStorage.UploadImage(ctx, file, product.Slug)
 		if err != nil {
-			log.Printf("error while uploading file to cloudinary: %v", err)
+			log.Printf("error while uploading file to the image storage: %v", err)
 			return err
 		}
 
 		// Add image to database
-		err = u.productRepo.AddImage(ctx, productID, imageURL, isPrimaryFlags[i])
+		err = u.productRepo.AddImage(ctx, newProductImage(productID, uploaded, isPrimaryFlags[i]))
 		if err != nil {
-			// If there's an error adding to the database, we should delete the image from Cloudinary
-			_ = u.cloudinary.DeleteImage(ctx, imageURL)
+			// If there's an error adding to the database, we should delete the image from the storage
+			_ = u.imageStorage.DeleteImage(ctx, uploaded.URL)
 			return err
 		}
 	}
@@ -433,10 +565,10 @@ func (u *productUseCase) DeleteProductImage(ctx context.Context, productID, imag
 		return utils.ErrLastImage
 	}
 
-	// Delete the image from cloudinary
-	err = u.cloudinary.DeleteImage(ctx, image.ImageURL)
+	// Delete the image from the image storage
+	err = u.imageStorage.DeleteImage(ctx, image.ImageURL)
 	if err != nil {
-		log.Printf("Failed to delete image from Cloudinary : %v", err)
+		log.Printf("Failed to delete image from the image storage : %v", err)
 	}
 	// Delete the image from the database
 	err = u.productRepo.DeleteImageByID(ctx, imageID)
@@ -461,16 +593,103 @@ func (u *productUseCase) setNewPrimaryImage(ctx context.Context, productID int64
 		return err
 	}
 	if len(images) > 0 {
-		return u.productRepo.SetImageAsPrimary(ctx, productID, images[0].ID)
+		return u.productRepo.ReorderImages(ctx, productID, imageOrder(images))
 	}
 	return nil
 }
 
+// imageOrder keeps the current gallery order of the images
+func imageOrder(images []*domain.ProductImage) []domain.ProductImageOrder {
+	order := make([]domain.ProductImageOrder, len(images))
+	for i, image := range images {
+		order[i] = domain.ProductImageOrder{ID: image.ID}
+	}
+	return order
+}
+
+/*
+ReorderImages:
+- The order must list every image of the product exactly once
+- First image of the order becomes the primary image
+- Alt text of an image can be changed in the same call
+*/
+func (u *productUseCase) ReorderImages(ctx context.Context, productID int64, order []domain.ProductImageOrder) ([]*domain.ProductImage, error) {
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := u.productRepo.GetProductImages(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(order) != len(images) {
+		return nil, utils.ErrInvalidImageOrder
+	}
+
+	productImages := make(map[int64]bool, len(images))
+	for _, image := range images {
+		productImages[image.ID] = true
+	}
+	for i, entry := range order {
+		if !productImages[entry.ID] {
+			return nil, utils.ErrInvalidImageOrder
+		}
+		delete(productImages, entry.ID) // an image listed twice is not found the second time
+
+		if entry.AltText != nil {
+			altText := strings.TrimSpace(*entry.AltText)
+			if len(altText) > utils.MaxImageAltTextLength {
+				return nil, utils.ErrInvalidAltText
+			}
+			order[i].AltText = &altText
+		}
+	}
+
+	err = u.productRepo.ReorderImages(ctx, productID, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.productRepo.GetProductImages(ctx, productID)
+}
+
 func (u *productUseCase) GetAllProducts(ctx context.Context) ([]*domain.Product, error) {
 	return u.productRepo.GetAll(ctx)
 }
 
 func (u *productUseCase) GetProducts(ctx context.Context, params domain.ProductQueryParams) ([]*domain.Product, int64, error) {
+	normalizeProductQueryParams(&params)
+
+	// Call repository method
+	return u.productRepo.GetProducts(ctx, params)
+}
+
+// GetProductsByCursor returns a keyset page of the products matching the listing query, newest first.
+// The pages are keyed on the product id, so a sort order can't be asked for
+func (u *productUseCase) GetProductsByCursor(ctx context.Context, params domain.ProductQueryParams, page pagination.Params) ([]*domain.Product, pagination.Page, error) {
+	if params.Sort != "" || params.Order != "" {
+		return nil, pagination.Page{}, utils.ErrCursorWithSort
+	}
+	normalizeProductQueryParams(&params)
+
+	return u.productRepo.GetProductsByCursor(ctx, params, page)
+}
+
+// GetProductFacets returns the product counts of each filter option for the given listing query
+func (u *productUseCase) GetProductFacets(ctx context.Context, params domain.ProductQueryParams) (*domain.ProductFacets, error) {
+	normalizeProductQueryParams(&params)
+
+	facets, err := u.productRepo.GetProductFacets(ctx, params)
+	if err != nil {
+		log.Printf("error while retrieving product facets : %v", err)
+		return nil, err
+	}
+	return facets, nil
+}
+
+// normalizeProductQueryParams validates the listing query params, setting defaults for the invalid ones
+func normalizeProductQueryParams(params *domain.ProductQueryParams) {
 	// Validate and set default values
 	if params.Page < 1 {
 		params.Page = 1
@@ -482,25 +701,76 @@ func (u *productUseCase) GetProducts(ctx context.Context, params domain.ProductQ
 	}
 
 	// Validate sorting parameters
-	validSortFields := map[string]bool{"price": true, "name": true, "created_at": true, "updated_at": true}
+	validSortFields := map[string]bool{"price": true, "name": true, "created_at": true, "updated_at": true, "rating": true, "relevance": true}
 	if params.Sort != "" && !validSortFields[params.Sort] {
 		params.Sort = "created_at"
 	}
+	// search results are ordered by relevance unless asked otherwise
+	params.Search = strings.Join(strings.Fields(params.Search), " ")
+	if params.Sort == "" && params.Search != "" {
+		params.Sort = "relevance"
+	}
 	if params.Order != "asc" && params.Order != "desc" {
 		params.Order = "desc"
 	}
+	if params.MinRating < 0 || params.MinRating > 5 {
+		params.MinRating = 0
+	}
+	// discount filters are percentages
+	if params.MinDiscount < 0 || params.MinDiscount > 100 {
+		params.MinDiscount = 0
+	}
+	if params.MaxDiscount < 0 || params.MaxDiscount > 100 {
+		params.MaxDiscount = 0
+	}
+
+	// Date filters are ignored if they are not in YYYY-MM-DD format
+	for _, date := range []*string{&params.CreatedAfter, &params.CreatedBefore, &params.UpdatedAfter, &params.UpdatedBefore} {
+		if _, err := time.Parse("2006-01-02", *date); err != nil {
+			*date = ""
+		}
+	}
 
 	// Convert all string parameters to lowercase for case-insensitive search
 	params.Category = strings.ToLower(params.Category)
 	params.Subcategory = strings.ToLower(params.Subcategory)
 	params.Search = strings.ToLower(params.Search)
+	params.Size = strings.ToLower(params.Size)
+	params.Color = strings.ToLower(params.Color)
 
 	for i, category := range params.Categories {
 		params.Categories[i] = strings.ToLower(category)
 	}
 
-	// Call repository method
-	return u.productRepo.GetProducts(ctx, params)
+	// brands are filtered using their slugs
+	if len(params.Brands) == 0 && params.Brand != "" {
+		params.Brands = []string{params.Brand}
+	}
+	for i, brand := range params.Brands {
+		params.Brands[i] = utils.GenerateSlug(brand)
+	}
+}
+
+/*
+GetPublicProductBySlug:
+- Resolve the slug, old slugs of renamed products are resolved too
+- For an old slug, only the current slug is returned so that the client can be redirected
+*/
+func (u *productUseCase) GetPublicProductBySlug(ctx context.Context, slug string) (*domain.PublicProduct, string, error) {
+	slug = strings.ToLower(strings.Trim(slug, "/"))
+	productID, currentSlug, err := u.productRepo.ResolveSlug(ctx, slug)
+	if err != nil {
+		return nil, "", err
+	}
+	if currentSlug != slug {
+		return nil, currentSlug, nil
+	}
+
+	product, err := u.GetPublicProductByID(ctx, productID)
+	if err != nil {
+		return nil, "", err
+	}
+	return product, "", nil
 }
 
 func (u *productUseCase) GetPublicProductByID(ctx context.Context, id int64) (*domain.PublicProduct, error) {
@@ -512,5 +782,330 @@ func (u *productUseCase) GetPublicProductByID(ctx context.Context, id int64) (*d
 		return nil, fmt.Errorf("failed to retrieve product: %w", err)
 	}
 
+	offers, err := u.offerRepo.GetActiveOffersForProduct(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve product offers: %w", err)
+	}
+	product.OriginalPrice = product.Price
+	product.OfferPrice = applyBestOffer(product.Price, offers)
+	product.DiscountPercentage = discountPercentage(product.OriginalPrice, product.OfferPrice)
+
+	variants, err := u.productRepo.GetVariantsByProductID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve product variants: %w", err)
+	}
+	for _, v := range variants {
+		price := v.Price
+		if price == 0 {
+			price = product.Price
+		}
+		product.Variants = append(product.Variants, &domain.PublicProductVariant{
+			ID:            v.ID,
+			SKU:           v.SKU,
+			Size:          v.Size,
+			Color:         v.Color,
+			Price:         price,
+			OfferPrice:    applyBestOffer(price, offers),
+			StockQuantity: v.StockQuantity - v.ReservedQuantity,
+		})
+	}
+
+	questionParams := domain.QuestionQueryParams{
+		ProductID: id,
+		Status:    utils.QAStatusPublished,
+		Page:      1,
+		Limit:     productQuestionsPreviewLimit,
+	}
+	product.Questions, err = getQuestionPage(ctx, u.questionRepo, questionParams, utils.QAStatusPublished)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve product questions: %w", err)
+	}
+
 	return product, nil
 }
+
+/*
+CreateVariant:
+- Make sure the product exists
+- Normalize and validate variant details
+- Create the variant, product stock becomes the sum of its variant stocks
+*/
+func (u *productUseCase) CreateVariant(ctx context.Context, adminID, productID int64, variant *domain.ProductVariant) error {
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	normalizeVariant(variant)
+	if err := validator.ValidateProductVariant(variant); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	variant.ProductID = productID
+	variant.CreatedAt = now
+	variant.UpdatedAt = now
+
+	err = u.productRepo.CreateVariant(ctx, variant, adminID)
+	if err != nil {
+		log.Printf("error while creating product variant : %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (u *productUseCase) GetVariants(ctx context.Context, productID int64) ([]*domain.ProductVariant, error) {
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.productRepo.GetVariantsByProductID(ctx, productID)
+}
+
+func (u *productUseCase) UpdateVariant(ctx context.Context, adminID, productID, variantID int64, updateFields map[string]interface{}) (*domain.ProductVariant, error) {
+	variant, err := u.getProductVariant(ctx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Iterate through the fields to be updated
+	for key, value := range updateFields {
+		switch key {
+		case "sku":
+			if sku, ok := value.(string); ok {
+				variant.SKU = sku
+			}
+		case "size":
+			if size, ok := value.(string); ok {
+				variant.Size = size
+			}
+		case "color":
+			if color, ok := value.(string); ok {
+				variant.Color = color
+			}
+		case "price":
+			if price, ok := value.(float64); ok {
+				variant.Price = price
+			}
+		case "stock_quantity":
+			if quantity, ok := value.(float64); ok {
+				variant.StockQuantity = int(quantity)
+			}
+		}
+	}
+
+	normalizeVariant(variant)
+	if err := validator.ValidateProductVariant(variant); err != nil {
+		return nil, err
+	}
+
+	variant.UpdatedAt = time.Now().UTC()
+	err = u.productRepo.UpdateVariant(ctx, variant, adminID)
+	if err != nil {
+		log.Printf("error while updating product variant : %v", err)
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+// GetPriceHistory returns the price timeline of the product and its variants, latest change first
+func (u *productUseCase) GetPriceHistory(ctx context.Context, productID int64) ([]*domain.ProductPriceHistory, error) {
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.productRepo.GetPriceHistory(ctx, productID)
+}
+
+func (u *productUseCase) DeleteVariant(ctx context.Context, adminID, productID, variantID int64) error {
+	_, err := u.getProductVariant(ctx, productID, variantID)
+	if err != nil {
+		return err
+	}
+
+	return u.productRepo.SoftDeleteVariant(ctx, variantID, adminID)
+}
+
+// getProductVariant retrieves the variant and makes sure it belongs to the given product
+func (u *productUseCase) getProductVariant(ctx context.Context, productID, variantID int64) (*domain.ProductVariant, error) {
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, err := u.productRepo.GetVariantByID(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant.ProductID != productID {
+		return nil, utils.ErrVariantNotFound
+	}
+
+	return variant, nil
+}
+
+// normalizeVariant stores sku in upper case, size and color in lower case
+func normalizeVariant(variant *domain.ProductVariant) {
+	variant.SKU = strings.ToUpper(strings.TrimSpace(variant.SKU))
+	variant.Size = strings.ToLower(strings.TrimSpace(variant.Size))
+	variant.Color = strings.ToLower(strings.TrimSpace(variant.Color))
+}
+
+/*
+ImportProducts:
+- Read the rows of the csv or excel file
+- Validate each row with the product rules, resolve its sub category and brand using their slugs
+- Slug of the product is generated the same way as CreateProduct, existing products are matched on it
+- Name of the product should be unique the same way as CreateProduct, other than the product with the same slug which is updated
+- Valid rows are upserted in one transaction, invalid rows are reported with their line number
+*/
+func (u *productUseCase) ImportProducts(ctx context.Context, adminID int64, file io.Reader, format string) (*domain.ProductImportResult, error) {
+	rows, lineNumbers, err := productcatalog.ReadRows(file, format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ProductImportResult{
+		TotalRows: len(rows),
+		Errors:    []domain.ProductImportRowError{},
+	}
+	addRowError := func(line int, err error) {
+		result.Errors = append(result.Errors, domain.ProductImportRowError{Row: line, Error: err.Error()})
+	}
+
+	// sub categories and brands are looked up once per slug
+	subCategories := make(map[string]*domain.SubCategory)
+	brandIDs := make(map[string]int)
+
+	var products []*domain.Product
+	var productLines []int
+	seenSlugs := make(map[string]int)
+	seenNames := make(map[string]int)
+	now := time.Now()
+
+	for i, row := range rows {
+		line := lineNumbers[i]
+
+		price, err := strconv.ParseFloat(strings.TrimSpace(row[2]), 64)
+		if err != nil {
+			addRowError(line, utils.ErrInvalidProductPrice)
+			continue
+		}
+		stock, err := strconv.Atoi(strings.TrimSpace(row[3]))
+		if err != nil {
+			addRowError(line, utils.ErrInvalidStockQuantity)
+			continue
+		}
+
+		subCategorySlug := strings.ToLower(strings.TrimSpace(row[4]))
+		subCategory, ok := subCategories[subCategorySlug]
+		if !ok {
+			subCategory, err = u.subCategoryRepo.GetBySlug(ctx, subCategorySlug)
+			if err != nil {
+				if err != utils.ErrSubCategoryNotFound {
+					return nil, err
+				}
+				subCategory = nil
+			}
+			subCategories[subCategorySlug] = subCategory
+		}
+		if subCategory == nil {
+			addRowError(line, utils.ErrInvalidSubCategory)
+			continue
+		}
+
+		product := &domain.Product{
+			Name:          strings.ToLower(strings.TrimSpace(row[0])), // names are kept in lower case, as on create
+			Description:   strings.TrimSpace(row[1]),
+			Price:         price,
+			StockQuantity: stock,
+			SubCategoryID: subCategory.ID,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+
+		// brand is optional
+		if brandSlug := strings.ToLower(strings.TrimSpace(row[5])); brandSlug != "" {
+			brandID, ok := brandIDs[brandSlug]
+			if !ok {
+				brand, err := u.brandRepo.GetBySlug(ctx, brandSlug)
+				if err != nil && err != utils.ErrBrandNotFound {
+					return nil, err
+				}
+				if brand != nil {
+					brandID = brand.ID
+				}
+				brandIDs[brandSlug] = brandID
+			}
+			if brandID == 0 {
+				addRowError(line, utils.ErrInvalidBrand)
+				continue
+			}
+			product.BrandID = &brandID
+		}
+
+		if err := validator.ValidateProduct(product); err != nil {
+			addRowError(line, err)
+			continue
+		}
+
+		product.Slug = fmt.Sprintf("%s/%s", subCategory.Slug, utils.GenerateSlug(product.Name))
+		if firstLine, exists := seenSlugs[product.Slug]; exists {
+			addRowError(line, fmt.Errorf("duplicate of the product in row %d", firstLine))
+			continue
+		}
+		if firstLine, exists := seenNames[product.Name]; exists {
+			addRowError(line, fmt.Errorf("product name is used by the product in row %d", firstLine))
+			continue
+		}
+
+		exists, err := u.productRepo.NameExistsExceptSlug(ctx, product.Name, product.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			addRowError(line, utils.ErrDuplicateProductName)
+			continue
+		}
+		seenSlugs[product.Slug] = line
+		seenNames[product.Name] = line
+
+		products = append(products, product)
+		productLines = append(productLines, line)
+	}
+
+	if len(products) > 0 {
+		inserted, err := u.productRepo.BulkUpsert(ctx, products, adminID)
+		if err != nil {
+			log.Printf("error while importing products : %v", err)
+			return nil, err
+		}
+		for i, product := range products {
+			switch {
+			case product.ID == 0:
+				addRowError(productLines[i], utils.ErrImportProductDeleted)
+			case inserted[i]:
+				result.Created++
+			default:
+				result.Updated++
+			}
+		}
+	}
+
+	result.Failed = len(result.Errors)
+	return result, nil
+}
+
+// ExportProducts generates the csv or excel file of the catalogue, in the import file format
+func (u *productUseCase) ExportProducts(ctx context.Context, format string) ([]byte, error) {
+	catalog, err := u.productRepo.GetCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return productcatalog.Write(catalog, format)
+}
@@ -0,0 +1,332 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/validator"
+)
+
+type QuestionUseCase interface {
+	AskQuestion(ctx context.Context, userID, productID int64, question string) (*domain.ProductQuestion, error)
+	AnswerQuestion(ctx context.Context, userID, questionID int64, answer string) (*domain.ProductAnswer, error)
+	AdminAnswerQuestion(ctx context.Context, adminID, questionID int64, answer string) (*domain.ProductAnswer, error)
+	GetProductQuestions(ctx context.Context, productID int64, page, limit int) (*domain.ProductQuestionPage, error)
+	GetQuestions(ctx context.Context, params domain.QuestionQueryParams) (*domain.ProductQuestionPage, error)
+	UpdateQuestionStatus(ctx context.Context, questionID int64, status string) (*domain.ProductQuestion, error)
+	UpdateAnswerStatus(ctx context.Context, answerID int64, status string) (*domain.ProductAnswer, error)
+	UpvoteAnswer(ctx context.Context, userID, answerID int64) (int, error)
+	RemoveAnswerUpvote(ctx context.Context, userID, answerID int64) (int, error)
+}
+
+type questionUseCase struct {
+	questionRepo repository.QuestionRepository
+	productRepo  repository.ProductRepository
+	orderRepo    repository.OrderRepository
+}
+
+func NewQuestionUseCase(questionRepo repository.QuestionRepository, productRepo repository.ProductRepository, orderRepo repository.OrderRepository) QuestionUseCase {
+	return &questionUseCase{
+		questionRepo: questionRepo,
+		productRepo:  productRepo,
+		orderRepo:    orderRepo,
+	}
+}
+
+/*
+AskQuestion:
+- Validate the question
+- Make sure the product exists
+- Question is published right away, admin can hide it later
+*/
+func (u *questionUseCase) AskQuestion(ctx context.Context, userID, productID int64, question string) (*domain.ProductQuestion, error) {
+	question = strings.TrimSpace(question)
+	if err := validator.ValidateQuestion(question); err != nil {
+		return nil, err
+	}
+
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	q := &domain.ProductQuestion{
+		ProductID: productID,
+		UserID:    userID,
+		Question:  question,
+		Status:    utils.QAStatusPublished,
+		Answers:   []*domain.ProductAnswer{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err = u.questionRepo.CreateQuestion(ctx, q)
+	if err != nil {
+		log.Printf("error while adding product question : %v", err)
+		return nil, err
+	}
+
+	return q, nil
+}
+
+/*
+AnswerQuestion:
+- Validate the answer
+- Hidden questions can't be answered
+- Only users with a delivered order containing the product can answer, answer is marked as from a verified buyer
+*/
+func (u *questionUseCase) AnswerQuestion(ctx context.Context, userID, questionID int64, answer string) (*domain.ProductAnswer, error) {
+	answer = strings.TrimSpace(answer)
+	if err := validator.ValidateAnswer(answer); err != nil {
+		return nil, err
+	}
+
+	question, err := u.questionRepo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.Status != utils.QAStatusPublished {
+		return nil, utils.ErrQuestionNotFound
+	}
+
+	delivered, err := u.orderRepo.HasDeliveredOrderForProduct(ctx, userID, question.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !delivered {
+		return nil, utils.ErrAnswerNotAllowed
+	}
+
+	a := newProductAnswer(questionID, answer)
+	a.UserID = &userID
+	a.IsVerifiedBuyer = true
+
+	err = u.questionRepo.CreateAnswer(ctx, a)
+	if err != nil {
+		log.Printf("error while adding answer : %v", err)
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// AdminAnswerQuestion adds an answer from the store, admin can answer hidden questions as well
+func (u *questionUseCase) AdminAnswerQuestion(ctx context.Context, adminID, questionID int64, answer string) (*domain.ProductAnswer, error) {
+	answer = strings.TrimSpace(answer)
+	if err := validator.ValidateAnswer(answer); err != nil {
+		return nil, err
+	}
+
+	_, err := u.questionRepo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	a := newProductAnswer(questionID, answer)
+	a.AdminID = &adminID
+	a.IsAdminAnswer = true
+
+	err = u.questionRepo.CreateAnswer(ctx, a)
+	if err != nil {
+		log.Printf("error while adding admin answer : %v", err)
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// GetProductQuestions returns only the published questions of the product along with their published answers
+func (u *questionUseCase) GetProductQuestions(ctx context.Context, productID int64, page, limit int) (*domain.ProductQuestionPage, error) {
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := domain.QuestionQueryParams{
+		ProductID: productID,
+		Status:    utils.QAStatusPublished,
+		Page:      page,
+		Limit:     limit,
+	}
+	setQuestionPagination(&params)
+
+	return getQuestionPage(ctx, u.questionRepo, params, utils.QAStatusPublished)
+}
+
+// GetQuestions lists the questions for moderation, hidden answers are included
+func (u *questionUseCase) GetQuestions(ctx context.Context, params domain.QuestionQueryParams) (*domain.ProductQuestionPage, error) {
+	if params.Status != "" && !isValidQAStatus(params.Status) {
+		return nil, utils.ErrInvalidQAStatus
+	}
+	setQuestionPagination(&params)
+
+	return getQuestionPage(ctx, u.questionRepo, params, "")
+}
+
+func (u *questionUseCase) UpdateQuestionStatus(ctx context.Context, questionID int64, status string) (*domain.ProductQuestion, error) {
+	if !isValidQAStatus(status) {
+		return nil, utils.ErrInvalidQAStatus
+	}
+
+	question, err := u.questionRepo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.Status == status {
+		return nil, utils.ErrQAAlreadyInStatus
+	}
+
+	err = u.questionRepo.UpdateQuestionStatus(ctx, questionID, status)
+	if err != nil {
+		log.Printf("error while updating question status : %v", err)
+		return nil, err
+	}
+
+	question.Status = status
+	question.UpdatedAt = time.Now().UTC()
+	return question, nil
+}
+
+func (u *questionUseCase) UpdateAnswerStatus(ctx context.Context, answerID int64, status string) (*domain.ProductAnswer, error) {
+	if !isValidQAStatus(status) {
+		return nil, utils.ErrInvalidQAStatus
+	}
+
+	answer, err := u.questionRepo.GetAnswerByID(ctx, answerID)
+	if err != nil {
+		return nil, err
+	}
+	if answer.Status == status {
+		return nil, utils.ErrQAAlreadyInStatus
+	}
+
+	err = u.questionRepo.UpdateAnswerStatus(ctx, answerID, status)
+	if err != nil {
+		log.Printf("error while updating answer status : %v", err)
+		return nil, err
+	}
+
+	answer.Status = status
+	answer.UpdatedAt = time.Now().UTC()
+	return answer, nil
+}
+
+/*
+UpvoteAnswer:
+- Only published answers of published questions can be upvoted
+- User can't upvote their own answer
+- User can upvote an answer only once, returns the new upvote count
+*/
+func (u *questionUseCase) UpvoteAnswer(ctx context.Context, userID, answerID int64) (int, error) {
+	answer, err := u.getVisibleAnswer(ctx, answerID)
+	if err != nil {
+		return 0, err
+	}
+	if answer.UserID != nil && *answer.UserID == userID {
+		return 0, utils.ErrCannotUpvoteOwnAnswer
+	}
+
+	return u.questionRepo.AddAnswerUpvote(ctx, answerID, userID)
+}
+
+func (u *questionUseCase) RemoveAnswerUpvote(ctx context.Context, userID, answerID int64) (int, error) {
+	_, err := u.getVisibleAnswer(ctx, answerID)
+	if err != nil {
+		return 0, err
+	}
+
+	return u.questionRepo.RemoveAnswerUpvote(ctx, answerID, userID)
+}
+
+// getVisibleAnswer returns the answer only if both the answer and its question are published
+func (u *questionUseCase) getVisibleAnswer(ctx context.Context, answerID int64) (*domain.ProductAnswer, error) {
+	answer, err := u.questionRepo.GetAnswerByID(ctx, answerID)
+	if err != nil {
+		return nil, err
+	}
+	if answer.Status != utils.QAStatusPublished {
+		return nil, utils.ErrAnswerNotFound
+	}
+
+	question, err := u.questionRepo.GetQuestionByID(ctx, answer.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.Status != utils.QAStatusPublished {
+		return nil, utils.ErrAnswerNotFound
+	}
+
+	return answer, nil
+}
+
+/*
+getQuestionPage:
+- Retrieve a page of questions
+- Answers of all the questions in the page are retrieved in a single query and grouped by question
+*/
+func getQuestionPage(ctx context.Context, questionRepo repository.QuestionRepository, params domain.QuestionQueryParams, answerStatus string) (*domain.ProductQuestionPage, error) {
+	questions, totalCount, err := questionRepo.GetQuestions(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(questions) > 0 {
+		questionIDs := make([]int64, len(questions))
+		byID := make(map[int64]*domain.ProductQuestion, len(questions))
+		for i, q := range questions {
+			q.Answers = []*domain.ProductAnswer{}
+			questionIDs[i] = q.ID
+			byID[q.ID] = q
+		}
+
+		answers, err := questionRepo.GetAnswersByQuestionIDs(ctx, questionIDs, answerStatus)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range answers {
+			if q, ok := byID[a.QuestionID]; ok {
+				q.Answers = append(q.Answers, a)
+			}
+		}
+	}
+
+	return &domain.ProductQuestionPage{
+		Questions:  questions,
+		TotalCount: totalCount,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: (totalCount + int64(params.Limit) - 1) / int64(params.Limit),
+	}, nil
+}
+
+func newProductAnswer(questionID int64, answer string) *domain.ProductAnswer {
+	now := time.Now().UTC()
+	return &domain.ProductAnswer{
+		QuestionID: questionID,
+		Answer:     answer,
+		Status:     utils.QAStatusPublished,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+func setQuestionPagination(params *domain.QuestionQueryParams) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 10
+	} else if params.Limit > 100 {
+		params.Limit = 100
+	}
+}
+
+func isValidQAStatus(status string) bool {
+	return status == utils.QAStatusPublished || status == utils.QAStatusHidden
+}
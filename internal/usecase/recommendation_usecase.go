@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+const (
+	DefaultRecommendationLimit = 10
+	MaxRecommendationLimit     = 50
+)
+
+type RecommendationUseCase interface {
+	GetRelatedProducts(ctx context.Context, productID int64, limit int) ([]*domain.Product, error)
+	GetCartRecommendations(ctx context.Context, userID int64, limit int) ([]*domain.Product, error)
+}
+
+type recommendationUseCase struct {
+	recommendationRepo repository.RecommendationRepository
+	productRepo        repository.ProductRepository
+}
+
+func NewRecommendationUseCase(recommendationRepo repository.RecommendationRepository, productRepo repository.ProductRepository) RecommendationUseCase {
+	return &recommendationUseCase{
+		recommendationRepo: recommendationRepo,
+		productRepo:        productRepo,
+	}
+}
+
+/*
+GetRelatedProducts:
+- Only the products visible in the shop have related products
+- Co-purchased products first, then products of the same sub category
+*/
+func (u *recommendationUseCase) GetRelatedProducts(ctx context.Context, productID int64, limit int) ([]*domain.Product, error) {
+	product, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Status != utils.ProductStatusActive {
+		return nil, utils.ErrProductNotFound
+	}
+
+	return u.recommendationRepo.GetRelatedProducts(ctx, productID, normalizeRecommendationLimit(limit))
+}
+
+// GetCartRecommendations returns the products frequently bought with the items in the user's cart
+func (u *recommendationUseCase) GetCartRecommendations(ctx context.Context, userID int64, limit int) ([]*domain.Product, error) {
+	return u.recommendationRepo.GetCartRecommendations(ctx, userID, normalizeRecommendationLimit(limit))
+}
+
+func normalizeRecommendationLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultRecommendationLimit
+	}
+	if limit > MaxRecommendationLimit {
+		return MaxRecommendationLimit
+	}
+	return limit
+}
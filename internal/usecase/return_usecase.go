@@ -17,29 +17,32 @@ type ReturnUseCase interface {
 	GetUserReturnRequests(ctx context.Context, userID int64) ([]*domain.ReturnRequest, error)
 	UpdateReturnRequest(ctx context.Context, returnID int64, isApproved bool) (*domain.ReturnRequest, error)
 	InitiateRefund(ctx context.Context, returnID int64) (*domain.RefundDetails, error)
-	MarkOrderReturnedToSeller(ctx context.Context, returnID int64) (*domain.ReturnRequest, error)
+	MarkOrderReturnedToSeller(ctx context.Context, adminID, returnID int64) (*domain.ReturnRequest, error)
 	GetPendingReturnRequests(ctx context.Context, page int) ([]*domain.ReturnRequest, int64, error)
 }
 
 type returnUseCase struct {
-	returnRepo  repository.ReturnRepository
-	orderRepo   repository.OrderRepository
-	walletRepo  repository.WalletRepository
-	productRepo repository.ProductRepository
-	paymentRepo repository.PaymentRepository
+	returnRepo   repository.ReturnRepository
+	orderRepo    repository.OrderRepository
+	walletRepo   repository.WalletRepository
+	productRepo  repository.ProductRepository
+	paymentRepo  repository.PaymentRepository
+	locationRepo repository.LocationRepository
 }
 
 func NewReturnUseCase(returnRepo repository.ReturnRepository,
 	orderRepo repository.OrderRepository,
 	walletRepo repository.WalletRepository,
 	productRepo repository.ProductRepository,
-	paymentRepo repository.PaymentRepository) ReturnUseCase {
+	paymentRepo repository.PaymentRepository,
+	locationRepo repository.LocationRepository) ReturnUseCase {
 	return &returnUseCase{
-		returnRepo:  returnRepo,
-		orderRepo:   orderRepo,
-		walletRepo:  walletRepo,
-		productRepo: productRepo,
-		paymentRepo: paymentRepo,
+		returnRepo:   returnRepo,
+		orderRepo:    orderRepo,
+		walletRepo:   walletRepo,
+		productRepo:  productRepo,
+		paymentRepo:  paymentRepo,
+		locationRepo: locationRepo,
 	}
 }
 
@@ -319,7 +322,7 @@ MarkOrderReturnedToSeller:
 - Update return_requests
 - Commit transaction
 */
-func (u *returnUseCase) MarkOrderReturnedToSeller(ctx context.Context, returnID int64) (*domain.ReturnRequest, error) {
+func (u *returnUseCase) MarkOrderReturnedToSeller(ctx context.Context, adminID, returnID int64) (*domain.ReturnRequest, error) {
 	// Start a transaction
 	tx, err := u.returnRepo.BeginTx(ctx)
 	if err != nil {
@@ -344,7 +347,14 @@ func (u *returnUseCase) MarkOrderReturnedToSeller(ctx context.Context, returnID
 	}
 
 	// Update stock for returned products
-	err = u.updateStockForReturnedOrder(ctx, tx, returnRequest.OrderID)
+	change := domain.StockChange{
+		Reason:        utils.StockMovementReasonReturn,
+		ReferenceType: utils.StockReferenceReturn,
+		ReferenceID:   &returnRequest.ID,
+		ActorType:     utils.StockActorAdmin,
+		ActorID:       &adminID,
+	}
+	err = u.updateStockForReturnedOrder(ctx, tx, returnRequest.OrderID, change)
 	if err != nil {
 		log.Printf("failed to update stock quantity for the returned order items : %v", err)
 		return nil, err
@@ -374,9 +384,16 @@ func (u *returnUseCase) MarkOrderReturnedToSeller(ctx context.Context, returnID
 
 /*
 updateStockForReturnedOrder:
-- Update stock for the products which belongs to the order items in the returned order
+- Restock the locations the stock of the returned order was allocated from
+- Orders without allocations restock the products of their order items at the primary location
+- The stock movements are recorded against the return request
 */
-func (u *returnUseCase) updateStockForReturnedOrder(ctx context.Context, tx *sql.Tx, orderID int64) error {
+func (u *returnUseCase) updateStockForReturnedOrder(ctx context.Context, tx *sql.Tx, orderID int64, change domain.StockChange) error {
+	restocked, err := restockAllocationsTx(ctx, tx, u.locationRepo, u.productRepo, orderID, change)
+	if err != nil || restocked {
+		return err
+	}
+
 	// Get order items
 	orderItems, err := u.orderRepo.GetOrderItems(ctx, orderID)
 	if err != nil {
@@ -391,7 +408,7 @@ func (u *returnUseCase) updateStockForReturnedOrder(ctx context.Context, tx *sql
 
 	// iterate through each order item
 	for _, item := range orderItems {
-		err = u.productRepo.UpdateStockTx(ctx, tx, item.ProductID, item.Quantity)
+		err = u.productRepo.UpdateStockTx(ctx, tx, item.ProductID, item.VariantID, item.Quantity, change)
 		if err != nil {
 			log.Printf("failed to update stock for product %d: %v", item.ProductID, err)
 			return err
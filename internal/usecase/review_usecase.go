@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/validator"
+)
+
+type ReviewUseCase interface {
+	AddReview(ctx context.Context, userID, productID int64, rating int, comment string) (*domain.ProductReview, error)
+	GetProductReviews(ctx context.Context, productID int64, page, limit int) ([]*domain.ProductReview, int64, error)
+	GetReviews(ctx context.Context, params domain.ReviewQueryParams) ([]*domain.ProductReview, int64, error)
+	UpdateReviewStatus(ctx context.Context, reviewID int64, status string) (*domain.ProductReview, error)
+}
+
+type reviewUseCase struct {
+	reviewRepo  repository.ReviewRepository
+	productRepo repository.ProductRepository
+	orderRepo   repository.OrderRepository
+}
+
+func NewReviewUseCase(reviewRepo repository.ReviewRepository, productRepo repository.ProductRepository, orderRepo repository.OrderRepository) ReviewUseCase {
+	return &reviewUseCase{
+		reviewRepo:  reviewRepo,
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+	}
+}
+
+/*
+AddReview:
+- Validate rating and comment
+- Make sure the product exists
+- User can review a product only if an order containing the product is delivered
+- User can review a product only once
+- Review is created with pending status, it's counted in the rating once approved by admin
+*/
+func (u *reviewUseCase) AddReview(ctx context.Context, userID, productID int64, rating int, comment string) (*domain.ProductReview, error) {
+	comment = strings.TrimSpace(comment)
+	if err := validator.ValidateReview(rating, comment); err != nil {
+		return nil, err
+	}
+
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivered, err := u.orderRepo.HasDeliveredOrderForProduct(ctx, userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !delivered {
+		return nil, utils.ErrReviewNotAllowed
+	}
+
+	exists, err := u.reviewRepo.ExistsForUserProduct(ctx, userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, utils.ErrDuplicateReview
+	}
+
+	now := time.Now().UTC()
+	review := &domain.ProductReview{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    rating,
+		Comment:   comment,
+		Status:    utils.ReviewStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err = u.reviewRepo.Create(ctx, review)
+	if err != nil {
+		log.Printf("error while adding product review : %v", err)
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// GetProductReviews returns only the approved reviews of the product
+func (u *reviewUseCase) GetProductReviews(ctx context.Context, productID int64, page, limit int) ([]*domain.ProductReview, int64, error) {
+	_, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params := domain.ReviewQueryParams{
+		ProductID: productID,
+		Status:    utils.ReviewStatusApproved,
+		Page:      page,
+		Limit:     limit,
+	}
+	setReviewPagination(&params)
+
+	return u.reviewRepo.GetReviews(ctx, params)
+}
+
+func (u *reviewUseCase) GetReviews(ctx context.Context, params domain.ReviewQueryParams) ([]*domain.ProductReview, int64, error) {
+	if params.Status != "" && !isValidReviewStatus(params.Status) {
+		return nil, 0, utils.ErrInvalidReviewStatus
+	}
+	setReviewPagination(&params)
+
+	return u.reviewRepo.GetReviews(ctx, params)
+}
+
+/*
+UpdateReviewStatus:
+- Admin approves or hides a review
+- Product rating is recalculated along with the status update
+*/
+func (u *reviewUseCase) UpdateReviewStatus(ctx context.Context, reviewID int64, status string) (*domain.ProductReview, error) {
+	if status != utils.ReviewStatusApproved && status != utils.ReviewStatusHidden {
+		return nil, utils.ErrInvalidReviewStatus
+	}
+
+	review, err := u.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status == status {
+		return nil, utils.ErrReviewAlreadyInStatus
+	}
+
+	err = u.reviewRepo.UpdateStatus(ctx, reviewID, status)
+	if err != nil {
+		log.Printf("error while updating review status : %v", err)
+		return nil, err
+	}
+
+	review.Status = status
+	review.UpdatedAt = time.Now().UTC()
+	return review, nil
+}
+
+func setReviewPagination(params *domain.ReviewQueryParams) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 10
+	} else if params.Limit > 100 {
+		params.Limit = 100
+	}
+}
+
+func isValidReviewStatus(status string) bool {
+	switch status {
+	case utils.ReviewStatusPending, utils.ReviewStatusApproved, utils.ReviewStatusHidden:
+		return true
+	}
+	return false
+}
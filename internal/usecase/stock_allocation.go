@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+/*
+rankLocations:
+- Order the locations holding the stock by the allocation rule
+- priority rule: the locations with a lower priority come first
+- nearest rule: the locations sharing a longer prefix with the shipping pincode come first
+- Ties are broken by the smaller difference between the pincodes, then by the priority
+*/
+func rankLocations(stocks []*domain.LocationStock, rule, pincode string) {
+	sort.SliceStable(stocks, func(i, j int) bool {
+		if rule == utils.AllocationRuleNearest && pincode != "" {
+			pi, di := pincodeDistance(stocks[i].Pincode, pincode)
+			pj, dj := pincodeDistance(stocks[j].Pincode, pincode)
+			if pi != pj {
+				return pi > pj
+			}
+			if di != dj {
+				return di < dj
+			}
+		}
+		if stocks[i].Priority != stocks[j].Priority {
+			return stocks[i].Priority < stocks[j].Priority
+		}
+		return stocks[i].LocationID < stocks[j].LocationID
+	})
+}
+
+// pincodeDistance gives the length of the common prefix of the pincodes and the difference between them
+func pincodeDistance(a, b string) (int, int64) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	na, errA := strconv.ParseInt(a, 10, 64)
+	nb, errB := strconv.ParseInt(b, 10, 64)
+	if errA != nil || errB != nil {
+		return prefix, 0
+	}
+	if na > nb {
+		return prefix, na - nb
+	}
+	return prefix, nb - na
+}
+
+/*
+allocateStock:
+- Take the quantity from the ranked locations
+- The first location which can cover the whole quantity is used alone, so that the item isn't split between shipments
+- Otherwise the quantity is split between the locations in the ranked order
+- Returns the quantity the locations couldn't cover
+*/
+func allocateStock(stocks []*domain.LocationStock, quantity int) ([]*domain.OrderAllocation, int) {
+	for _, stock := range stocks {
+		if stock.StockQuantity >= quantity {
+			return []*domain.OrderAllocation{{LocationID: stock.LocationID, Quantity: quantity}}, 0
+		}
+	}
+
+	var allocations []*domain.OrderAllocation
+	remaining := quantity
+	for _, stock := range stocks {
+		if remaining == 0 {
+			break
+		}
+		taken := min(stock.StockQuantity, remaining)
+		allocations = append(allocations, &domain.OrderAllocation{LocationID: stock.LocationID, Quantity: taken})
+		remaining -= taken
+	}
+	return allocations, remaining
+}
+
+/*
+restockAllocationsTx:
+- Release the allocations of the order and restock the locations the stock was taken from
+- Returns false when the order has no allocations to release
+- Orders placed before the stock was allocated from locations restock their items at the primary location instead
+*/
+func restockAllocationsTx(ctx context.Context, tx *sql.Tx, locationRepo repository.LocationRepository, productRepo repository.ProductRepository,
+	orderID int64, change domain.StockChange) (bool, error) {
+	allocations, err := locationRepo.ReleaseAllocationsTx(ctx, tx, orderID)
+	if err != nil {
+		return false, err
+	}
+	if len(allocations) == 0 {
+		return false, nil
+	}
+
+	for _, allocation := range allocations {
+		locationChange := change
+		locationChange.LocationID = &allocation.LocationID
+		err = productRepo.UpdateStockTx(ctx, tx, allocation.ProductID, allocation.VariantID, allocation.Quantity, locationChange)
+		if err != nil {
+			log.Printf("failed to restock product %d at location %d: %v", allocation.ProductID, allocation.LocationID, err)
+			return false, err
+		}
+	}
+
+	return true, nil
+}
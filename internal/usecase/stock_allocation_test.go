@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestPincodeDistance(t *testing.T) {
+	tests := []struct {
+		name           string
+		a, b           string
+		wantPrefix     int
+		wantDifference int64
+	}{
+		{name: "same pincode", a: "682001", b: "682001", wantPrefix: 6, wantDifference: 0},
+		{name: "same district", a: "682001", b: "682030", wantPrefix: 4, wantDifference: 29},
+		{name: "difference is absolute", a: "682030", b: "682001", wantPrefix: 4, wantDifference: 29},
+		{name: "nothing in common", a: "110001", b: "682001", wantPrefix: 0, wantDifference: 572000},
+		{name: "not a number", a: "68200A", b: "682001", wantPrefix: 5, wantDifference: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, difference := pincodeDistance(tt.a, tt.b)
+			if prefix != tt.wantPrefix || difference != tt.wantDifference {
+				t.Errorf("pincodeDistance(%q, %q) = %d, %d, want %d, %d",
+					tt.a, tt.b, prefix, difference, tt.wantPrefix, tt.wantDifference)
+			}
+		})
+	}
+}
+
+func TestRankLocations(t *testing.T) {
+	locations := func() []*domain.LocationStock {
+		return []*domain.LocationStock{
+			{LocationID: 1, Pincode: "110001", Priority: 0},
+			{LocationID: 2, Pincode: "682030", Priority: 5},
+			{LocationID: 3, Pincode: "682001", Priority: 10},
+			{LocationID: 4, Pincode: "560001", Priority: 5},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		rule    string
+		pincode string
+		want    []int64
+	}{
+		{name: "priority rule", rule: utils.AllocationRulePriority, pincode: "682001", want: []int64{1, 2, 4, 3}},
+		{name: "nearest rule", rule: utils.AllocationRuleNearest, pincode: "682001", want: []int64{3, 2, 4, 1}},
+		{name: "nearest rule breaks ties on the pincode difference", rule: utils.AllocationRuleNearest, pincode: "682020", want: []int64{2, 3, 4, 1}},
+		{name: "nearest rule without a pincode", rule: utils.AllocationRuleNearest, pincode: "", want: []int64{1, 2, 4, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stocks := locations()
+			rankLocations(stocks, tt.rule, tt.pincode)
+
+			got := make([]int64, len(stocks))
+			for i, stock := range stocks {
+				got[i] = stock.LocationID
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rankLocations() order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocateStock(t *testing.T) {
+	stocks := []*domain.LocationStock{
+		{LocationID: 1, StockQuantity: 3},
+		{LocationID: 2, StockQuantity: 10},
+		{LocationID: 3, StockQuantity: 4},
+	}
+
+	tests := []struct {
+		name          string
+		quantity      int
+		want          []domain.OrderAllocation
+		wantRemaining int
+	}{
+		{name: "first location covers it", quantity: 2, want: []domain.OrderAllocation{{LocationID: 1, Quantity: 2}}},
+		{name: "a later location covers it alone", quantity: 8, want: []domain.OrderAllocation{{LocationID: 2, Quantity: 8}}},
+		{
+			name:     "split in the ranked order",
+			quantity: 15,
+			want:     []domain.OrderAllocation{{LocationID: 1, Quantity: 3}, {LocationID: 2, Quantity: 10}, {LocationID: 3, Quantity: 2}},
+		},
+		{
+			name:          "not enough stock",
+			quantity:      20,
+			want:          []domain.OrderAllocation{{LocationID: 1, Quantity: 3}, {LocationID: 2, Quantity: 10}, {LocationID: 3, Quantity: 4}},
+			wantRemaining: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocations, remaining := allocateStock(stocks, tt.quantity)
+			if remaining != tt.wantRemaining {
+				t.Errorf("allocateStock() remaining = %d, want %d", remaining, tt.wantRemaining)
+			}
+
+			got := make([]domain.OrderAllocation, len(allocations))
+			for i, allocation := range allocations {
+				got[i] = domain.OrderAllocation{LocationID: allocation.LocationID, Quantity: allocation.Quantity}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocateStock() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no locations", func(t *testing.T) {
+		allocations, remaining := allocateStock(nil, 5)
+		if len(allocations) != 0 || remaining != 5 {
+			t.Errorf("allocateStock() = %v, %d, want no allocations and 5 remaining", allocations, remaining)
+		}
+	})
+}
@@ -18,7 +18,7 @@ type SubCategoryUseCase interface {
 	GetSubCategoriesByCategory(ctx context.Context, categoryID int) ([]*domain.SubCategory, error)
 	GetSubCategoryByID(ctx context.Context, categoryID, subCategoryID int) (*domain.SubCategory, error)
 	UpdateSubCategory(ctx context.Context, categoryID int, subCategory *domain.SubCategory) error
-	SoftDeleteSubCategory(ctx context.Context, categoryID, subCategoryID int) error
+	SoftDeleteSubCategory(ctx context.Context, categoryID, subCategoryID int) (*domain.CategoryCascadeResult, error)
 }
 
 type subCategoryUseCase struct {
@@ -122,7 +122,7 @@ func (u *subCategoryUseCase) GetSubCategoryByID(ctx context.Context, categoryID,
 
 func (u *subCategoryUseCase) UpdateSubCategory(ctx context.Context, categoryID int, subCategory *domain.SubCategory) error {
 	// Check if the parent category exists
-	_, err := u.categoryRepo.GetByID(ctx, categoryID)
+	parentCategory, err := u.categoryRepo.GetByID(ctx, categoryID)
 	if err != nil {
 		if err == utils.ErrCategoryNotFound {
 			return utils.ErrCategoryNotFound
@@ -140,8 +140,8 @@ func (u *subCategoryUseCase) UpdateSubCategory(ctx context.Context, categoryID i
 		return err
 	}
 
-	// Generate slug
-	subCategory.Slug = utils.GenerateSlug(subCategory.Name)
+	// Generate slug, prefixed with the slug of the parent category like a new sub category
+	subCategory.Slug = utils.GenerateSubCategorySlug(parentCategory.Slug, subCategory.Name)
 
 	// Set parent category ID
 	subCategory.ParentCategoryID = categoryID
@@ -159,41 +159,41 @@ func (u *subCategoryUseCase) UpdateSubCategory(ctx context.Context, categoryID i
 	return nil
 }
 
-func (u *subCategoryUseCase) SoftDeleteSubCategory(ctx context.Context, categoryID, subCategoryID int) error {
+func (u *subCategoryUseCase) SoftDeleteSubCategory(ctx context.Context, categoryID, subCategoryID int) (*domain.CategoryCascadeResult, error) {
 	// Check if the parent category exists
 	_, err := u.categoryRepo.GetByID(ctx, categoryID)
 	if err != nil {
 		if err == utils.ErrCategoryNotFound {
-			return utils.ErrCategoryNotFound
+			return nil, utils.ErrCategoryNotFound
 		}
 		log.Printf("error while retrieving category details using ID : %v", err)
-		return err
+		return nil, err
 	}
 
 	// Check if the sub-category exists and belongs to the specified category
 	subCategory, err := u.subCategoryRepo.GetByID(ctx, subCategoryID)
 	if err != nil {
 		if err == utils.ErrSubCategoryNotFound {
-			return utils.ErrSubCategoryNotFound
+			return nil, utils.ErrSubCategoryNotFound
 		}
 		log.Printf("error while retrieving sub category details using ID : %v", err)
-		return err
+		return nil, err
 	}
 
 	if subCategory.ParentCategoryID != categoryID {
-		return utils.ErrSubCategoryNotFound
+		return nil, utils.ErrSubCategoryNotFound
 	}
 
 	if subCategory.IsDeleted {
-		return utils.ErrSubCategoryAlreadyDeleted
+		return nil, utils.ErrSubCategoryAlreadyDeleted
 	}
 
 	// Perform the soft delete
-	err = u.subCategoryRepo.SoftDelete(ctx, subCategoryID)
+	result, err := u.subCategoryRepo.SoftDelete(ctx, subCategoryID)
 	if err != nil {
 		log.Printf("error while soft deleting the given sub category details : %v", err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	return result, nil
 }
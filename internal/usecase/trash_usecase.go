@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/storage"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+type TrashUseCase interface {
+	GetDeletedProducts(ctx context.Context) ([]*domain.Product, error)
+	GetDeletedCategories(ctx context.Context) ([]*domain.Category, error)
+	RestoreProduct(ctx context.Context, id int64) error
+	RestoreCategory(ctx context.Context, id int) (*domain.CategoryCascadeResult, error)
+	Purge(ctx context.Context, olderThanDays int) (*domain.TrashPurgeResult, error)
+}
+
+type trashUseCase struct {
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	imageStorage storage.ImageStorage
+}
+
+func NewTrashUseCase(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, imageStorage storage.ImageStorage) TrashUseCase {
+	return &trashUseCase{
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		imageStorage: imageStorage,
+	}
+}
+
+func (u *trashUseCase) GetDeletedProducts(ctx context.Context) ([]*domain.Product, error) {
+	return u.productRepo.GetDeleted(ctx)
+}
+
+// GetDeletedCategories returns the deleted categories of every level, sub categories have their parent id
+func (u *trashUseCase) GetDeletedCategories(ctx context.Context) ([]*domain.Category, error) {
+	return u.categoryRepo.GetDeleted(ctx)
+}
+
+/*
+RestoreProduct:
+- The sub category of the product and all of its ancestors must be active
+- Name and slug of the product must not be used by an active product in the meantime
+*/
+func (u *trashUseCase) RestoreProduct(ctx context.Context, id int64) error {
+	product, err := u.productRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	active, err := u.categoryRepo.IsActiveInTree(ctx, product.SubCategoryID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return utils.ErrParentDeleted
+	}
+
+	exists, err := u.productRepo.NameExists(ctx, product.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.ErrDuplicateProductName
+	}
+
+	exists, err = u.productRepo.SlugExists(ctx, product.Slug)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return utils.ErrSlugTaken
+	}
+
+	return u.productRepo.Restore(ctx, id)
+}
+
+// RestoreCategory restores a category along with the categories and products deleted by its cascade,
+// its parent and all the ancestors must be active.
+// Slugs of the categories are unique across the deleted ones as well, so the slug is still free
+func (u *trashUseCase) RestoreCategory(ctx context.Context, id int) (*domain.CategoryCascadeResult, error) {
+	category, err := u.categoryRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if category.ParentID != nil {
+		active, err := u.categoryRepo.IsActiveInTree(ctx, *category.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if !active {
+			return nil, utils.ErrParentDeleted
+		}
+	}
+
+	return u.categoryRepo.Restore(ctx, id)
+}
+
+/*
+Purge:
+- Permanently delete the products and the categories that are in the trash for more than the given days
+- Products go first, so that the categories emptied by them can be purged in the same run
+- Images of the purged products are removed from the image storage, a failed removal is only logged
+*/
+func (u *trashUseCase) Purge(ctx context.Context, olderThanDays int) (*domain.TrashPurgeResult, error) {
+	if olderThanDays <= 0 {
+		return nil, utils.ErrInvalidRetentionDay
+	}
+	before := time.Now().UTC().AddDate(0, 0, -olderThanDays)
+
+	purgedProducts, imageURLs, err := u.productRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	removedImages := 0
+	for _, imageURL := range imageURLs {
+		err := u.imageStorage.DeleteImage(ctx, imageURL)
+		if err != nil {
+			log.Printf("failed to delete image %s of a purged product : %v", imageURL, err)
+			continue
+		}
+		removedImages++
+	}
+
+	purgedCategories, err := u.categoryRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TrashPurgeResult{
+		PurgedProducts:   purgedProducts,
+		PurgedCategories: purgedCategories,
+		RemovedImages:    removedImages,
+	}, nil
+}
@@ -5,12 +5,14 @@ import (
 
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/repository"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/pagination"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
 )
 
 type WalletUseCase interface {
 	GetBalance(ctx context.Context, userID int64) (float64, error)
 	GetWalletTransactions(ctx context.Context, userID int64, page, limit int, sort, order, transactionType string) ([]*domain.WalletTransaction, int64, error)
+	GetWalletTransactionsByCursor(ctx context.Context, userID int64, transactionType string, params pagination.Params) ([]*domain.WalletTransaction, pagination.Page, error)
 }
 
 type walletUseCase struct {
@@ -66,3 +68,8 @@ func (u *walletUseCase) GetWalletTransactions(ctx context.Context, userID int64,
 
 	return transactions, totalCount, nil
 }
+
+// GetWalletTransactionsByCursor returns a keyset page of the wallet transactions of the user, newest first
+func (u *walletUseCase) GetWalletTransactionsByCursor(ctx context.Context, userID int64, transactionType string, params pagination.Params) ([]*domain.WalletTransaction, pagination.Page, error) {
+	return u.walletRepo.GetTransactionsByCursor(ctx, userID, transactionType, params)
+}
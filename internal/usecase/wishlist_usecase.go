@@ -37,6 +37,10 @@ func (u *wishlistUseCase) AddToWishlist(ctx context.Context, userID, productID i
 		}
 		return nil, err
 	}
+	// products that are not live yet or archived can't be wishlisted
+	if product.Status != utils.ProductStatusActive {
+		return nil, utils.ErrProductNotFound
+	}
 
 	// Check if the item is already in the wishlist
 	exists, err := u.wishlistRepo.ItemExists(ctx, userID, productID)
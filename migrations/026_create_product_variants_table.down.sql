@@ -0,0 +1,5 @@
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS fk_product_variants_product;
+DROP INDEX IF EXISTS idx_product_variants_product_id;
+DROP INDEX IF EXISTS idx_product_variants_sku;
+DROP INDEX IF EXISTS idx_product_variants_product_size_color;
+DROP TABLE IF EXISTS product_variants;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    sku VARCHAR(100) NOT NULL,
+    size VARCHAR(20) NOT NULL DEFAULT '',
+    color VARCHAR(50) NOT NULL DEFAULT '',
+    price DECIMAL(10, 2) NOT NULL,
+    stock_quantity INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT fk_product_variants_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_variants_product_id ON product_variants(product_id);
+-- sku and (size, color) combination only need to be unique among the active variants
+CREATE UNIQUE INDEX idx_product_variants_sku ON product_variants(sku) WHERE is_deleted = false;
+CREATE UNIQUE INDEX idx_product_variants_product_size_color ON product_variants(product_id, size, color) WHERE is_deleted = false;
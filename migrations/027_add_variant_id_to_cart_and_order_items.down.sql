@@ -0,0 +1,8 @@
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS fk_order_items_variant;
+ALTER TABLE order_items DROP COLUMN IF EXISTS variant_id;
+
+DROP INDEX IF EXISTS idx_cart_items_user_product_variant;
+DELETE FROM cart_items WHERE variant_id IS NOT NULL;
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS fk_cart_items_variant;
+ALTER TABLE cart_items DROP COLUMN IF EXISTS variant_id;
+ALTER TABLE cart_items ADD CONSTRAINT unique_user_product UNIQUE (user_id, product_id);
@@ -0,0 +1,17 @@
+-- cart items : a product can be in the cart once for each of its variants
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS variant_id BIGINT;
+ALTER TABLE cart_items
+ADD CONSTRAINT fk_cart_items_variant
+FOREIGN KEY (variant_id)
+REFERENCES product_variants(id)
+ON DELETE CASCADE;
+
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS unique_user_product;
+CREATE UNIQUE INDEX idx_cart_items_user_product_variant ON cart_items(user_id, product_id, COALESCE(variant_id, 0));
+
+-- order items : keep track of the variant that was ordered
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_id BIGINT;
+ALTER TABLE order_items
+ADD CONSTRAINT fk_order_items_variant
+FOREIGN KEY (variant_id)
+REFERENCES product_variants(id);
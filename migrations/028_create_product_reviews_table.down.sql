@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_products_average_rating;
+
+ALTER TABLE products DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE products DROP COLUMN IF EXISTS average_rating;
+
+ALTER TABLE product_reviews DROP CONSTRAINT IF EXISTS fk_product_reviews_product;
+ALTER TABLE product_reviews DROP CONSTRAINT IF EXISTS fk_product_reviews_user;
+ALTER TABLE product_reviews DROP CONSTRAINT IF EXISTS uq_product_reviews_product_user;
+
+DROP INDEX IF EXISTS idx_product_reviews_product_status;
+DROP INDEX IF EXISTS idx_product_reviews_status;
+
+DROP TABLE IF EXISTS product_reviews;
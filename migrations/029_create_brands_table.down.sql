@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_products_brand_id;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_products_brand;
+ALTER TABLE products DROP COLUMN IF EXISTS brand_id;
+
+DROP INDEX IF EXISTS idx_brands_slug;
+DROP TABLE IF EXISTS brands;
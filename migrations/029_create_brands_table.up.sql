@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS brands (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    slug VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    is_deleted BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX idx_brands_slug ON brands(slug);
+
+-- brand is optional for a product
+ALTER TABLE products ADD COLUMN IF NOT EXISTS brand_id INTEGER;
+ALTER TABLE products ADD CONSTRAINT fk_products_brand
+    FOREIGN KEY (brand_id)
+    REFERENCES brands(id)
+    ON DELETE SET NULL;
+
+CREATE INDEX idx_products_brand_id ON products(brand_id);
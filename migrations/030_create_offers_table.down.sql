@@ -0,0 +1,10 @@
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS fk_offers_product;
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS fk_offers_sub_category;
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS fk_offers_category;
+
+DROP INDEX IF EXISTS idx_offers_product_id;
+DROP INDEX IF EXISTS idx_offers_sub_category_id;
+DROP INDEX IF EXISTS idx_offers_category_id;
+DROP INDEX IF EXISTS idx_offers_active_period;
+
+DROP TABLE IF EXISTS offers;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS offers (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    discount_type VARCHAR(20) NOT NULL CHECK (discount_type IN ('percentage', 'flat')),
+    discount_value DECIMAL(10, 2) NOT NULL CHECK (discount_value > 0),
+    product_id BIGINT,
+    sub_category_id INTEGER,
+    category_id INTEGER,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_offers_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_offers_sub_category FOREIGN KEY (sub_category_id) REFERENCES sub_categories(id) ON DELETE CASCADE,
+    CONSTRAINT fk_offers_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+    -- an offer targets exactly one of product, sub category or category
+    CONSTRAINT chk_offers_single_target CHECK (num_nonnulls(product_id, sub_category_id, category_id) = 1),
+    CONSTRAINT chk_offers_period CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_offers_product_id ON offers(product_id) WHERE product_id IS NOT NULL;
+CREATE INDEX idx_offers_sub_category_id ON offers(sub_category_id) WHERE sub_category_id IS NOT NULL;
+CREATE INDEX idx_offers_category_id ON offers(category_id) WHERE category_id IS NOT NULL;
+CREATE INDEX idx_offers_active_period ON offers(starts_at, ends_at) WHERE is_active = true;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_products_search_text_trgm;
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+DROP TRIGGER IF EXISTS trg_categories_search_refresh ON categories;
+DROP FUNCTION IF EXISTS categories_search_refresh();
+DROP TRIGGER IF EXISTS trg_sub_categories_search_refresh ON sub_categories;
+DROP FUNCTION IF EXISTS sub_categories_search_refresh();
+DROP TRIGGER IF EXISTS trg_products_search_update ON products;
+DROP FUNCTION IF EXISTS products_search_update();
+
+ALTER TABLE products DROP COLUMN IF EXISTS search_text;
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
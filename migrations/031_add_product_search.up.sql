@@ -0,0 +1,60 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- search_vector is used for ranked full text search,
+-- search_text is used for typo tolerant (trigram) matching
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_text TEXT NOT NULL DEFAULT '';
+
+-- name has the highest weight, followed by category names and then description
+CREATE OR REPLACE FUNCTION products_search_update() RETURNS TRIGGER AS $$
+DECLARE
+    sub_category_name TEXT;
+    category_name TEXT;
+BEGIN
+    SELECT sc.name, c.name INTO sub_category_name, category_name
+    FROM sub_categories sc
+    JOIN categories c ON sc.parent_category_id = c.id
+    WHERE sc.id = NEW.sub_category_id;
+
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(sub_category_name, '') || ' ' || COALESCE(category_name, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'C');
+    NEW.search_text := LOWER(CONCAT_WS(' ', NEW.name, sub_category_name, category_name));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_search_update
+    BEFORE INSERT OR UPDATE OF name, description, sub_category_id ON products
+    FOR EACH ROW EXECUTE FUNCTION products_search_update();
+
+-- renaming a category or sub category refreshes the search data of its products
+CREATE OR REPLACE FUNCTION sub_categories_search_refresh() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products SET name = name WHERE sub_category_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_sub_categories_search_refresh
+    AFTER UPDATE OF name, parent_category_id ON sub_categories
+    FOR EACH ROW EXECUTE FUNCTION sub_categories_search_refresh();
+
+CREATE OR REPLACE FUNCTION categories_search_refresh() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products SET name = name
+    WHERE sub_category_id IN (SELECT id FROM sub_categories WHERE parent_category_id = NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_categories_search_refresh
+    AFTER UPDATE OF name ON categories
+    FOR EACH ROW EXECUTE FUNCTION categories_search_refresh();
+
+-- fill the search data of existing products
+UPDATE products SET name = name;
+
+CREATE INDEX idx_products_search_vector ON products USING GIN(search_vector);
+CREATE INDEX idx_products_search_text_trgm ON products USING GIN(search_text gin_trgm_ops);
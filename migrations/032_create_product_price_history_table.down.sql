@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_price_history_product_changed_at;
+DROP TABLE IF EXISTS product_price_history;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS product_price_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    old_price DECIMAL(10, 2) NOT NULL,
+    new_price DECIMAL(10, 2) NOT NULL,
+    changed_by INTEGER,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_price_history_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_price_history_variant
+        FOREIGN KEY (variant_id)
+        REFERENCES product_variants(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_price_history_admin
+        FOREIGN KEY (changed_by)
+        REFERENCES admins(id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX idx_price_history_product_changed_at ON product_price_history(product_id, changed_at);
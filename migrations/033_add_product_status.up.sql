@@ -0,0 +1,10 @@
+-- existing products are already live
+ALTER TABLE products ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE products ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE products ADD CONSTRAINT chk_products_status
+    CHECK (status IN ('draft', 'scheduled', 'active', 'archived'));
+ALTER TABLE products ADD CONSTRAINT chk_products_scheduled_publish_at
+    CHECK (status <> 'scheduled' OR publish_at IS NOT NULL);
+
+CREATE INDEX idx_products_status ON products(status);
+CREATE INDEX idx_products_scheduled_publish_at ON products(publish_at) WHERE status = 'scheduled';
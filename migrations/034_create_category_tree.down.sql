@@ -0,0 +1,102 @@
+-- the old schema only has two levels and unique category names, so stop before anything is dropped
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM categories WHERE depth > 1) THEN
+        RAISE EXCEPTION 'cannot revert category tree: categories deeper than two levels exist';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM categories WHERE parent_id IS NULL GROUP BY name HAVING COUNT(*) > 1) THEN
+        RAISE EXCEPTION 'cannot revert category tree: top level categories with duplicate names exist';
+    END IF;
+END $$;
+
+DROP VIEW IF EXISTS sub_categories;
+
+CREATE TABLE IF NOT EXISTS sub_categories (
+    id SERIAL PRIMARY KEY,
+    parent_category_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT fk_parent_category
+        FOREIGN KEY (parent_category_id)
+        REFERENCES categories(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sub_categories_slug ON sub_categories(slug);
+CREATE INDEX IF NOT EXISTS idx_sub_categories_parent ON sub_categories(parent_category_id);
+
+-- sub categories keep their category ids, so products and offers don't have to change
+INSERT INTO sub_categories (id, parent_category_id, name, slug, created_at, updated_at, deleted_at, is_deleted)
+SELECT id, CAST(split_part(path, '/', 2) AS INTEGER), name, slug, created_at, updated_at, deleted_at, is_deleted
+FROM categories
+WHERE parent_id IS NOT NULL;
+
+SELECT setval(pg_get_serial_sequence('sub_categories', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM sub_categories;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_sub_category;
+ALTER TABLE products ADD CONSTRAINT fk_sub_category
+    FOREIGN KEY (sub_category_id)
+    REFERENCES sub_categories(id)
+    ON DELETE CASCADE;
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS fk_offers_sub_category;
+ALTER TABLE offers ADD CONSTRAINT fk_offers_sub_category
+    FOREIGN KEY (sub_category_id)
+    REFERENCES sub_categories(id)
+    ON DELETE CASCADE;
+
+-- offers on categories below the top level are removed along with them
+DELETE FROM categories WHERE parent_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION products_search_update() RETURNS TRIGGER AS $$
+DECLARE
+    sub_category_name TEXT;
+    category_name TEXT;
+BEGIN
+    SELECT sc.name, c.name INTO sub_category_name, category_name
+    FROM sub_categories sc
+    JOIN categories c ON sc.parent_category_id = c.id
+    WHERE sc.id = NEW.sub_category_id;
+
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(sub_category_name, '') || ' ' || COALESCE(category_name, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'C');
+    NEW.search_text := LOWER(CONCAT_WS(' ', NEW.name, sub_category_name, category_name));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION sub_categories_search_refresh() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products SET name = name WHERE sub_category_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_sub_categories_search_refresh
+    AFTER UPDATE OF name, parent_category_id ON sub_categories
+    FOR EACH ROW EXECUTE FUNCTION sub_categories_search_refresh();
+
+CREATE OR REPLACE FUNCTION categories_search_refresh() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products SET name = name
+    WHERE sub_category_id IN (SELECT id FROM sub_categories WHERE parent_category_id = NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+UPDATE products SET name = name;
+
+DROP INDEX IF EXISTS idx_categories_path;
+DROP INDEX IF EXISTS idx_categories_parent_id;
+DROP INDEX IF EXISTS idx_categories_parent_name;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS fk_categories_parent;
+ALTER TABLE categories DROP COLUMN IF EXISTS depth;
+ALTER TABLE categories DROP COLUMN IF EXISTS path;
+ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
+ALTER TABLE categories ADD CONSTRAINT categories_name_key UNIQUE (name);
@@ -0,0 +1,100 @@
+-- categories become a self referencing tree of any depth.
+-- path is the materialised path of ids from the top level category, like '/1/4/9/',
+-- so the descendants of a category are the categories whose path starts with its path
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id INTEGER;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS path TEXT NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS depth INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE categories ADD CONSTRAINT fk_categories_parent
+    FOREIGN KEY (parent_id)
+    REFERENCES categories(id)
+    ON DELETE CASCADE;
+
+-- category names only have to be unique among siblings now
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_name_key;
+CREATE UNIQUE INDEX idx_categories_parent_name ON categories(COALESCE(parent_id, 0), name);
+
+UPDATE categories SET path = '/' || id || '/';
+
+-- sub categories move into the tree as the children of their category
+ALTER TABLE categories ADD COLUMN sub_category_id INTEGER;
+
+-- slugs stay unique across the whole tree, so a sub category slug already used by a category
+-- gets the slug of its parent category as prefix (and its id when that is taken too)
+INSERT INTO categories (parent_id, name, slug, path, depth, created_at, updated_at, deleted_at, is_deleted, sub_category_id)
+SELECT sc.parent_category_id, sc.name,
+    CASE
+        WHEN NOT EXISTS (SELECT 1 FROM categories c WHERE c.slug = sc.slug) THEN sc.slug
+        WHEN NOT EXISTS (SELECT 1 FROM categories c WHERE c.slug = p.slug || '/' || sc.slug)
+            AND NOT EXISTS (SELECT 1 FROM sub_categories s WHERE s.slug = p.slug || '/' || sc.slug) THEN p.slug || '/' || sc.slug
+        ELSE p.slug || '/' || sc.slug || '-' || sc.id
+    END,
+    '', 1, sc.created_at, sc.updated_at, sc.deleted_at, sc.is_deleted, sc.id
+FROM sub_categories sc
+JOIN categories p ON p.id = sc.parent_category_id;
+
+UPDATE categories SET path = '/' || parent_id || '/' || id || '/' WHERE sub_category_id IS NOT NULL;
+
+-- search data of a product has the names of its category and all the ancestors of it
+CREATE OR REPLACE FUNCTION products_search_update() RETURNS TRIGGER AS $$
+DECLARE
+    category_names TEXT;
+BEGIN
+    SELECT string_agg(a.name, ' ' ORDER BY a.depth DESC) INTO category_names
+    FROM categories node
+    JOIN categories a ON node.path LIKE a.path || '%'
+    WHERE node.id = NEW.sub_category_id;
+
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(category_names, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'C');
+    NEW.search_text := LOWER(CONCAT_WS(' ', NEW.name, category_names));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION categories_search_refresh() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products SET name = name
+    WHERE sub_category_id IN (SELECT id FROM categories WHERE path LIKE NEW.path || '%');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- products and offers point to the new category ids
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_sub_category;
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS fk_offers_sub_category;
+
+UPDATE products p SET sub_category_id = c.id
+FROM categories c
+WHERE c.sub_category_id = p.sub_category_id;
+
+UPDATE offers o SET sub_category_id = c.id
+FROM categories c
+WHERE c.sub_category_id = o.sub_category_id;
+
+ALTER TABLE products ADD CONSTRAINT fk_sub_category
+    FOREIGN KEY (sub_category_id)
+    REFERENCES categories(id)
+    ON DELETE CASCADE;
+ALTER TABLE offers ADD CONSTRAINT fk_offers_sub_category
+    FOREIGN KEY (sub_category_id)
+    REFERENCES categories(id)
+    ON DELETE CASCADE;
+
+ALTER TABLE categories DROP COLUMN sub_category_id;
+
+DROP TRIGGER IF EXISTS trg_sub_categories_search_refresh ON sub_categories;
+DROP FUNCTION IF EXISTS sub_categories_search_refresh();
+DROP TABLE sub_categories;
+
+-- compatibility view of the old sub_categories table : every category below the top level,
+-- parent_category_id is its top level category so that listings and reports keep grouping by it
+CREATE VIEW sub_categories AS
+SELECT id, CAST(split_part(path, '/', 2) AS INTEGER) AS parent_category_id, name, slug, path,
+       created_at, updated_at, deleted_at, is_deleted
+FROM categories
+WHERE parent_id IS NOT NULL;
+
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+CREATE INDEX idx_categories_path ON categories(path text_pattern_ops);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_product_attribute_values_attribute_value;
+DROP TABLE IF EXISTS product_attribute_values;
+DROP TABLE IF EXISTS product_attributes;
@@ -0,0 +1,36 @@
+-- attribute schema of a sub category, it applies to the products of the sub category
+-- and of all the sub categories below it
+CREATE TABLE IF NOT EXISTS product_attributes (
+    id SERIAL PRIMARY KEY,
+    sub_category_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    allowed_values TEXT[] NOT NULL DEFAULT '{}',
+    is_filterable BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_product_attributes_sub_category
+        FOREIGN KEY (sub_category_id)
+        REFERENCES categories(id)
+        ON DELETE CASCADE,
+    CONSTRAINT uq_product_attributes_sub_category_slug UNIQUE (sub_category_id, slug),
+    CONSTRAINT chk_product_attributes_type CHECK (type IN ('text', 'number', 'boolean', 'select'))
+);
+
+CREATE TABLE IF NOT EXISTS product_attribute_values (
+    product_id BIGINT NOT NULL,
+    attribute_id INTEGER NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    PRIMARY KEY (product_id, attribute_id),
+    CONSTRAINT fk_product_attribute_values_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_product_attribute_values_attribute
+        FOREIGN KEY (attribute_id)
+        REFERENCES product_attributes(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_attribute_values_attribute_value ON product_attribute_values(attribute_id, LOWER(value));
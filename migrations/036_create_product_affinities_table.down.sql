@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_product_affinities_product_score;
+DROP TABLE IF EXISTS product_affinities;
@@ -0,0 +1,19 @@
+-- co-purchase affinity of the products, rebuilt periodically from the completed orders.
+-- score is the number of completed orders in which both products were bought together
+CREATE TABLE IF NOT EXISTS product_affinities (
+    product_id BIGINT NOT NULL,
+    related_product_id BIGINT NOT NULL,
+    score INTEGER NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, related_product_id),
+    CONSTRAINT fk_product_affinities_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_product_affinities_related_product
+        FOREIGN KEY (related_product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_affinities_product_score ON product_affinities(product_id, score DESC);
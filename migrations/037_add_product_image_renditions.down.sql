@@ -0,0 +1,3 @@
+ALTER TABLE product_images
+    DROP COLUMN IF EXISTS medium_url,
+    DROP COLUMN IF EXISTS thumbnail_url;
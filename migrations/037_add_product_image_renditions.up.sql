@@ -0,0 +1,5 @@
+-- resized renditions generated on upload, listings use the thumbnail.
+-- They are null for the images uploaded before the renditions were introduced
+ALTER TABLE product_images
+    ADD COLUMN thumbnail_url TEXT,
+    ADD COLUMN medium_url TEXT;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_product_images_product_position;
+
+ALTER TABLE product_images
+    DROP COLUMN IF EXISTS alt_text,
+    DROP COLUMN IF EXISTS position;
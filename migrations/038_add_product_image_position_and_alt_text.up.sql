@@ -0,0 +1,15 @@
+-- gallery order of the images, the image at the lowest position is the primary image
+ALTER TABLE product_images
+    ADD COLUMN position INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN alt_text VARCHAR(255) NOT NULL DEFAULT '';
+
+-- existing galleries keep their order : primary image first, then the upload order
+UPDATE product_images pi
+SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY is_primary DESC, created_at, id) - 1 AS position
+    FROM product_images
+) ordered
+WHERE pi.id = ordered.id;
+
+CREATE INDEX idx_product_images_product_position ON product_images (product_id, position);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_product_slug_history_product_id;
+DROP TABLE IF EXISTS product_slug_history;
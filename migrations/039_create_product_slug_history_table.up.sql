@@ -0,0 +1,14 @@
+-- previous slugs of the products, old product urls redirect to the current slug
+CREATE TABLE IF NOT EXISTS product_slug_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_product_slug_history_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE,
+    CONSTRAINT uq_product_slug_history_slug UNIQUE (slug)
+);
+
+CREATE INDEX idx_product_slug_history_product_id ON product_slug_history(product_id);
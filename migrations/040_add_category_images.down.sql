@@ -0,0 +1,3 @@
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS banner_url,
+    DROP COLUMN IF EXISTS image_url;
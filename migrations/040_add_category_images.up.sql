@@ -0,0 +1,4 @@
+-- images shown by the storefront navigation, a small image for the menus and a wide banner for the category page
+ALTER TABLE categories
+    ADD COLUMN image_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN banner_url TEXT NOT NULL DEFAULT '';
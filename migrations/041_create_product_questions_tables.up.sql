@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS product_questions (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    question TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'hidden')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_product_questions_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_product_questions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_questions_product_status ON product_questions(product_id, status);
+CREATE INDEX idx_product_questions_status ON product_questions(status);
+
+-- an answer is given either by an admin or by a user who bought the product
+CREATE TABLE IF NOT EXISTS product_answers (
+    id BIGSERIAL PRIMARY KEY,
+    question_id BIGINT NOT NULL,
+    user_id BIGINT,
+    admin_id INTEGER,
+    answer TEXT NOT NULL,
+    is_verified_buyer BOOLEAN NOT NULL DEFAULT FALSE,
+    upvote_count INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'hidden')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_product_answers_question FOREIGN KEY (question_id) REFERENCES product_questions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_product_answers_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_product_answers_admin FOREIGN KEY (admin_id) REFERENCES admins(id) ON DELETE CASCADE,
+    CONSTRAINT chk_product_answers_author CHECK ((user_id IS NULL) <> (admin_id IS NULL))
+);
+
+CREATE INDEX idx_product_answers_question_status ON product_answers(question_id, status);
+
+CREATE TABLE IF NOT EXISTS product_answer_upvotes (
+    answer_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (answer_id, user_id),
+    CONSTRAINT fk_product_answer_upvotes_answer FOREIGN KEY (answer_id) REFERENCES product_answers(id) ON DELETE CASCADE,
+    CONSTRAINT fk_product_answer_upvotes_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_products_deleted_by_category;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_by_category_id;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS fk_categories_deleted_by_category;
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_by_category_id;
@@ -0,0 +1,16 @@
+-- deleted_by_category_id is the category whose soft delete cascaded to the row,
+-- restoring that category brings back exactly these rows. It is NULL for the rows deleted directly
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_by_category_id INTEGER;
+ALTER TABLE categories ADD CONSTRAINT fk_categories_deleted_by_category
+    FOREIGN KEY (deleted_by_category_id)
+    REFERENCES categories(id)
+    ON DELETE SET NULL;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_by_category_id INTEGER;
+ALTER TABLE products ADD CONSTRAINT fk_products_deleted_by_category
+    FOREIGN KEY (deleted_by_category_id)
+    REFERENCES categories(id)
+    ON DELETE SET NULL;
+
+CREATE INDEX idx_categories_deleted_by_category_id ON categories(deleted_by_category_id);
+CREATE INDEX idx_products_deleted_by_category_id ON products(deleted_by_category_id);
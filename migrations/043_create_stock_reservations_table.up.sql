@@ -0,0 +1,25 @@
+-- stock held for a checkout or an unpaid order, available to sell stock is the stock minus the active reservations.
+-- A reservation becomes committed once the stock is decremented for the order
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id BIGSERIAL PRIMARY KEY,
+    checkout_id BIGINT NOT NULL,
+    order_id BIGINT,
+    user_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'committed', 'released', 'expired')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_stock_reservations_checkout FOREIGN KEY (checkout_id) REFERENCES checkout_sessions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_reservations_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_reservations_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_reservations_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_reservations_variant FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_stock_reservations_active_product ON stock_reservations(product_id) WHERE status = 'active';
+CREATE INDEX idx_stock_reservations_active_variant ON stock_reservations(variant_id) WHERE status = 'active';
+CREATE INDEX idx_stock_reservations_checkout_id ON stock_reservations(checkout_id);
+CREATE INDEX idx_stock_reservations_order_id ON stock_reservations(order_id);
@@ -0,0 +1,31 @@
+-- every change of stock_quantity is recorded as a movement, the stock can be recomputed as the sum of the movements.
+-- A movement with variant_id NULL changes the product stock, otherwise it changes the variant stock
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    quantity_change INTEGER NOT NULL CHECK (quantity_change <> 0),
+    stock_after INTEGER NOT NULL,
+    reason VARCHAR(30) NOT NULL CHECK (reason IN ('opening_balance', 'initial_stock', 'adjustment', 'import', 'order', 'cancellation', 'return', 'variant_deleted')),
+    reference_type VARCHAR(20) NOT NULL CHECK (reference_type IN ('order', 'return', 'admin', 'system')),
+    reference_id BIGINT,
+    actor_type VARCHAR(20) NOT NULL CHECK (actor_type IN ('user', 'admin', 'system')),
+    actor_id BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_stock_movements_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_movements_variant FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_stock_movements_product_id ON stock_movements(product_id, created_at DESC);
+CREATE INDEX idx_stock_movements_variant_id ON stock_movements(variant_id);
+
+-- opening balance of the existing stock, so that the ledger matches the current stock
+INSERT INTO stock_movements (product_id, variant_id, quantity_change, stock_after, reason, reference_type, actor_type)
+SELECT id, NULL, stock_quantity, stock_quantity, 'opening_balance', 'system', 'system'
+FROM products
+WHERE stock_quantity <> 0;
+
+INSERT INTO stock_movements (product_id, variant_id, quantity_change, stock_after, reason, reference_type, actor_type)
+SELECT product_id, id, stock_quantity, stock_quantity, 'opening_balance', 'system', 'system'
+FROM product_variants
+WHERE stock_quantity <> 0;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS stock_alerts;
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_threshold;
@@ -0,0 +1,20 @@
+-- stock at or below the reorder threshold of the product raises an alert, NULL threshold means the product is not watched
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_threshold INTEGER CHECK (reorder_threshold >= 0);
+
+-- an alert stays open till the stock is back above the threshold, there is at most one open alert per product
+CREATE TABLE IF NOT EXISTS stock_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    reorder_threshold INTEGER NOT NULL,
+    stock_quantity INTEGER NOT NULL,
+    daily_sales_velocity NUMERIC(10,2) NOT NULL DEFAULT 0,
+    suggested_reorder_quantity INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved')),
+    emailed_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_stock_alerts_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_stock_alerts_open_product ON stock_alerts(product_id) WHERE status = 'open';
+CREATE INDEX idx_stock_alerts_status ON stock_alerts(status, created_at DESC);
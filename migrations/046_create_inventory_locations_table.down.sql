@@ -0,0 +1,16 @@
+DELETE FROM stock_movements WHERE reason = 'transfer';
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reference_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reference_type_check
+    CHECK (reference_type IN ('order', 'return', 'admin', 'system'));
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reason_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reason_check
+    CHECK (reason IN ('opening_balance', 'initial_stock', 'adjustment', 'import', 'order', 'cancellation', 'return', 'variant_deleted'));
+
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS location_id;
+
+DROP TABLE IF EXISTS stock_transfers;
+DROP TABLE IF EXISTS order_allocations;
+DROP TABLE IF EXISTS location_stock;
+DROP TABLE IF EXISTS inventory_locations;
@@ -0,0 +1,92 @@
+-- warehouses and stores holding stock, the active location with the lowest priority is the primary location
+CREATE TABLE IF NOT EXISTS inventory_locations (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    pincode VARCHAR(20) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO inventory_locations (name, code, pincode, priority)
+VALUES ('Main Warehouse', 'MAIN', '000000', 0);
+
+-- stock of the product (variant_id NULL) or of the variant at each location,
+-- stock_quantity of products and product_variants is the sum over the locations
+CREATE TABLE IF NOT EXISTS location_stock (
+    id BIGSERIAL PRIMARY KEY,
+    location_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    stock_quantity INTEGER NOT NULL DEFAULT 0 CHECK (stock_quantity >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_location_stock_location FOREIGN KEY (location_id) REFERENCES inventory_locations(id),
+    CONSTRAINT fk_location_stock_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_location_stock_variant FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_location_stock_item ON location_stock(location_id, product_id, (COALESCE(variant_id, 0)));
+CREATE INDEX idx_location_stock_product ON location_stock(product_id, variant_id);
+
+-- the existing stock is held at the main warehouse
+INSERT INTO location_stock (location_id, product_id, variant_id, stock_quantity)
+SELECT l.id, p.id, NULL, p.stock_quantity
+FROM products p, inventory_locations l
+WHERE l.code = 'MAIN' AND p.stock_quantity > 0
+  AND NOT EXISTS (SELECT 1 FROM product_variants pv WHERE pv.product_id = p.id AND pv.is_deleted = false);
+
+INSERT INTO location_stock (location_id, product_id, variant_id, stock_quantity)
+SELECT l.id, pv.product_id, pv.id, pv.stock_quantity
+FROM product_variants pv, inventory_locations l
+WHERE l.code = 'MAIN' AND pv.stock_quantity > 0 AND pv.is_deleted = false;
+
+-- the locations the stock of each order item was taken from, released when the order is cancelled or returned
+CREATE TABLE IF NOT EXISTS order_allocations (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    location_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'allocated' CHECK (status IN ('allocated', 'released')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    released_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_order_allocations_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_allocations_location FOREIGN KEY (location_id) REFERENCES inventory_locations(id),
+    CONSTRAINT fk_order_allocations_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_allocations_variant FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_order_allocations_order ON order_allocations(order_id, status);
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    from_location_id BIGINT NOT NULL,
+    to_location_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    variant_id BIGINT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    note TEXT,
+    created_by BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_stock_transfers_locations CHECK (from_location_id <> to_location_id),
+    CONSTRAINT fk_stock_transfers_from FOREIGN KEY (from_location_id) REFERENCES inventory_locations(id),
+    CONSTRAINT fk_stock_transfers_to FOREIGN KEY (to_location_id) REFERENCES inventory_locations(id),
+    CONSTRAINT fk_stock_transfers_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_transfers_variant FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_stock_transfers_created_at ON stock_transfers(created_at DESC);
+
+-- movements record the location whose stock changed, a transfer is recorded as a movement out of one location and into another
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS location_id BIGINT REFERENCES inventory_locations(id);
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reason_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reason_check
+    CHECK (reason IN ('opening_balance', 'initial_stock', 'adjustment', 'import', 'order', 'cancellation', 'return', 'variant_deleted', 'transfer'));
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reference_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reference_type_check
+    CHECK (reference_type IN ('order', 'return', 'admin', 'system', 'transfer'));
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SendCacheableResponse sends a successful response which public caches can keep for maxAge.
+// The ETag of the body lets the clients revalidate, an unchanged body is answered with 304 Not Modified
+func SendCacheableResponse(w http.ResponseWriter, r *http.Request, maxAge time.Duration, message string, data interface{}) {
+	body, err := json.Marshal(Response{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    data,
+	})
+	if err != nil {
+		SendResponse(w, http.StatusInternalServerError, message, nil, "An unexpected error occurred")
+		return
+	}
+
+	hash := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(body, '\n'))
+}
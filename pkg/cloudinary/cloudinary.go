@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"log"
 	"mime/multipart"
+	"path"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/storage"
 )
 
 type CloudinaryService struct {
@@ -28,7 +32,8 @@ func NewCloudinaryService(cloudName, apiKey, apiSecret string) (*CloudinaryServi
 	return &CloudinaryService{cld: cld}, nil
 }
 
-func (s *CloudinaryService) UploadImage(ctx context.Context, file multipart.File, productSlug string) (string, error) {
+// UploadImage uploads the image, the renditions are resized by cloudinary on delivery using url transformations
+func (s *CloudinaryService) UploadImage(ctx context.Context, file multipart.File, productSlug string) (*storage.UploadedImage, error) {
 	uploadParams := uploader.UploadParams{
 		PublicID: productSlug + "_" + generateUniqueID(),
 		Folder:   "product_images",
@@ -37,17 +42,41 @@ func (s *CloudinaryService) UploadImage(ctx context.Context, file multipart.File
 	result, err := s.cld.Upload.Upload(ctx, file, uploadParams)
 	if err != nil {
 		log.Printf("error while uploading to cloudinary :%v", err)
-		return "", err
+		return nil, err
 	}
 
-	return result.SecureURL, nil
+	return &storage.UploadedImage{
+		URL:          result.SecureURL,
+		ThumbnailURL: renditionURL(result.SecureURL, storage.ThumbnailSize),
+		MediumURL:    renditionURL(result.SecureURL, storage.MediumSize),
+	}, nil
 }
 
-func (s *CloudinaryService) DeleteImage(ctx context.Context, publicID string) error {
-	_, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: publicID})
+// DeleteImage deletes the image with the given url, the renditions are derived from it and go along with it
+func (s *CloudinaryService) DeleteImage(ctx context.Context, imageURL string) error {
+	_, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: publicIDFromURL(imageURL)})
 	return err
 }
 
+// renditionURL adds a resize transformation to the delivery url, the image fits in a size x size box
+func renditionURL(imageURL string, size int) string {
+	transformation := fmt.Sprintf("/upload/c_limit,w_%d,h_%d/", size, size)
+	return strings.Replace(imageURL, "/upload/", transformation, 1)
+}
+
+var versionSegment = regexp.MustCompile(`^v\d+/`)
+
+// publicIDFromURL extracts the public id from a delivery url
+// e.g. https://res.cloudinary.com/demo/image/upload/v1712/product_images/shirt_1712.jpg -> product_images/shirt_1712
+func publicIDFromURL(imageURL string) string {
+	_, publicID, found := strings.Cut(imageURL, "/upload/")
+	if !found {
+		return imageURL
+	}
+	publicID = versionSegment.ReplaceAllString(publicID, "")
+	return strings.TrimSuffix(publicID, path.Ext(publicID))
+}
+
 func generateUniqueID() string {
 	// Implement a function to generate a unique ID
 	return fmt.Sprintf("%d", time.Now().UnixNano())
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
 )
@@ -12,6 +13,7 @@ import (
 type EmailSender interface {
 	SendOTP(to, otp string) error
 	SendPasswordResetToken(to, token string) error
+	SendLowStockAlert(to string, alerts []*domain.StockAlert) error
 }
 
 // Sender implements EmailSender using SendGrid HTTP API.
@@ -69,3 +71,17 @@ func (s *Sender) SendPasswordResetToken(to, token string) error {
 	body := fmt.Sprintf("Your password reset token is: %s", token)
 	return s.send(to, subject, body)
 }
+
+// SendLowStockAlert sends a single email listing the products at or below their reorder threshold
+func (s *Sender) SendLowStockAlert(to string, alerts []*domain.StockAlert) error {
+	subject := fmt.Sprintf("Low stock alert: %d product(s) need reordering", len(alerts))
+
+	var body strings.Builder
+	body.WriteString("The following products are at or below their reorder threshold:\n\n")
+	for _, alert := range alerts {
+		fmt.Fprintf(&body, "- %s (ID %d): %d in stock, threshold %d, selling %.2f per day, suggested reorder %d\n",
+			alert.ProductName, alert.ProductID, alert.StockQuantity, alert.ReorderThreshold,
+			alert.DailySalesVelocity, alert.SuggestedReorderQuantity)
+	}
+	return s.send(to, subject, body.String())
+}
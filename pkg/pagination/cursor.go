@@ -0,0 +1,155 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+const (
+	DirectionNext = "next"
+	DirectionPrev = "prev"
+
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// Cursor points to the row a page starts after (next) or ends before (prev).
+// Rows are ordered newest first on (created_at, id), or on id alone when CreatedAt is nil.
+type Cursor struct {
+	CreatedAt *time.Time `json:"t,omitempty"`
+	ID        int64      `json:"id"`
+	Direction string     `json:"d"`
+}
+
+// Params holds the requested cursor page, Cursor is nil for the first page
+type Params struct {
+	Cursor *Cursor
+	Limit  int
+}
+
+// Page holds the cursors of the pages around the returned page, empty when there is no such page
+type Page struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Limit      int    `json:"limit"`
+}
+
+// NewParams decodes the opaque cursor given by the client and validates the limit
+func NewParams(cursor string, limit int) (Params, error) {
+	if limit < 1 || limit > MaxLimit {
+		limit = DefaultLimit
+	}
+	params := Params{Limit: limit}
+	if cursor == "" {
+		return params, nil
+	}
+
+	c, err := Decode(cursor)
+	if err != nil {
+		return params, err
+	}
+	params.Cursor = c
+	return params, nil
+}
+
+// FromRequest tells if cursor pagination is asked for, either with pagination=cursor
+// for the first page or with the cursor of another page, and returns its params
+func FromRequest(r *http.Request) (Params, bool, error) {
+	cursor := r.URL.Query().Get("cursor")
+	if cursor == "" && r.URL.Query().Get("pagination") != "cursor" {
+		return Params{}, false, nil
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	params, err := NewParams(cursor, limit)
+	return params, true, err
+}
+
+func Encode(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func Decode(cursor string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, utils.ErrInvalidCursor
+	}
+	if c.Direction != DirectionNext && c.Direction != DirectionPrev {
+		return nil, utils.ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// IsPrev tells if the page is fetched backwards, the fetched rows then have to be reversed
+func (p Params) IsPrev() bool {
+	return p.Cursor != nil && p.Cursor.Direction == DirectionPrev
+}
+
+/*
+Clause:
+- Returns the keyset condition, its arguments and the order by clause of the page
+- createdAtColumn is empty when the rows are ordered on the id column alone
+- argPos is the position of the first placeholder of the condition
+- One more row than the limit is fetched, to know if there are more rows
+*/
+func (p Params) Clause(createdAtColumn, idColumn string, argPos int) (string, []interface{}, string) {
+	comparison, direction := "<", "DESC"
+	if p.IsPrev() {
+		comparison, direction = ">", "ASC"
+	}
+
+	orderBy := fmt.Sprintf(" ORDER BY %s %s LIMIT %d", idColumn, direction, p.Limit+1)
+	if createdAtColumn != "" {
+		orderBy = fmt.Sprintf(" ORDER BY %s %s, %s %s LIMIT %d", createdAtColumn, direction, idColumn, direction, p.Limit+1)
+	}
+
+	if p.Cursor == nil {
+		return "", nil, orderBy
+	}
+
+	if createdAtColumn != "" && p.Cursor.CreatedAt != nil {
+		condition := fmt.Sprintf("(%s, %s) %s ($%d, $%d)", createdAtColumn, idColumn, comparison, argPos, argPos+1)
+		return condition, []interface{}{*p.Cursor.CreatedAt, p.Cursor.ID}, orderBy
+	}
+	condition := fmt.Sprintf("%s %s $%d", idColumn, comparison, argPos)
+	return condition, []interface{}{p.Cursor.ID}, orderBy
+}
+
+/*
+NewPage:
+- hasMore tells if more rows were found than the limit in the fetched direction
+- first and last are the keys of the first and last rows of the page, in display order
+*/
+func NewPage(p Params, hasMore bool, first, last *Cursor) Page {
+	page := Page{Limit: p.Limit}
+	if first == nil || last == nil {
+		return page
+	}
+
+	hasNext := hasMore
+	hasPrev := p.Cursor != nil
+	if p.IsPrev() {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	if hasNext {
+		last.Direction = DirectionNext
+		page.NextCursor = Encode(*last)
+	}
+	if hasPrev {
+		first.Direction = DirectionPrev
+		page.PrevCursor = Encode(*first)
+	}
+	return page
+}
@@ -0,0 +1,219 @@
+package pagination
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{name: "id only", cursor: Cursor{ID: 42, Direction: DirectionNext}},
+		{name: "created at and id", cursor: Cursor{CreatedAt: &createdAt, ID: 7, Direction: DirectionPrev}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(Encode(tt.cursor))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got.ID != tt.cursor.ID || got.Direction != tt.cursor.Direction {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.cursor)
+			}
+			if (got.CreatedAt == nil) != (tt.cursor.CreatedAt == nil) ||
+				(got.CreatedAt != nil && !got.CreatedAt.Equal(*tt.cursor.CreatedAt)) {
+				t.Errorf("Decode() created at = %v, want %v", got.CreatedAt, tt.cursor.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "%%%"},
+		{name: "not json", cursor: "bm90IGpzb24"},
+		{name: "unknown direction", cursor: Encode(Cursor{ID: 1, Direction: "up"})},
+		{name: "missing direction", cursor: Encode(Cursor{ID: 1})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.cursor)
+			if !errors.Is(err, utils.ErrInvalidCursor) {
+				t.Errorf("Decode() error = %v, want %v", err, utils.ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestNewParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		cursor    string
+		limit     int
+		wantLimit int
+		wantErr   error
+	}{
+		{name: "default limit", limit: 0, wantLimit: DefaultLimit},
+		{name: "limit too high", limit: MaxLimit + 1, wantLimit: DefaultLimit},
+		{name: "valid limit", limit: 25, wantLimit: 25},
+		{name: "with cursor", cursor: Encode(Cursor{ID: 3, Direction: DirectionNext}), limit: 5, wantLimit: 5},
+		{name: "invalid cursor", cursor: "%%%", limit: 5, wantLimit: 5, wantErr: utils.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := NewParams(tt.cursor, tt.limit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewParams() error = %v, want %v", err, tt.wantErr)
+			}
+			if params.Limit != tt.wantLimit {
+				t.Errorf("NewParams() limit = %d, want %d", params.Limit, tt.wantLimit)
+			}
+			if tt.wantErr == nil && (params.Cursor != nil) != (tt.cursor != "") {
+				t.Errorf("NewParams() cursor = %+v, given %q", params.Cursor, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestClause(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		params          Params
+		createdAtColumn string
+		argPos          int
+		wantCondition   string
+		wantArgs        []interface{}
+		wantOrderBy     string
+	}{
+		{
+			name:        "first page on id",
+			params:      Params{Limit: 10},
+			argPos:      1,
+			wantOrderBy: " ORDER BY p.id DESC LIMIT 11",
+		},
+		{
+			name:            "first page on created at",
+			params:          Params{Limit: 10},
+			createdAtColumn: "p.created_at",
+			argPos:          1,
+			wantOrderBy:     " ORDER BY p.created_at DESC, p.id DESC LIMIT 11",
+		},
+		{
+			name:          "next page on id",
+			params:        Params{Cursor: &Cursor{ID: 50, Direction: DirectionNext}, Limit: 10},
+			argPos:        3,
+			wantCondition: "p.id < $3",
+			wantArgs:      []interface{}{int64(50)},
+			wantOrderBy:   " ORDER BY p.id DESC LIMIT 11",
+		},
+		{
+			name:          "previous page on id",
+			params:        Params{Cursor: &Cursor{ID: 50, Direction: DirectionPrev}, Limit: 10},
+			argPos:        1,
+			wantCondition: "p.id > $1",
+			wantArgs:      []interface{}{int64(50)},
+			wantOrderBy:   " ORDER BY p.id ASC LIMIT 11",
+		},
+		{
+			name:            "next page on created at",
+			params:          Params{Cursor: &Cursor{CreatedAt: &createdAt, ID: 50, Direction: DirectionNext}, Limit: 5},
+			createdAtColumn: "p.created_at",
+			argPos:          2,
+			wantCondition:   "(p.created_at, p.id) < ($2, $3)",
+			wantArgs:        []interface{}{createdAt, int64(50)},
+			wantOrderBy:     " ORDER BY p.created_at DESC, p.id DESC LIMIT 6",
+		},
+		{
+			name:            "cursor without created at falls back to id",
+			params:          Params{Cursor: &Cursor{ID: 50, Direction: DirectionNext}, Limit: 5},
+			createdAtColumn: "p.created_at",
+			argPos:          1,
+			wantCondition:   "p.id < $1",
+			wantArgs:        []interface{}{int64(50)},
+			wantOrderBy:     " ORDER BY p.created_at DESC, p.id DESC LIMIT 6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args, orderBy := tt.params.Clause(tt.createdAtColumn, "p.id", tt.argPos)
+			if condition != tt.wantCondition {
+				t.Errorf("Clause() condition = %q, want %q", condition, tt.wantCondition)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Clause() args = %v, want %v", args, tt.wantArgs)
+			}
+			if orderBy != tt.wantOrderBy {
+				t.Errorf("Clause() order by = %q, want %q", orderBy, tt.wantOrderBy)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   Params
+		hasMore  bool
+		empty    bool
+		wantNext bool
+		wantPrev bool
+	}{
+		{name: "only page", params: Params{Limit: 10}},
+		{name: "first of many pages", params: Params{Limit: 10}, hasMore: true, wantNext: true},
+		{name: "middle page", params: Params{Cursor: &Cursor{ID: 9, Direction: DirectionNext}, Limit: 10}, hasMore: true, wantNext: true, wantPrev: true},
+		{name: "last page", params: Params{Cursor: &Cursor{ID: 9, Direction: DirectionNext}, Limit: 10}, wantPrev: true},
+		{name: "back to the first page", params: Params{Cursor: &Cursor{ID: 9, Direction: DirectionPrev}, Limit: 10}, wantNext: true},
+		{name: "back to a middle page", params: Params{Cursor: &Cursor{ID: 9, Direction: DirectionPrev}, Limit: 10}, hasMore: true, wantNext: true, wantPrev: true},
+		{name: "empty page", params: Params{Cursor: &Cursor{ID: 9, Direction: DirectionNext}, Limit: 10}, empty: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var first, last *Cursor
+			if !tt.empty {
+				first, last = &Cursor{ID: 20}, &Cursor{ID: 11}
+			}
+
+			page := NewPage(tt.params, tt.hasMore, first, last)
+			if page.Limit != tt.params.Limit {
+				t.Errorf("NewPage() limit = %d, want %d", page.Limit, tt.params.Limit)
+			}
+			if (page.NextCursor != "") != tt.wantNext {
+				t.Errorf("NewPage() next cursor = %q, want next: %v", page.NextCursor, tt.wantNext)
+			}
+			if (page.PrevCursor != "") != tt.wantPrev {
+				t.Errorf("NewPage() prev cursor = %q, want prev: %v", page.PrevCursor, tt.wantPrev)
+			}
+
+			if tt.wantNext {
+				next, err := Decode(page.NextCursor)
+				if err != nil || next.ID != 11 || next.Direction != DirectionNext {
+					t.Errorf("NewPage() next cursor = %+v, %v, want id 11 going next", next, err)
+				}
+			}
+			if tt.wantPrev {
+				prev, err := Decode(page.PrevCursor)
+				if err != nil || prev.ID != 20 || prev.Direction != DirectionPrev {
+					t.Errorf("NewPage() prev cursor = %+v, %v, want id 20 going prev", prev, err)
+				}
+			}
+		})
+	}
+}
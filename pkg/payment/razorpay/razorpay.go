@@ -23,6 +23,13 @@ type Order struct {
 	Currency string `json:"currency"`
 }
 
+// Payment struct represents the details of a payment made against a Razorpay order.
+type Payment struct {
+	ID      string `json:"id"`
+	OrderID string `json:"order_id"`
+	Status  string `json:"status"` // created, authorized, captured, refunded or failed
+}
+
 // creates a Razorpay client that will be used for subsequent API calls.
 func NewService(keyID, keySecret string) *Service {
 	client := razorpay.NewClient(keyID, keySecret)
@@ -70,6 +77,35 @@ func (s *Service) CreateOrder(amount int64, currency string) (*Order, error) {
 	return order, nil
 }
 
+// FetchPayment gets the payment from Razorpay, so that its status doesn't depend on what the client reports
+func (s *Service) FetchPayment(paymentID string) (*Payment, error) {
+	body, err := s.client.Payment.Fetch(paymentID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := body["id"].(string)
+	if !ok {
+		return nil, errors.New("invalid payment id type")
+	}
+
+	orderID, ok := body["order_id"].(string)
+	if !ok {
+		return nil, errors.New("invalid order id type")
+	}
+
+	status, ok := body["status"].(string)
+	if !ok {
+		return nil, errors.New("invalid status type")
+	}
+
+	return &Payment{
+		ID:      id,
+		OrderID: orderID,
+		Status:  status,
+	}, nil
+}
+
 func (s *Service) VerifyPaymentSignature(attributes map[string]interface{}) error {
 	orderId, ok := attributes["razorpay_order_id"].(string)
 	if !ok {
@@ -0,0 +1,211 @@
+package productcatalog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatExcel = "excel"
+
+	// MaxRows is the maximum number of product rows accepted in one import file
+	MaxRows = 5000
+
+	sheetName = "Products"
+
+	// formulaPrefixes are the first characters which make spreadsheet apps read a cell as a formula
+	formulaPrefixes = "=+-@"
+)
+
+// Header is the column layout shared by the import and export files
+var Header = []string{"name", "description", "price", "stock_quantity", "sub_category_slug", "brand_slug"}
+
+// FormatFromFilename returns the file format based on the extension of the uploaded file
+func FormatFromFilename(filename string) (string, error) {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV, nil
+	case strings.HasSuffix(name, ".xlsx"):
+		return FormatExcel, nil
+	default:
+		return "", utils.ErrUnsupportedFileFormat
+	}
+}
+
+/*
+ReadRows:
+- Read all the records of the csv or excel file
+- First record should be the header, it is verified and left out
+- Empty records are left out, other records are padded to the header length
+- Cells escaped by Write are read back as they were
+- Returns the records along with their line numbers in the file
+*/
+func ReadRows(r io.Reader, format string) ([][]string, []int, error) {
+	var records [][]string
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1 // column count is verified per row
+		reader.TrimLeadingSpace = true
+		var err error
+		records, err = reader.ReadAll()
+		if err != nil {
+			log.Printf("error while reading csv import file : %v", err)
+			return nil, nil, utils.ErrInvalidImportFile
+		}
+	case FormatExcel:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			log.Printf("error while opening excel import file : %v", err)
+			return nil, nil, utils.ErrInvalidImportFile
+		}
+		defer f.Close()
+		records, err = f.GetRows(f.GetSheetName(0))
+		if err != nil {
+			log.Printf("error while reading excel import file : %v", err)
+			return nil, nil, utils.ErrInvalidImportFile
+		}
+	default:
+		return nil, nil, utils.ErrUnsupportedFileFormat
+	}
+
+	if len(records) == 0 || !isHeader(records[0]) {
+		return nil, nil, utils.ErrInvalidImportHeader
+	}
+
+	var rows [][]string
+	var lineNumbers []int
+	for i, record := range records[1:] {
+		if isEmptyRecord(record) {
+			continue
+		}
+		for len(record) < len(Header) {
+			record = append(record, "")
+		}
+		for j := range record {
+			record[j] = unescapeCell(record[j])
+		}
+		rows = append(rows, record)
+		lineNumbers = append(lineNumbers, i+2) // header is line 1
+	}
+
+	if len(rows) == 0 {
+		return nil, nil, utils.ErrEmptyImportFile
+	}
+	if len(rows) > MaxRows {
+		return nil, nil, utils.ErrTooManyImportRows
+	}
+
+	return rows, lineNumbers, nil
+}
+
+// Write generates the csv or excel file of the given products
+func Write(products []*domain.ProductCatalogRow, format string) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		return writeCSV(products)
+	case FormatExcel:
+		return writeExcel(products)
+	default:
+		return nil, utils.ErrUnsupportedFileFormat
+	}
+}
+
+func writeCSV(products []*domain.ProductCatalogRow) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	writer.Write(Header)
+	for _, p := range products {
+		writer.Write(toRecord(p))
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("error while writing csv export file : %v", err)
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeExcel(products []*domain.ProductCatalogRow) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	// rename the default sheet instead of adding a new one
+	f.SetSheetName(f.GetSheetName(0), sheetName)
+
+	f.SetSheetRow(sheetName, "A1", &Header)
+	for i, p := range products {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		record := toRecord(p)
+		f.SetSheetRow(sheetName, cell, &record)
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		log.Printf("error while writing excel export file : %v", err)
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func toRecord(p *domain.ProductCatalogRow) []string {
+	return []string{
+		escapeCell(p.Name),
+		escapeCell(p.Description),
+		strconv.FormatFloat(p.Price, 'f', 2, 64),
+		strconv.Itoa(p.StockQuantity),
+		escapeCell(p.SubCategorySlug),
+		escapeCell(p.BrandSlug),
+	}
+}
+
+// escapeCell prefixes a text cell which would be read as a formula with a quote, so that it stays text when the file is opened
+func escapeCell(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCell removes the quote escapeCell added
+func unescapeCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+func isHeader(record []string) bool {
+	if len(record) < len(Header) {
+		return false
+	}
+	for i, column := range Header {
+		// files saved from spreadsheet apps may start with a byte order mark
+		value := strings.TrimPrefix(record[i], "\ufeff")
+		if strings.ToLower(strings.TrimSpace(value)) != column {
+			return false
+		}
+	}
+	return true
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package productcatalog
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+const csvHeader = "name,description,price,stock_quantity,sub_category_slug,brand_slug\n"
+
+func TestFormatFromFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+		wantErr  error
+	}{
+		{filename: "products.csv", want: FormatCSV},
+		{filename: "Products.XLSX", want: FormatExcel},
+		{filename: "products.xls", wantErr: utils.ErrUnsupportedFileFormat},
+		{filename: "products", wantErr: utils.ErrUnsupportedFileFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			got, err := FormatFromFilename(tt.filename)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("FormatFromFilename(%q) = %q, %v, want %q, %v", tt.filename, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadRowsCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		wantRows  [][]string
+		wantLines []int
+		wantErr   error
+	}{
+		{
+			name:      "rows with line numbers",
+			file:      csvHeader + "jersey,home kit,999.00,10,football/jerseys,nike\nball,,499,5,football/balls,\n",
+			wantRows:  [][]string{{"jersey", "home kit", "999.00", "10", "football/jerseys", "nike"}, {"ball", "", "499", "5", "football/balls", ""}},
+			wantLines: []int{2, 3},
+		},
+		{
+			name:      "empty rows are skipped and short rows padded",
+			file:      csvHeader + ",,,,,\njersey,home kit,999,10\n",
+			wantRows:  [][]string{{"jersey", "home kit", "999", "10", "", ""}},
+			wantLines: []int{3},
+		},
+		{
+			name:      "header with byte order mark and other case",
+			file:      "\ufeffName,Description,Price,Stock_Quantity,Sub_Category_Slug,Brand_Slug\njersey,kit,999,10,football/jerseys,\n",
+			wantRows:  [][]string{{"jersey", "kit", "999", "10", "football/jerseys", ""}},
+			wantLines: []int{2},
+		},
+		{
+			name:      "escaped cells are read back",
+			file:      csvHeader + "'-50% jersey,'=kit,999,10,football/jerseys,\n",
+			wantRows:  [][]string{{"-50% jersey", "=kit", "999", "10", "football/jerseys", ""}},
+			wantLines: []int{2},
+		},
+		{name: "missing header", file: "jersey,home kit,999,10,football/jerseys,nike\n", wantErr: utils.ErrInvalidImportHeader},
+		{name: "empty file", file: "", wantErr: utils.ErrInvalidImportHeader},
+		{name: "header only", file: csvHeader, wantErr: utils.ErrEmptyImportFile},
+		{name: "broken quotes", file: csvHeader + "\"jersey,kit\n", wantErr: utils.ErrInvalidImportFile},
+		{name: "too many rows", file: csvHeader + strings.Repeat("jersey,kit,999,10,football/jerseys,\n", MaxRows+1), wantErr: utils.ErrTooManyImportRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, lines, err := ReadRows(strings.NewReader(tt.file), FormatCSV)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadRows() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("ReadRows() rows = %q, want %q", rows, tt.wantRows)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("ReadRows() lines = %v, want %v", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestReadRowsUnsupportedFormat(t *testing.T) {
+	_, _, err := ReadRows(strings.NewReader(csvHeader), "json")
+	if !errors.Is(err, utils.ErrUnsupportedFileFormat) {
+		t.Errorf("ReadRows() error = %v, want %v", err, utils.ErrUnsupportedFileFormat)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		products []*domain.ProductCatalogRow
+		want     string
+	}{
+		{name: "header only", want: csvHeader},
+		{
+			name: "products",
+			products: []*domain.ProductCatalogRow{
+				{Name: "jersey", Description: "home kit, size m", Price: 999, StockQuantity: 10, SubCategorySlug: "football/jerseys", BrandSlug: "nike"},
+			},
+			want: csvHeader + "jersey,\"home kit, size m\",999.00,10,football/jerseys,nike\n",
+		},
+		{
+			name: "formula cells are escaped",
+			products: []*domain.ProductCatalogRow{
+				{Name: "=HYPERLINK(\"x\")", Description: "+1 free", Price: 5, StockQuantity: 1, SubCategorySlug: "-a", BrandSlug: "@b"},
+			},
+			want: csvHeader + "\"'=HYPERLINK(\"\"x\"\")\",'+1 free,5.00,1,'-a,'@b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Write(tt.products, FormatCSV)
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Write() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	products := []*domain.ProductCatalogRow{
+		{Name: "jersey", Description: "home kit", Price: 999.5, StockQuantity: 10, SubCategorySlug: "football/jerseys", BrandSlug: "nike"},
+		{Name: "-50% ball", Description: "=match ball", Price: 499, StockQuantity: 0, SubCategorySlug: "football/balls"},
+	}
+	want := [][]string{
+		{"jersey", "home kit", "999.50", "10", "football/jerseys", "nike"},
+		{"-50% ball", "=match ball", "499.00", "0", "football/balls", ""},
+	}
+
+	for _, format := range []string{FormatCSV, FormatExcel} {
+		t.Run(format, func(t *testing.T) {
+			file, err := Write(products, format)
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			rows, lines, err := ReadRows(bytes.NewReader(file), format)
+			if err != nil {
+				t.Fatalf("ReadRows() error = %v", err)
+			}
+			if !reflect.DeepEqual(rows, want) {
+				t.Errorf("ReadRows() rows = %q, want %q", rows, want)
+			}
+			if !reflect.DeepEqual(lines, []int{2, 3}) {
+				t.Errorf("ReadRows() lines = %v, want [2 3]", lines)
+			}
+		})
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	_, err := Write(nil, "json")
+	if !errors.Is(err, utils.ErrUnsupportedFileFormat) {
+		t.Errorf("Write() error = %v, want %v", err, utils.ErrUnsupportedFileFormat)
+	}
+}
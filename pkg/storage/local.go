@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+// LocalStorage stores the images in a directory of the local disk, the application serves them under urlPrefix
+type LocalStorage struct {
+	dir       string
+	urlPrefix string
+}
+
+func NewLocalStorage(dir, urlPrefix string) (*LocalStorage, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("local storage directory is not set")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("Failed to create the local storage directory : %v", err)
+		return nil, err
+	}
+
+	urlPrefix = "/" + strings.Trim(urlPrefix, "/") + "/"
+	return &LocalStorage{dir: dir, urlPrefix: urlPrefix}, nil
+}
+
+/*
+UploadImage:
+- Save the original image as it is
+- Save the thumbnail and medium renditions next to it
+- Only the formats the standard library can decode are accepted (jpeg, png and gif)
+- Dimensions are checked before the image is decoded, so that a small file can't expand into a huge image in memory
+- When a rendition can't be saved, the files already saved for the upload are removed
+*/
+func (s *LocalStorage) UploadImage(ctx context.Context, file multipart.File, productSlug string) (*UploadedImage, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.Printf("error while reading the uploaded image : %v", err)
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("error while reading the dimensions of the uploaded image : %v", err)
+		return nil, err
+	}
+	if config.Width > MaxImageDimension || config.Height > MaxImageDimension {
+		return nil, utils.ErrImageDimensionsTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("error while decoding the uploaded image : %v", err)
+		return nil, err
+	}
+
+	// product slugs have the category path in them, the file is saved directly in the storage directory
+	name := strings.ReplaceAll(productSlug, "/", "_") + "_" + fmt.Sprintf("%d", time.Now().UnixNano())
+	ext := "." + format
+	if format == "jpeg" {
+		ext = ".jpg"
+	}
+
+	uploaded := &UploadedImage{}
+	uploaded.URL, err = s.writeFile(name+ext, data)
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded.ThumbnailURL, err = s.writeRendition(name+"_thumb", img, format, ThumbnailSize)
+	if err != nil {
+		return nil, s.removeUploaded(uploaded, err)
+	}
+
+	uploaded.MediumURL, err = s.writeRendition(name+"_medium", img, format, MediumSize)
+	if err != nil {
+		return nil, s.removeUploaded(uploaded, err)
+	}
+
+	return uploaded, nil
+}
+
+// removeUploaded removes the files saved for the failed upload, the errors of the removal are returned along with the upload error
+func (s *LocalStorage) removeUploaded(uploaded *UploadedImage, uploadErr error) error {
+	errs := []error{uploadErr}
+	for _, url := range []string{uploaded.URL, uploaded.ThumbnailURL, uploaded.MediumURL} {
+		if url == "" {
+			continue
+		}
+		filePath := filepath.Join(s.dir, strings.TrimPrefix(url, s.urlPrefix))
+		err := os.Remove(filePath)
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("error while removing image file %s of the failed upload : %v", filePath, err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 1 {
+		return uploadErr
+	}
+	return errors.Join(errs...)
+}
+
+// DeleteImage removes the image and its renditions, the files which are already gone are ignored.
+// Images uploaded to another storage before switching to the local storage are left as they are
+func (s *LocalStorage) DeleteImage(ctx context.Context, imageURL string) error {
+	fileName, ok := strings.CutPrefix(imageURL, s.urlPrefix)
+	if !ok {
+		log.Printf("image %s is not stored in the local storage, skipping the file deletion", imageURL)
+		return nil
+	}
+	// only the file name is used, so that the url can't point outside the storage directory
+	fileName = path.Base(fileName)
+	name := strings.TrimSuffix(fileName, path.Ext(fileName))
+
+	renditions, err := filepath.Glob(filepath.Join(s.dir, name+"_thumb.*"))
+	if err != nil {
+		return err
+	}
+	mediums, err := filepath.Glob(filepath.Join(s.dir, name+"_medium.*"))
+	if err != nil {
+		return err
+	}
+
+	for _, filePath := range append([]string{filepath.Join(s.dir, fileName)}, append(renditions, mediums...)...) {
+		err := os.Remove(filePath)
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("error while deleting image file %s : %v", filePath, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LocalStorage) URLPrefix() string {
+	return s.urlPrefix
+}
+
+// Handler serves the stored images
+func (s *LocalStorage) Handler() http.Handler {
+	return http.StripPrefix(s.urlPrefix, http.FileServer(http.Dir(s.dir)))
+}
+
+func (s *LocalStorage) writeRendition(name string, img image.Image, format string, size int) (string, error) {
+	data, ext, err := encodeImage(resizeImage(img, size), format)
+	if err != nil {
+		log.Printf("error while encoding image rendition : %v", err)
+		return "", err
+	}
+	return s.writeFile(name+ext, data)
+}
+
+// writeFile saves the file in the storage directory, a partly written file is removed
+func (s *LocalStorage) writeFile(fileName string, data []byte) (string, error) {
+	filePath := filepath.Join(s.dir, fileName)
+	err := os.WriteFile(filePath, data, 0644)
+	if err != nil {
+		log.Printf("error while saving image file : %v", err)
+		if removeErr := os.Remove(filePath); removeErr != nil && !os.IsNotExist(removeErr) {
+			log.Printf("error while removing partly saved image file %s : %v", filePath, removeErr)
+		}
+		return "", err
+	}
+	return s.urlPrefix + fileName, nil
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+// uploadedFile is an in memory multipart.File
+type uploadedFile struct {
+	*bytes.Reader
+}
+
+func (f uploadedFile) Close() error { return nil }
+
+func pngFile(t *testing.T, width, height int) uploadedFile {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return uploadedFile{bytes.NewReader(buf.Bytes())}
+}
+
+func TestLocalStorageUploadImage(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      uploadedFile
+		wantErr   error
+		wantFiles int
+	}{
+		{name: "image with renditions", file: pngFile(t, 800, 400), wantFiles: 3},
+		{name: "widest accepted image", file: pngFile(t, MaxImageDimension, 1), wantFiles: 3},
+		{name: "too wide", file: pngFile(t, MaxImageDimension+1, 1), wantErr: utils.ErrImageDimensionsTooLarge},
+		{name: "too tall", file: pngFile(t, 1, MaxImageDimension+1), wantErr: utils.ErrImageDimensionsTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s, err := NewLocalStorage(dir, "uploads")
+			if err != nil {
+				t.Fatalf("NewLocalStorage() error = %v", err)
+			}
+
+			uploaded, err := s.UploadImage(context.Background(), tt.file, "football/jersey")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UploadImage() error = %v, want %v", err, tt.wantErr)
+			}
+
+			files, _ := os.ReadDir(dir)
+			if len(files) != tt.wantFiles {
+				t.Errorf("UploadImage() saved %d files, want %d", len(files), tt.wantFiles)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			for _, url := range []string{uploaded.URL, uploaded.ThumbnailURL, uploaded.MediumURL} {
+				if !strings.HasPrefix(url, "/uploads/") {
+					t.Errorf("UploadImage() url = %q, want it under /uploads/", url)
+				}
+			}
+			thumbnail, err := os.Open(filepath.Join(dir, strings.TrimPrefix(uploaded.ThumbnailURL, "/uploads/")))
+			if err != nil {
+				t.Fatalf("thumbnail not saved: %v", err)
+			}
+			defer thumbnail.Close()
+			config, _, err := image.DecodeConfig(thumbnail)
+			if err != nil || max(config.Width, config.Height) > ThumbnailSize {
+				t.Errorf("thumbnail size = %dx%d, %v, want at most %d", config.Width, config.Height, err, ThumbnailSize)
+			}
+		})
+	}
+}
+
+func TestLocalStorageUploadImageRemovesSavedFiles(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStorage(dir, "uploads")
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+
+	// a directory in place of the medium rendition makes its write fail after the other files are saved
+	err = os.Mkdir(filepath.Join(dir, "blocked_medium.png"), 0755)
+	if err != nil {
+		t.Fatalf("os.Mkdir() error = %v", err)
+	}
+	uploaded := &UploadedImage{}
+	uploaded.URL, err = s.writeFile("blocked.png", []byte("original"))
+	if err != nil {
+		t.Fatalf("writeFile() error = %v", err)
+	}
+	uploaded.ThumbnailURL, err = s.writeRendition("blocked_thumb", image.NewGray(image.Rect(0, 0, 10, 10)), "png", ThumbnailSize)
+	if err != nil {
+		t.Fatalf("writeRendition() error = %v", err)
+	}
+	_, renditionErr := s.writeRendition("blocked_medium", image.NewGray(image.Rect(0, 0, 10, 10)), "png", MediumSize)
+	if renditionErr == nil {
+		t.Fatal("writeRendition() error = nil, want the write to fail")
+	}
+
+	err = s.removeUploaded(uploaded, renditionErr)
+	if !errors.Is(err, renditionErr) {
+		t.Errorf("removeUploaded() error = %v, want %v", err, renditionErr)
+	}
+	for _, name := range []string{"blocked.png", "blocked_thumb.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s is left after the failed upload", name)
+		}
+	}
+}
+
+func TestLocalStorageDeleteImage(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStorage(dir, "uploads")
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+	for _, name := range []string{"jersey_1.jpg", "jersey_1_thumb.jpg", "jersey_1_medium.jpg", "jersey_2.jpg"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("image"), 0644); err != nil {
+			t.Fatalf("os.WriteFile() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "image with renditions", url: "/uploads/jersey_1.jpg"},
+		{name: "already deleted image", url: "/uploads/jersey_1.jpg"},
+		{name: "image of another storage", url: "https://cdn.example.com/jersey_2.jpg"},
+		{name: "path outside the storage", url: "/uploads/../jersey_3.jpg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.DeleteImage(context.Background(), tt.url); err != nil {
+				t.Errorf("DeleteImage(%q) error = %v", tt.url, err)
+			}
+		})
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "jersey_2.jpg" {
+		t.Errorf("files left = %v, want only jersey_2.jpg", files)
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestResizeImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		width      int
+		height     int
+		maxSize    int
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "smaller image is kept", width: 100, height: 50, maxSize: 200, wantWidth: 100, wantHeight: 50},
+		{name: "image of the exact size is kept", width: 200, height: 200, maxSize: 200, wantWidth: 200, wantHeight: 200},
+		{name: "landscape", width: 800, height: 400, maxSize: 200, wantWidth: 200, wantHeight: 100},
+		{name: "portrait", width: 300, height: 900, maxSize: 600, wantWidth: 200, wantHeight: 600},
+		{name: "square", width: 1000, height: 1000, maxSize: 200, wantWidth: 200, wantHeight: 200},
+		{name: "thin image keeps a pixel", width: 1000, height: 2, maxSize: 200, wantWidth: 200, wantHeight: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+			bounds := resizeImage(src, tt.maxSize).Bounds()
+			if bounds.Dx() != tt.wantWidth || bounds.Dy() != tt.wantHeight {
+				t.Errorf("resizeImage() size = %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestResizeImageAveragesPixels(t *testing.T) {
+	// left half black, right half white, with a bounds origin which is not zero
+	src := image.NewRGBA(image.Rect(10, 10, 14, 12))
+	for y := 10; y < 12; y++ {
+		for x := 10; x < 14; x++ {
+			c := color.RGBA{A: 255}
+			if x >= 12 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+
+	dst := resizeImage(src, 2)
+	tests := []struct {
+		x, y int
+		want color.RGBA64
+	}{
+		{x: 0, y: 0, want: color.RGBA64{A: 0xffff}},
+		{x: 1, y: 0, want: color.RGBA64{R: 0xffff, G: 0xffff, B: 0xffff, A: 0xffff}},
+	}
+	for _, tt := range tests {
+		r, g, b, a := dst.At(tt.x, tt.y).RGBA()
+		got := color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: uint16(a)}
+		if got != tt.want {
+			t.Errorf("resizeImage() pixel (%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+
+	tests := []struct {
+		format     string
+		wantExt    string
+		wantFormat string
+	}{
+		{format: "jpeg", wantExt: ".jpg", wantFormat: "jpeg"},
+		{format: "png", wantExt: ".png", wantFormat: "png"},
+		{format: "gif", wantExt: ".png", wantFormat: "png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			data, ext, err := encodeImage(img, tt.format)
+			if err != nil {
+				t.Fatalf("encodeImage() error = %v", err)
+			}
+			if ext != tt.wantExt {
+				t.Errorf("encodeImage() extension = %q, want %q", ext, tt.wantExt)
+			}
+			_, format, err := image.DecodeConfig(bytes.NewReader(data))
+			if err != nil || format != tt.wantFormat {
+				t.Errorf("encodeImage() encoded format = %q, %v, want %q", format, err, tt.wantFormat)
+			}
+		})
+	}
+}
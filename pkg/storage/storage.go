@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"mime/multipart"
+	"net/http"
+)
+
+// Longest side, in pixels, of the resized renditions generated on upload
+const (
+	ThumbnailSize = 200
+	MediumSize    = 600
+)
+
+// MaxImageDimension is the longest width or height, in pixels, of an image accepted for upload
+const MaxImageDimension = 6000
+
+// UploadedImage holds the URLs of an uploaded image and of its resized renditions
+type UploadedImage struct {
+	URL          string
+	ThumbnailURL string
+	MediumURL    string
+}
+
+// ImageStorage stores the product images
+type ImageStorage interface {
+	UploadImage(ctx context.Context, file multipart.File, productSlug string) (*UploadedImage, error)
+	// DeleteImage deletes the image with the given URL along with its renditions
+	DeleteImage(ctx context.Context, imageURL string) error
+}
+
+// StaticImageStorage is implemented by the storages whose images are served by the application itself
+type StaticImageStorage interface {
+	ImageStorage
+	URLPrefix() string
+	Handler() http.Handler
+}
@@ -0,0 +1,37 @@
+package tasks
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ProductAffinityRefresher rebuilds the co-purchase affinity of the products from the completed orders
+type ProductAffinityRefresher interface {
+	RefreshProductAffinities(ctx context.Context) (int64, error)
+}
+
+// StartProductAffinityRefreshTask starts a background task that rebuilds the product affinities
+// once at startup and then every 6 hours.
+func StartProductAffinityRefreshTask(refresher ProductAffinityRefresher) {
+	ticker := time.NewTicker(6 * time.Hour)
+
+	go func() {
+		refreshProductAffinities(refresher)
+		for range ticker.C {
+			refreshProductAffinities(refresher)
+		}
+	}()
+}
+
+func refreshProductAffinities(refresher ProductAffinityRefresher) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	pairs, err := refresher.RefreshProductAffinities(ctx)
+	if err != nil {
+		log.Printf("Error refreshing product affinities: %v", err)
+		return
+	}
+	log.Printf("Refreshed product affinities, %d product pairs", pairs)
+}
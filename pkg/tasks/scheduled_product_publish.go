@@ -0,0 +1,33 @@
+package tasks
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ScheduledProductPublisher makes the scheduled products live once their publish time has come
+type ScheduledProductPublisher interface {
+	PublishScheduledProducts(ctx context.Context) (int64, error)
+}
+
+// StartScheduledProductPublishTask starts a background task that checks every minute
+// for scheduled products whose publish time has come and makes them active.
+func StartScheduledProductPublishTask(publisher ScheduledProductPublisher) {
+	ticker := time.NewTicker(1 * time.Minute)
+
+	go func() {
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+
+			published, err := publisher.PublishScheduledProducts(ctx)
+			if err != nil {
+				log.Printf("Error publishing scheduled products: %v", err)
+			} else if published > 0 {
+				log.Printf("Published %d scheduled products", published)
+			}
+
+			cancel()
+		}
+	}()
+}
@@ -0,0 +1,39 @@
+package tasks
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StockAlertDetector raises the alerts of the products which crossed their reorder threshold
+type StockAlertDetector interface {
+	DetectStockAlerts(ctx context.Context) (int64, error)
+}
+
+// StartStockAlertTask starts a background task that checks the stock against the reorder thresholds
+// once at startup and then every 30 minutes.
+func StartStockAlertTask(detector StockAlertDetector) {
+	ticker := time.NewTicker(30 * time.Minute)
+
+	go func() {
+		detectStockAlerts(detector)
+		for range ticker.C {
+			detectStockAlerts(detector)
+		}
+	}()
+}
+
+func detectStockAlerts(detector StockAlertDetector) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	created, err := detector.DetectStockAlerts(ctx)
+	if err != nil {
+		log.Printf("Error detecting low stock: %v", err)
+		return
+	}
+	if created > 0 {
+		log.Printf("Raised %d low stock alerts", created)
+	}
+}
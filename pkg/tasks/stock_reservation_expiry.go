@@ -0,0 +1,33 @@
+package tasks
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StockReservationExpirer releases the stock reservations which are past their expiry
+type StockReservationExpirer interface {
+	ExpireReservations(ctx context.Context) (int64, error)
+}
+
+// StartStockReservationExpiryTask starts a background task that marks the expired stock reservations every minute.
+// Expired reservations don't hold the stock even before the task runs, the task keeps their status accurate.
+func StartStockReservationExpiryTask(expirer StockReservationExpirer) {
+	ticker := time.NewTicker(1 * time.Minute)
+
+	go func() {
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+
+			expired, err := expirer.ExpireReservations(ctx)
+			if err != nil {
+				log.Printf("Error expiring stock reservations: %v", err)
+			} else if expired > 0 {
+				log.Printf("Released %d expired stock reservations", expired)
+			}
+
+			cancel()
+		}
+	}()
+}
@@ -12,14 +12,15 @@ import (
 
 // usecase constants
 const (
-	MaxResendAttempts   = 3
-	ResendCooldown      = 1 * time.Minute
-	SignupExpiration    = 1 * time.Hour
-	MaxImagesPerProduct = 5
-	MaxFileSize         = 10 * 1024 * 1024 // 10 MB
-	MaxCartItemQuantity = 10
-	MaxDiscountAmount   = 5000
-	CODLimit            = 1000.0 // cash on delivery order limit
+	MaxResendAttempts     = 3
+	ResendCooldown        = 1 * time.Minute
+	SignupExpiration      = 1 * time.Hour
+	MaxImagesPerProduct   = 5
+	MaxImageAltTextLength = 255
+	MaxFileSize           = 10 * 1024 * 1024 // 10 MB
+	MaxCartItemQuantity   = 10
+	MaxDiscountAmount     = 5000
+	CODLimit              = 1000.0 // cash on delivery order limit
 
 	// order status constants
 	OrderStatusPending             = "pending_payment"
@@ -46,6 +47,59 @@ const (
 	CheckoutStatusAbondoned = "abandoned"
 	CheckoutStatusExpired   = "expired"
 
+	// Stock reservation status in stock_reservations table
+	ReservationStatusActive    = "active"
+	ReservationStatusCommitted = "committed"
+	ReservationStatusReleased  = "released"
+	ReservationStatusExpired   = "expired"
+
+	// Stock is held for the checkout once the address is given, and for the razorpay order till it is paid
+	CheckoutReservationTTL = 15 * time.Minute
+	PaymentReservationTTL  = 30 * time.Minute
+
+	// Reason of a stock movement in stock_movements table
+	StockMovementReasonOpeningBalance = "opening_balance"
+	StockMovementReasonInitialStock   = "initial_stock"
+	StockMovementReasonAdjustment     = "adjustment"
+	StockMovementReasonImport         = "import"
+	StockMovementReasonOrder          = "order"
+	StockMovementReasonCancellation   = "cancellation"
+	StockMovementReasonReturn         = "return"
+	StockMovementReasonVariantDeleted = "variant_deleted"
+	StockMovementReasonTransfer       = "transfer"
+
+	// Reference and actor of a stock movement
+	StockReferenceOrder    = "order"
+	StockReferenceReturn   = "return"
+	StockReferenceAdmin    = "admin"
+	StockReferenceTransfer = "transfer"
+	StockActorUser         = "user"
+	StockActorAdmin        = "admin"
+	StockActorSystem       = "system"
+
+	// Rule used to pick the locations the stock of an order is taken from,
+	// priority takes the stock in the priority order of the locations,
+	// nearest takes the stock from the locations nearest to the shipping pincode first
+	AllocationRulePriority = "priority"
+	AllocationRuleNearest  = "nearest"
+
+	// Inventory listing with the stock of each item at every location
+	InventoryBreakdownLocation = "location"
+
+	// Status of an order allocation in order_allocations table
+	OrderAllocationStatusAllocated = "allocated"
+	OrderAllocationStatusReleased  = "released"
+
+	// Status of a low stock alert in stock_alerts table
+	StockAlertStatusOpen     = "open"
+	StockAlertStatusResolved = "resolved"
+
+	// Sales velocity is the average daily sales of the trailing window,
+	// the suggested reorder brings the stock to the threshold plus the sales expected in ReorderCoverDays
+	SalesVelocityWindowDays = 30
+	ReorderCoverDays        = 30
+	MaxReorderThreshold     = 1000000
+
 	// Payment method
 	PaymentMethodRazorpay = "razorpay"
 	PaymentMethodCOD      = "cod"
@@ -60,6 +114,9 @@ const (
 	PaymentStatusAwaitingPayment = "awaiting_payment"
 	PaymentStatusPaid            = "paid"
 
+	// Status of a failed payment in Razorpay
+	RazorpayPaymentStatusFailed = "failed"
+
 	// Cancellation status in cancellation_requests table
 	CancellationStatusPendingReview = "pending_review"
 	CancellationStatusCancelled     = "cancelled"
@@ -71,6 +128,31 @@ const (
 	// Refund
 	RefundStatusNotApplicable = "not_applicable"
 	RefundStatusInitiated     = "initiated"
+
+	// Review status in product_reviews table
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusHidden   = "hidden"
+
+	// Status of product questions and answers
+	QAStatusPublished = "published"
+	QAStatusHidden    = "hidden"
+
+	// Product attribute types
+	AttributeTypeText    = "text"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeSelect  = "select"
+
+	// Offer discount types
+	OfferTypePercentage = "percentage"
+	OfferTypeFlat       = "flat"
+
+	// Product status in products table, only active products are shown to the users
+	ProductStatusDraft     = "draft"
+	ProductStatusScheduled = "scheduled"
+	ProductStatusActive    = "active"
+	ProductStatusArchived  = "archived"
 )
 
 const (
@@ -166,6 +248,12 @@ var (
 	ErrCategoryNameNumeric    = errors.New("category name purely numeric")
 	ErrDBCreateCategory       = errors.New("failed to create category in db")
 	ErrCategoryAlreadyDeleted = errors.New("category already deleted")
+	ErrInvalidParentCategory  = errors.New("invalid parent category")
+
+	// trash bin
+	ErrParentDeleted       = errors.New("parent category is deleted, restore it first")
+	ErrSlugTaken           = errors.New("slug is used by another active entry")
+	ErrInvalidRetentionDay = errors.New("older_than_days must be a positive number")
 
 	//sub category
 	ErrInvalidSubCategoryName    = errors.New("invalid subcategory name")
@@ -177,6 +265,14 @@ var (
 	ErrCreateSubCategory         = errors.New("failed to create sub category")
 	ErrSubCategoryAlreadyDeleted = errors.New("sub category already deleted")
 
+	//brand
+	ErrInvalidBrandName  = errors.New("invalid brand name")
+	ErrBrandNameTooShort = errors.New("brand name too short")
+	ErrBrandNameTooLong  = errors.New("brand name too long")
+	ErrDuplicateBrand    = errors.New("brand already exists")
+	ErrBrandNotFound     = errors.New("brand not found")
+	ErrInvalidBrand      = errors.New("invalid brand ID")
+
 	//db errors
 	ErrQueryExecution    = errors.New("failed to execute query")
 	ErrRowScan           = errors.New("failed to scan row")
@@ -200,6 +296,37 @@ var (
 	ErrDuplicateProductName       = errors.New("product name already exists")
 	ErrDuplicateProductSlug       = errors.New("product slug already exists")
 	ErrInvalidQueryParameter      = errors.New("invalid query parameter")
+	ErrInvalidProductStatus       = errors.New("invalid product status")
+	ErrInvalidPublishAt           = errors.New("scheduled product needs a future publish time")
+
+	// product variant
+	ErrVariantNotFound          = errors.New("product variant not found")
+	ErrInvalidVariantSKU        = errors.New("invalid variant sku")
+	ErrInvalidVariantAttributes = errors.New("variant needs a valid size or color")
+	ErrInvalidVariantPrice      = errors.New("invalid variant price")
+	ErrDuplicateVariantSKU      = errors.New("variant sku already exists")
+	ErrDuplicateVariant         = errors.New("variant with this size and color already exists")
+	ErrVariantRequired          = errors.New("variant is required for this product")
+	ErrProductHasVariants       = errors.New("product stock is managed through its variants")
+
+	// stock alerts
+	ErrInvalidReorderThreshold = errors.New("invalid reorder threshold")
+	ErrInvalidStockAlertStatus = errors.New("invalid stock alert status")
+
+	// inventory locations
+	ErrLocationNotFound          = errors.New("inventory location not found")
+	ErrInvalidLocationName       = errors.New("invalid location name")
+	ErrInvalidLocationCode       = errors.New("invalid location code")
+	ErrInvalidLocationPincode    = errors.New("invalid location pincode")
+	ErrInvalidLocationPriority   = errors.New("invalid location priority")
+	ErrDuplicateLocationName     = errors.New("location name already exists")
+	ErrDuplicateLocationCode     = errors.New("location code already exists")
+	ErrLocationInactive          = errors.New("inventory location is inactive")
+	ErrLocationHasStock          = errors.New("inventory location still holds stock")
+	ErrNoActiveLocation          = errors.New("no active inventory location")
+	ErrInsufficientLocationStock = errors.New("insufficient stock at the location")
+	ErrInvalidTransferQuantity   = errors.New("invalid transfer quantity")
+	ErrSameTransferLocation      = errors.New("stock can't be transferred to the same location")
 
 	//usecase errors
 	ErrAdminNotFound           = errors.New("admin not found")
@@ -231,12 +358,15 @@ var (
 	ErrHashingPassword         = errors.New("error hashing password")
 
 	//image
-	ErrImageNotFound   = errors.New("image not found")
-	ErrLastImage       = errors.New("last image")
-	ErrFileTooLarge    = errors.New("file size exceeds the maximum limit of 10MB")
-	ErrInvalidFileType = errors.New("invalid file type. Only .jpg, .jpeg, .png, and .gif are allowed")
-	ErrTooManyImages   = errors.New("maximum number of images (5) reached for this product")
-	ErrEmptyFile       = errors.New("file is empty")
+	ErrImageNotFound           = errors.New("image not found")
+	ErrLastImage               = errors.New("last image")
+	ErrFileTooLarge            = errors.New("file size exceeds the maximum limit of 10MB")
+	ErrImageDimensionsTooLarge = errors.New("image width and height should be at most 6000 pixels")
+	ErrInvalidFileType         = errors.New("invalid file type. Only .jpg, .jpeg, .png, and .gif are allowed")
+	ErrTooManyImages           = errors.New("maximum number of images (5) reached for this product")
+	ErrEmptyFile               = errors.New("file is empty")
+	ErrInvalidImageOrder       = errors.New("image order must list every image of the product exactly once")
+	ErrInvalidAltText          = errors.New("alt text must be at most 255 characters")
 
 	// user password change
 	ErrOTPNotRequested      = errors.New("no OTP was requested for this email")
@@ -309,6 +439,7 @@ var (
 	ErrPaymentNotFound            = errors.New("payment not found")
 	ErrRazorpayServiceUnavailable = errors.New("Razorpay service unavailable")
 	ErrPaymentNotRefundable       = errors.New("payment not refundable")
+	ErrPaymentNotFailed           = errors.New("payment has not failed")
 
 	// inventory
 	ErrStockQuantityTooLarge = errors.New("stock quantity too large")
@@ -359,6 +490,60 @@ var (
 	ErrMissingPaymentStatus = errors.New("missing payment status")
 	ErrInvalidPaymentStatus = errors.New("invalid payment status")
 
+	// review
+	ErrReviewNotFound        = errors.New("review not found")
+	ErrInvalidRating         = errors.New("rating should be between 1 and 5")
+	ErrReviewTooLong         = errors.New("review is too long")
+	ErrReviewNotAllowed      = errors.New("product can be reviewed only after it is delivered")
+	ErrDuplicateReview       = errors.New("product already reviewed by the user")
+	ErrInvalidReviewStatus   = errors.New("invalid review status")
+	ErrReviewAlreadyInStatus = errors.New("review already has this status")
+
+	// product questions and answers
+	ErrQuestionNotFound      = errors.New("question not found")
+	ErrAnswerNotFound        = errors.New("answer not found")
+	ErrInvalidQuestion       = errors.New("invalid question")
+	ErrInvalidAnswer         = errors.New("invalid answer")
+	ErrAnswerNotAllowed      = errors.New("question can be answered only by buyers of the product")
+	ErrInvalidQAStatus       = errors.New("invalid question or answer status")
+	ErrQAAlreadyInStatus     = errors.New("question or answer already has this status")
+	ErrAlreadyUpvoted        = errors.New("answer already upvoted by the user")
+	ErrUpvoteNotFound        = errors.New("answer not upvoted by the user")
+	ErrCannotUpvoteOwnAnswer = errors.New("user can't upvote own answer")
+
+	// offer
+	ErrOfferNotFound        = errors.New("offer not found")
+	ErrInvalidOfferName     = errors.New("invalid offer name")
+	ErrInvalidOfferType     = errors.New("invalid offer discount type")
+	ErrInvalidOfferValue    = errors.New("invalid offer discount value")
+	ErrInvalidOfferTarget   = errors.New("offer should target exactly one product, sub category or category")
+	ErrInvalidOfferPeriod   = errors.New("invalid offer period")
+	ErrOfferAlreadyInactive = errors.New("offer is already inactive")
+	ErrPriceChanged         = errors.New("price of some items changed after checkout")
+
+	// pagination
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	ErrCursorWithSort = errors.New("sorting is not supported with cursor pagination")
+
+	// product import
+	ErrUnsupportedFileFormat = errors.New("unsupported file format")
+	ErrInvalidImportFile     = errors.New("invalid import file")
+	ErrInvalidImportHeader   = errors.New("invalid import file header")
+	ErrTooManyImportRows     = errors.New("too many rows in import file")
+	ErrEmptyImportFile       = errors.New("import file has no rows")
+	ErrImportProductDeleted  = errors.New("product with this name was deleted, it can't be imported")
+
+	// product attributes
+	ErrAttributeNotFound      = errors.New("product attribute not found")
+	ErrInvalidAttributeName   = errors.New("invalid product attribute name")
+	ErrInvalidAttributeType   = errors.New("invalid product attribute type")
+	ErrInvalidAllowedValues   = errors.New("select attribute needs allowed values")
+	ErrDuplicateAttribute     = errors.New("product attribute already exists for the sub category")
+	ErrInvalidAttributeValue  = errors.New("invalid value for the product attribute")
+	ErrAttributeNotApplicable = errors.New("attribute doesn't apply to the product's sub category")
+	ErrAttributeSlugInTree    = errors.New("product attribute already exists for a sub category above or below it")
+	ErrAllowedValueInUse      = errors.New("allowed value is used by products")
+
 	// ErrNoDataFound      = errors.New("no data found")
 	// ErrInvalidFormat    = errors.New("invalid format")
 	// ErrInvalidDateRange = errors.New("invalid date range")
@@ -412,3 +597,25 @@ func IsDuplicateKeyError(err error) bool {
 	// Generic check (less reliable, but can work as a fallback)
 	return strings.Contains(err.Error(), "duplicate key value violates unique constraint")
 }
+
+// StockShortage is an item which doesn't have enough stock for the requested quantity
+type StockShortage struct {
+	ProductID   int64  `json:"product_id"`
+	ProductName string `json:"product_name"`
+	VariantID   *int64 `json:"variant_id,omitempty"`
+	Requested   int    `json:"requested_quantity"`
+	Available   int    `json:"available_quantity"`
+}
+
+// InsufficientStockError lists every item which is short of stock, it matches ErrInsufficientStock with errors.Is
+type InsufficientStockError struct {
+	Items []StockShortage
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for %d item(s)", len(e.Items))
+}
+
+func (e *InsufficientStockError) Is(target error) bool {
+	return target == ErrInsufficientStock
+}
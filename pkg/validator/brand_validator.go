@@ -0,0 +1,19 @@
+package validator
+
+import "github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+
+func ValidateBrandName(name string) error {
+	if name == "" {
+		return utils.ErrInvalidBrandName
+	}
+
+	if len(name) < 2 {
+		return utils.ErrBrandNameTooShort
+	}
+
+	if len(name) > 50 {
+		return utils.ErrBrandNameTooLong
+	}
+
+	return nil
+}
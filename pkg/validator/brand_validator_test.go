@@ -0,0 +1,33 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestValidateBrandName(t *testing.T) {
+	tests := []struct {
+		name    string
+		brand   string
+		wantErr error
+	}{
+		{name: "valid name", brand: "nike"},
+		{name: "shortest name", brand: "hp"},
+		{name: "longest name", brand: strings.Repeat("a", 50)},
+		{name: "empty name", brand: "", wantErr: utils.ErrInvalidBrandName},
+		{name: "name too short", brand: "n", wantErr: utils.ErrBrandNameTooShort},
+		{name: "name too long", brand: strings.Repeat("a", 51), wantErr: utils.ErrBrandNameTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBrandName(tt.brand)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateBrandName(%q) error = %v, want %v", tt.brand, err, tt.wantErr)
+			}
+		})
+	}
+}
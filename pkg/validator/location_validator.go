@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"regexp"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+const (
+	MaxLocationNameLength = 100
+	MaxLocationPriority   = 1000
+)
+
+var locationCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{2,20}$`)
+
+func ValidateInventoryLocation(location *domain.InventoryLocation) error {
+	if err := ValidateLocationName(location.Name); err != nil {
+		return err
+	}
+	if !locationCodePattern.MatchString(location.Code) {
+		return utils.ErrInvalidLocationCode
+	}
+	if err := ValidateLocationPincode(location.Pincode); err != nil {
+		return err
+	}
+	return ValidateLocationPriority(location.Priority)
+}
+
+func ValidateLocationName(name string) error {
+	if len(name) < 2 || len(name) > MaxLocationNameLength {
+		return utils.ErrInvalidLocationName
+	}
+	return nil
+}
+
+// ValidateLocationPincode checks the pincode the same way as the pincode of the shipping address
+func ValidateLocationPincode(pincode string) error {
+	if ValidatePinCode(pincode) != nil {
+		return utils.ErrInvalidLocationPincode
+	}
+	return nil
+}
+
+func ValidateLocationPriority(priority int) error {
+	if priority < 0 || priority > MaxLocationPriority {
+		return utils.ErrInvalidLocationPriority
+	}
+	return nil
+}
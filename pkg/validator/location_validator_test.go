@@ -0,0 +1,43 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestValidateInventoryLocation(t *testing.T) {
+	valid := domain.InventoryLocation{Name: "Kochi warehouse", Code: "KOCHI_01", Pincode: "682001", Priority: 10}
+
+	tests := []struct {
+		name    string
+		modify  func(l *domain.InventoryLocation)
+		wantErr error
+	}{
+		{name: "valid location", modify: func(l *domain.InventoryLocation) {}},
+		{name: "name too short", modify: func(l *domain.InventoryLocation) { l.Name = "K" }, wantErr: utils.ErrInvalidLocationName},
+		{name: "name too long", modify: func(l *domain.InventoryLocation) { l.Name = strings.Repeat("k", MaxLocationNameLength+1) }, wantErr: utils.ErrInvalidLocationName},
+		{name: "lower case code", modify: func(l *domain.InventoryLocation) { l.Code = "kochi" }, wantErr: utils.ErrInvalidLocationCode},
+		{name: "code too short", modify: func(l *domain.InventoryLocation) { l.Code = "K" }, wantErr: utils.ErrInvalidLocationCode},
+		{name: "code with spaces", modify: func(l *domain.InventoryLocation) { l.Code = "KOCHI 01" }, wantErr: utils.ErrInvalidLocationCode},
+		{name: "short pincode", modify: func(l *domain.InventoryLocation) { l.Pincode = "68200" }, wantErr: utils.ErrInvalidLocationPincode},
+		{name: "pincode with letters", modify: func(l *domain.InventoryLocation) { l.Pincode = "68200A" }, wantErr: utils.ErrInvalidLocationPincode},
+		{name: "negative priority", modify: func(l *domain.InventoryLocation) { l.Priority = -1 }, wantErr: utils.ErrInvalidLocationPriority},
+		{name: "priority too high", modify: func(l *domain.InventoryLocation) { l.Priority = MaxLocationPriority + 1 }, wantErr: utils.ErrInvalidLocationPriority},
+		{name: "highest priority", modify: func(l *domain.InventoryLocation) { l.Priority = MaxLocationPriority }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := valid
+			tt.modify(&location)
+			err := ValidateInventoryLocation(&location)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateInventoryLocation() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
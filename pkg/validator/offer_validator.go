@@ -0,0 +1,48 @@
+package validator
+
+import (
+	"strings"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+const MaxOfferNameLength = 100
+
+func ValidateOfferInput(input domain.CreateOfferInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > MaxOfferNameLength {
+		return utils.ErrInvalidOfferName
+	}
+
+	switch input.DiscountType {
+	case utils.OfferTypePercentage:
+		// percentage offers can't make a product free
+		if input.DiscountValue <= 0 || input.DiscountValue >= 100 {
+			return utils.ErrInvalidOfferValue
+		}
+	case utils.OfferTypeFlat:
+		if input.DiscountValue <= 0 {
+			return utils.ErrInvalidOfferValue
+		}
+	default:
+		return utils.ErrInvalidOfferType
+	}
+
+	// Exactly one target should be given
+	targets := 0
+	if input.ProductID != nil {
+		targets++
+	}
+	if input.SubCategoryID != nil {
+		targets++
+	}
+	if input.CategoryID != nil {
+		targets++
+	}
+	if targets != 1 {
+		return utils.ErrInvalidOfferTarget
+	}
+
+	return nil
+}
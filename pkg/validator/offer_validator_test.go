@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestValidateOfferInput(t *testing.T) {
+	productID := int64(1)
+	categoryID := 2
+
+	valid := domain.CreateOfferInput{
+		Name:          "Summer sale",
+		DiscountType:  utils.OfferTypePercentage,
+		DiscountValue: 20,
+		ProductID:     &productID,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(o *domain.CreateOfferInput)
+		wantErr error
+	}{
+		{name: "valid percentage offer", modify: func(o *domain.CreateOfferInput) {}},
+		{name: "valid flat offer", modify: func(o *domain.CreateOfferInput) { o.DiscountType, o.DiscountValue = utils.OfferTypeFlat, 500 }},
+		{name: "blank name", modify: func(o *domain.CreateOfferInput) { o.Name = "   " }, wantErr: utils.ErrInvalidOfferName},
+		{name: "name too long", modify: func(o *domain.CreateOfferInput) { o.Name = strings.Repeat("a", MaxOfferNameLength+1) }, wantErr: utils.ErrInvalidOfferName},
+		{name: "unknown type", modify: func(o *domain.CreateOfferInput) { o.DiscountType = "bogo" }, wantErr: utils.ErrInvalidOfferType},
+		{name: "zero percentage", modify: func(o *domain.CreateOfferInput) { o.DiscountValue = 0 }, wantErr: utils.ErrInvalidOfferValue},
+		{name: "full percentage", modify: func(o *domain.CreateOfferInput) { o.DiscountValue = 100 }, wantErr: utils.ErrInvalidOfferValue},
+		{name: "negative flat", modify: func(o *domain.CreateOfferInput) { o.DiscountType, o.DiscountValue = utils.OfferTypeFlat, -1 }, wantErr: utils.ErrInvalidOfferValue},
+		{name: "no target", modify: func(o *domain.CreateOfferInput) { o.ProductID = nil }, wantErr: utils.ErrInvalidOfferTarget},
+		{name: "two targets", modify: func(o *domain.CreateOfferInput) { o.CategoryID = &categoryID }, wantErr: utils.ErrInvalidOfferTarget},
+		{name: "category target", modify: func(o *domain.CreateOfferInput) { o.ProductID, o.CategoryID = nil, &categoryID }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := valid
+			tt.modify(&input)
+			err := ValidateOfferInput(input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateOfferInput() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
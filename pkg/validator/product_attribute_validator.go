@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+const (
+	MaxAttributeNameLength  = 100
+	MaxAttributeValueLength = 255
+	MaxAllowedValues        = 50
+)
+
+func ValidateProductAttribute(attribute *domain.ProductAttribute) error {
+	name := strings.TrimSpace(attribute.Name)
+	if len(name) < 2 || len(name) > MaxAttributeNameLength {
+		return utils.ErrInvalidAttributeName
+	}
+
+	switch attribute.Type {
+	case utils.AttributeTypeText, utils.AttributeTypeNumber, utils.AttributeTypeBoolean:
+		// allowed values are only used by select attributes
+		if len(attribute.AllowedValues) > 0 {
+			return utils.ErrInvalidAllowedValues
+		}
+	case utils.AttributeTypeSelect:
+		if len(attribute.AllowedValues) == 0 || len(attribute.AllowedValues) > MaxAllowedValues {
+			return utils.ErrInvalidAllowedValues
+		}
+		seen := make(map[string]bool, len(attribute.AllowedValues))
+		for _, value := range attribute.AllowedValues {
+			key := strings.ToLower(strings.TrimSpace(value))
+			if key == "" || len(value) > MaxAttributeValueLength || seen[key] {
+				return utils.ErrInvalidAllowedValues
+			}
+			seen[key] = true
+		}
+	default:
+		return utils.ErrInvalidAttributeType
+	}
+
+	return nil
+}
+
+// ValidateAttributeValue checks the value against the type of the attribute,
+// it returns the value in the form it is stored
+func ValidateAttributeValue(attribute *domain.ProductAttribute, value interface{}) (string, error) {
+	switch attribute.Type {
+	case utils.AttributeTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return "", utils.ErrInvalidAttributeValue
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case utils.AttributeTypeBoolean:
+		b, ok := value.(bool)
+		if !ok {
+			return "", utils.ErrInvalidAttributeValue
+		}
+		return strconv.FormatBool(b), nil
+	case utils.AttributeTypeSelect:
+		text, ok := value.(string)
+		if !ok {
+			return "", utils.ErrInvalidAttributeValue
+		}
+		// the value is stored as it is written in the allowed values
+		for _, allowed := range attribute.AllowedValues {
+			if strings.EqualFold(strings.TrimSpace(text), allowed) {
+				return allowed, nil
+			}
+		}
+		return "", utils.ErrInvalidAttributeValue
+	default:
+		text, ok := value.(string)
+		text = strings.TrimSpace(text)
+		if !ok || text == "" || len(text) > MaxAttributeValueLength {
+			return "", utils.ErrInvalidAttributeValue
+		}
+		return text, nil
+	}
+}
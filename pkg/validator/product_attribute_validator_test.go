@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestValidateProductAttribute(t *testing.T) {
+	manyValues := make([]string, MaxAllowedValues+1)
+	for i := range manyValues {
+		manyValues[i] = strings.Repeat("v", i+1)
+	}
+
+	tests := []struct {
+		name      string
+		attribute domain.ProductAttribute
+		wantErr   error
+	}{
+		{name: "text attribute", attribute: domain.ProductAttribute{Name: "Material", Type: utils.AttributeTypeText}},
+		{name: "number attribute", attribute: domain.ProductAttribute{Name: "Weight", Type: utils.AttributeTypeNumber}},
+		{name: "boolean attribute", attribute: domain.ProductAttribute{Name: "Waterproof", Type: utils.AttributeTypeBoolean}},
+		{name: "select attribute", attribute: domain.ProductAttribute{Name: "Fit", Type: utils.AttributeTypeSelect, AllowedValues: []string{"Slim", "Regular"}}},
+		{name: "name too short", attribute: domain.ProductAttribute{Name: " F ", Type: utils.AttributeTypeText}, wantErr: utils.ErrInvalidAttributeName},
+		{name: "name too long", attribute: domain.ProductAttribute{Name: strings.Repeat("a", MaxAttributeNameLength+1), Type: utils.AttributeTypeText}, wantErr: utils.ErrInvalidAttributeName},
+		{name: "unknown type", attribute: domain.ProductAttribute{Name: "Fit", Type: "date"}, wantErr: utils.ErrInvalidAttributeType},
+		{name: "allowed values on a text attribute", attribute: domain.ProductAttribute{Name: "Material", Type: utils.AttributeTypeText, AllowedValues: []string{"cotton"}}, wantErr: utils.ErrInvalidAllowedValues},
+		{name: "select without allowed values", attribute: domain.ProductAttribute{Name: "Fit", Type: utils.AttributeTypeSelect}, wantErr: utils.ErrInvalidAllowedValues},
+		{name: "too many allowed values", attribute: domain.ProductAttribute{Name: "Fit", Type: utils.AttributeTypeSelect, AllowedValues: manyValues}, wantErr: utils.ErrInvalidAllowedValues},
+		{name: "blank allowed value", attribute: domain.ProductAttribute{Name: "Fit", Type: utils.AttributeTypeSelect, AllowedValues: []string{"Slim", " "}}, wantErr: utils.ErrInvalidAllowedValues},
+		{name: "duplicate allowed values", attribute: domain.ProductAttribute{Name: "Fit", Type: utils.AttributeTypeSelect, AllowedValues: []string{"Slim", " slim"}}, wantErr: utils.ErrInvalidAllowedValues},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProductAttribute(&tt.attribute)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateProductAttribute() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAttributeValue(t *testing.T) {
+	text := &domain.ProductAttribute{Type: utils.AttributeTypeText}
+	number := &domain.ProductAttribute{Type: utils.AttributeTypeNumber}
+	boolean := &domain.ProductAttribute{Type: utils.AttributeTypeBoolean}
+	selection := &domain.ProductAttribute{Type: utils.AttributeTypeSelect, AllowedValues: []string{"Slim", "Regular"}}
+
+	tests := []struct {
+		name      string
+		attribute *domain.ProductAttribute
+		value     interface{}
+		want      string
+		wantErr   error
+	}{
+		{name: "text is trimmed", attribute: text, value: "  cotton ", want: "cotton"},
+		{name: "blank text", attribute: text, value: "  ", wantErr: utils.ErrInvalidAttributeValue},
+		{name: "text too long", attribute: text, value: strings.Repeat("a", MaxAttributeValueLength+1), wantErr: utils.ErrInvalidAttributeValue},
+		{name: "number as text", attribute: text, value: 12.0, wantErr: utils.ErrInvalidAttributeValue},
+		{name: "whole number", attribute: number, value: 250.0, want: "250"},
+		{name: "decimal number", attribute: number, value: 1.25, want: "1.25"},
+		{name: "number given as text", attribute: number, value: "250", wantErr: utils.ErrInvalidAttributeValue},
+		{name: "boolean", attribute: boolean, value: true, want: "true"},
+		{name: "boolean given as text", attribute: boolean, value: "yes", wantErr: utils.ErrInvalidAttributeValue},
+		{name: "select keeps the allowed spelling", attribute: selection, value: " slim", want: "Slim"},
+		{name: "select value not allowed", attribute: selection, value: "Loose", wantErr: utils.ErrInvalidAttributeValue},
+		{name: "select given a number", attribute: selection, value: 1.0, wantErr: utils.ErrInvalidAttributeValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateAttributeValue(tt.attribute, tt.value)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("ValidateAttributeValue(%v) = %q, %v, want %q, %v", tt.value, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
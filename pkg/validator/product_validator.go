@@ -4,6 +4,7 @@ import (
 	"mime/multipart"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
 	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
@@ -95,6 +96,20 @@ func ValidateProductSubCategoryID(subCategoryID int) error {
 	return nil
 }
 
+// ValidateProductStatus validates the status of the product, a scheduled product needs a publish time in the future
+func ValidateProductStatus(status string, publishAt *time.Time) error {
+	switch status {
+	case utils.ProductStatusDraft, utils.ProductStatusActive, utils.ProductStatusArchived:
+		return nil
+	case utils.ProductStatusScheduled:
+		if publishAt == nil || !publishAt.After(time.Now()) {
+			return utils.ErrInvalidPublishAt
+		}
+		return nil
+	}
+	return utils.ErrInvalidProductStatus
+}
+
 func ValidateFile(header *multipart.FileHeader) error {
 	// Check file size
 	if header.Size > utils.MaxFileSize {
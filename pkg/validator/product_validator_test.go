@@ -0,0 +1,39 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestValidateProductStatus(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		status    string
+		publishAt *time.Time
+		wantErr   error
+	}{
+		{name: "draft", status: utils.ProductStatusDraft},
+		{name: "active", status: utils.ProductStatusActive},
+		{name: "archived", status: utils.ProductStatusArchived},
+		{name: "scheduled in the future", status: utils.ProductStatusScheduled, publishAt: &future},
+		{name: "scheduled without a publish time", status: utils.ProductStatusScheduled, wantErr: utils.ErrInvalidPublishAt},
+		{name: "scheduled in the past", status: utils.ProductStatusScheduled, publishAt: &past, wantErr: utils.ErrInvalidPublishAt},
+		{name: "unknown status", status: "deleted", wantErr: utils.ErrInvalidProductStatus},
+		{name: "empty status", status: "", wantErr: utils.ErrInvalidProductStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProductStatus(tt.status, tt.publishAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateProductStatus(%q) error = %v, want %v", tt.status, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"strings"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+const (
+	MaxVariantSKULength   = 100
+	MaxVariantSizeLength  = 20
+	MaxVariantColorLength = 50
+)
+
+func ValidateProductVariant(variant *domain.ProductVariant) error {
+	if err := ValidateVariantSKU(variant.SKU); err != nil {
+		return err
+	}
+
+	// A variant should be identified at least by a size or a color
+	if variant.Size == "" && variant.Color == "" {
+		return utils.ErrInvalidVariantAttributes
+	}
+	if len(variant.Size) > MaxVariantSizeLength || len(variant.Color) > MaxVariantColorLength {
+		return utils.ErrInvalidVariantAttributes
+	}
+
+	// price 0 means the variant is sold at the product price
+	if variant.Price < 0 {
+		return utils.ErrInvalidVariantPrice
+	}
+
+	if variant.StockQuantity < 0 {
+		return utils.ErrInvalidStockQuantity
+	}
+
+	return nil
+}
+
+func ValidateVariantSKU(sku string) error {
+	if strings.TrimSpace(sku) == "" || len(sku) > MaxVariantSKULength {
+		return utils.ErrInvalidVariantSKU
+	}
+	if strings.ContainsAny(sku, " \t\n") {
+		return utils.ErrInvalidVariantSKU
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/internal/domain"
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestValidateProductVariant(t *testing.T) {
+	valid := domain.ProductVariant{SKU: "JERSEY-HOME-M", Size: "M", Color: "red", Price: 999, StockQuantity: 10}
+
+	tests := []struct {
+		name    string
+		modify  func(v *domain.ProductVariant)
+		wantErr error
+	}{
+		{name: "valid variant", modify: func(v *domain.ProductVariant) {}},
+		{name: "size only", modify: func(v *domain.ProductVariant) { v.Color = "" }},
+		{name: "color only", modify: func(v *domain.ProductVariant) { v.Size = "" }},
+		{name: "sold at the product price", modify: func(v *domain.ProductVariant) { v.Price = 0 }},
+		{name: "blank sku", modify: func(v *domain.ProductVariant) { v.SKU = "  " }, wantErr: utils.ErrInvalidVariantSKU},
+		{name: "sku with spaces", modify: func(v *domain.ProductVariant) { v.SKU = "JERSEY M" }, wantErr: utils.ErrInvalidVariantSKU},
+		{name: "sku too long", modify: func(v *domain.ProductVariant) { v.SKU = strings.Repeat("A", MaxVariantSKULength+1) }, wantErr: utils.ErrInvalidVariantSKU},
+		{name: "no size or color", modify: func(v *domain.ProductVariant) { v.Size, v.Color = "", "" }, wantErr: utils.ErrInvalidVariantAttributes},
+		{name: "size too long", modify: func(v *domain.ProductVariant) { v.Size = strings.Repeat("X", MaxVariantSizeLength+1) }, wantErr: utils.ErrInvalidVariantAttributes},
+		{name: "color too long", modify: func(v *domain.ProductVariant) { v.Color = strings.Repeat("r", MaxVariantColorLength+1) }, wantErr: utils.ErrInvalidVariantAttributes},
+		{name: "negative price", modify: func(v *domain.ProductVariant) { v.Price = -1 }, wantErr: utils.ErrInvalidVariantPrice},
+		{name: "negative stock", modify: func(v *domain.ProductVariant) { v.StockQuantity = -1 }, wantErr: utils.ErrInvalidStockQuantity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variant := valid
+			tt.modify(&variant)
+			err := ValidateProductVariant(&variant)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateProductVariant() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
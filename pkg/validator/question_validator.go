@@ -0,0 +1,23 @@
+package validator
+
+import "github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+
+const (
+	MinQuestionLength = 5
+	MaxQuestionLength = 1000
+	MaxAnswerLength   = 2000
+)
+
+func ValidateQuestion(question string) error {
+	if len(question) < MinQuestionLength || len(question) > MaxQuestionLength {
+		return utils.ErrInvalidQuestion
+	}
+	return nil
+}
+
+func ValidateAnswer(answer string) error {
+	if answer == "" || len(answer) > MaxAnswerLength {
+		return utils.ErrInvalidAnswer
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestValidateQuestion(t *testing.T) {
+	tests := []struct {
+		name     string
+		question string
+		wantErr  error
+	}{
+		{name: "valid question", question: "Is this true to size?"},
+		{name: "shortest question", question: strings.Repeat("a", MinQuestionLength)},
+		{name: "longest question", question: strings.Repeat("a", MaxQuestionLength)},
+		{name: "empty question", question: "", wantErr: utils.ErrInvalidQuestion},
+		{name: "question too short", question: strings.Repeat("a", MinQuestionLength-1), wantErr: utils.ErrInvalidQuestion},
+		{name: "question too long", question: strings.Repeat("a", MaxQuestionLength+1), wantErr: utils.ErrInvalidQuestion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateQuestion(tt.question)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateQuestion() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAnswer(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		wantErr error
+	}{
+		{name: "valid answer", answer: "Yes"},
+		{name: "longest answer", answer: strings.Repeat("a", MaxAnswerLength)},
+		{name: "empty answer", answer: "", wantErr: utils.ErrInvalidAnswer},
+		{name: "answer too long", answer: strings.Repeat("a", MaxAnswerLength+1), wantErr: utils.ErrInvalidAnswer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAnswer(tt.answer)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateAnswer() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
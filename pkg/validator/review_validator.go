@@ -0,0 +1,22 @@
+package validator
+
+import "github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+
+const (
+	MinReviewRating = 1
+	MaxReviewRating = 5
+	MaxReviewLength = 2000
+)
+
+func ValidateReview(rating int, comment string) error {
+	if rating < MinReviewRating || rating > MaxReviewRating {
+		return utils.ErrInvalidRating
+	}
+
+	// comment is optional, a user may only give a rating
+	if len(comment) > MaxReviewLength {
+		return utils.ErrReviewTooLong
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mohamedfawas/rmshop-clean-architecture/pkg/utils"
+)
+
+func TestValidateReview(t *testing.T) {
+	tests := []struct {
+		name    string
+		rating  int
+		comment string
+		wantErr error
+	}{
+		{name: "rating with comment", rating: 4, comment: "Good fit"},
+		{name: "rating only", rating: MinReviewRating},
+		{name: "highest rating", rating: MaxReviewRating},
+		{name: "longest comment", rating: 3, comment: strings.Repeat("a", MaxReviewLength)},
+		{name: "rating too low", rating: MinReviewRating - 1, wantErr: utils.ErrInvalidRating},
+		{name: "rating too high", rating: MaxReviewRating + 1, wantErr: utils.ErrInvalidRating},
+		{name: "comment too long", rating: 3, comment: strings.Repeat("a", MaxReviewLength+1), wantErr: utils.ErrReviewTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReview(tt.rating, tt.comment)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateReview() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}